@@ -0,0 +1,144 @@
+package proteus
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/resolver"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+// allowTypes adds the custom mappings, including the ones of a single
+// package, and the allowed types and packages of the options to the custom
+// types of the resolver. It fails if an allowed type has no mapping.
+func allowTypes(options Options, r *resolver.Resolver) error {
+	for name := range options.Mappings {
+		r.AddCustomType(name)
+	}
+
+	for _, m := range options.PackageMappings {
+		for name := range m {
+			r.AddCustomType(name)
+		}
+	}
+
+	var missing []string
+	for _, name := range options.AllowedTypes {
+		if !strings.ContainsAny(name, "*?[") && !hasMapping(options, "", name) {
+			missing = append(missing, name)
+		}
+		r.AddCustomType(name)
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("allowed types have no mapping to a protobuf type: %s", strings.Join(missing, ", "))
+	}
+
+	for _, path := range options.AllowedPackages {
+		r.AddCustomPackage(path)
+	}
+
+	return nil
+}
+
+// checkAllowedTypes checks that the types of the allowed packages, or
+// matching allowed patterns or the mappings of a single package, used in the
+// resolved packages have a mapping, so they do not end up referencing a
+// proto file that is never generated.
+func checkAllowedTypes(options Options, pkgs []*scanner.Package) error {
+	if len(options.AllowedTypes) == 0 && len(options.AllowedPackages) == 0 && len(options.PackageMappings) == 0 {
+		return nil
+	}
+
+	scanned := make(map[string]bool, len(pkgs))
+	for _, p := range pkgs {
+		scanned[p.Path] = true
+	}
+
+	var problems []string
+	check := func(pkg, where string, typ scanner.Type) {
+		for _, n := range namedTypes(typ) {
+			if !scanned[n.Path] && isAllowed(options, n) && !hasMapping(options, pkg, n.InstanceName()) && !hasMapping(options, pkg, n.String()) {
+				problems = append(problems, fmt.Sprintf("%s has type %s, which is allowed but has no mapping to a protobuf type", where, n))
+			}
+		}
+	}
+
+	for _, p := range pkgs {
+		for _, s := range p.Structs {
+			for _, f := range s.Fields {
+				check(p.Path, fmt.Sprintf("field %s.%s.%s", p.Path, s.Name, f.Name), f.Type)
+			}
+		}
+
+		for _, f := range p.Funcs {
+			for _, typ := range append(f.Input, f.Output...) {
+				check(p.Path, fmt.Sprintf("func %s.%s", p.Path, f.Name), typ)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "\n"))
+	}
+
+	return nil
+}
+
+// isAllowed reports whether the type is one of the allowed types, belongs
+// to one of the allowed packages or has a mapping in a single package.
+func isAllowed(options Options, n *scanner.Named) bool {
+	for _, path := range options.AllowedPackages {
+		if n.Path == path {
+			return true
+		}
+	}
+
+	for _, name := range options.AllowedTypes {
+		if scanner.MatchName(name, n.String()) {
+			return true
+		}
+	}
+
+	for _, m := range options.PackageMappings {
+		if m.Find(n.InstanceName()) != nil || m.Find(n.String()) != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasMapping reports whether the Go type with the given name has a mapping
+// in the package with the given path.
+func hasMapping(options Options, pkg, name string) bool {
+	mappings := []protobuf.TypeMappings{
+		options.PackageMappings[pkg],
+		options.Mappings,
+		protobuf.DefaultMappings,
+	}
+	if !options.NoStdlibTypes {
+		mappings = append(mappings, protobuf.StdlibMappings)
+	}
+
+	for _, m := range mappings {
+		if m.Find(name) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// namedTypes returns the named types in the given type.
+func namedTypes(typ scanner.Type) []*scanner.Named {
+	switch t := typ.(type) {
+	case *scanner.Named:
+		return []*scanner.Named{t}
+	case *scanner.Map:
+		return append(namedTypes(t.Key), namedTypes(t.Value)...)
+	case *scanner.Alias:
+		return append(namedTypes(t.Type), namedTypes(t.Underlying)...)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package proteus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+const subpkg = fixturesPkg + "/subpkg"
+
+func TestAllowedPackages(t *testing.T) {
+	report.TestMode()
+	defer report.EndTestMode()
+
+	options := Options{Packages: []string{fixturesPkg}}
+	pkgs, err := scanPackages(options)
+	require.NoError(t, err)
+	require.Nil(t, findField(pkgs[0], "Saz", "Point"), "fields of not scanned packages are dropped")
+
+	options.AllowedPackages = []string{subpkg}
+	_, err = scanPackages(options)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "field "+fixturesPkg+".Saz.Point has type "+subpkg+".Point, which is allowed but has no mapping")
+
+	options.Mappings = protobuf.TypeMappings{
+		subpkg + ".*": &protobuf.ProtoType{Name: "bytes", Basic: true},
+	}
+	pkgs, err = scanPackages(options)
+	require.NoError(t, err)
+	require.NotNil(t, findField(pkgs[0], "Saz", "Point"))
+}
+
+func TestAllowedTypes(t *testing.T) {
+	report.TestMode()
+	defer report.EndTestMode()
+
+	options := Options{
+		Packages:     []string{fixturesPkg},
+		AllowedTypes: []string{subpkg + ".Point", "github.com/google/uuid.UUID"},
+		Mappings: protobuf.TypeMappings{
+			subpkg + ".Point": &protobuf.ProtoType{Name: "bytes", Basic: true},
+		},
+	}
+
+	_, err := scanPackages(options)
+	require.Error(t, err)
+	require.Equal(t, "allowed types have no mapping to a protobuf type: github.com/google/uuid.UUID", err.Error())
+
+	options.AllowedTypes = options.AllowedTypes[:1]
+	pkgs, err := scanPackages(options)
+	require.NoError(t, err)
+	require.NotNil(t, findField(pkgs[0], "Saz", "Point"))
+
+	options.AllowedTypes = []string{"*.Point"}
+	options.Mappings = nil
+	_, err = scanPackages(options)
+	require.Error(t, err, "types matching allowed patterns must have a mapping")
+}
+
+func TestAllowedPackageMappings(t *testing.T) {
+	report.TestMode()
+	defer report.EndTestMode()
+
+	point := protobuf.TypeMappings{
+		subpkg + ".Point": &protobuf.ProtoType{Name: "bytes", Basic: true},
+	}
+	options := Options{
+		Packages:        []string{fixturesPkg},
+		PackageMappings: map[string]protobuf.TypeMappings{fixturesPkg: point},
+	}
+
+	pkgs, err := scanPackages(options)
+	require.NoError(t, err)
+	require.NotNil(t, findField(pkgs[0], "Saz", "Point"), "types with a mapping of the package are kept")
+
+	options.PackageMappings = map[string]protobuf.TypeMappings{"github.com/example/other": point}
+	_, err = scanPackages(options)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "field "+fixturesPkg+".Saz.Point has type "+subpkg+".Point, which is allowed but has no mapping")
+}
+
+func findField(pkg *scanner.Package, structName, name string) *scanner.Field {
+	for _, s := range pkg.Structs {
+		if s.Name != structName {
+			continue
+		}
+
+		for _, f := range s.Fields {
+			if f.Name == name {
+				return f
+			}
+		}
+	}
+	return nil
+}
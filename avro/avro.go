@@ -0,0 +1,409 @@
+package avro // import "gopkg.in/src-d/proteus.v1/avro"
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+// Generator is in charge of generating the Avro schemas of the messages and
+// enums of a protobuf package and write them to disk at the given path, in a
+// file named "NAME.avsc" for each one of them. The messages generated for
+// the requests and responses of the RPCs are not written.
+//
+// Messages are records in the namespace of the proto package and every
+// schema is self-contained: the records and enums of the same package are
+// defined the first time they are used and referenced by name afterwards.
+// Records and enums of other packages are referenced by their full name, so
+// their schemas must be registered as well.
+//
+// Every field has a default value, so fields can be added to the structs
+// without breaking the readers of the old data:
+//   - fields that can be null in Go, and wrappers of the well-known types,
+//     are unions with null, which is their default value.
+//   - the rest of fields default to the zero value of their type, the first
+//     symbol for enums, except records, which have no default value.
+//
+// Timestamps are longs with the timestamp-micros logical type, durations are
+// longs with the number of nanoseconds and maps have string keys, as Avro
+// does not support other types of keys.
+type Generator struct {
+	basePath    string
+	checkCompat bool
+}
+
+// NewGenerator creates a new Generator with the given base path.
+func NewGenerator(basePath string) *Generator {
+	return &Generator{basePath: basePath}
+}
+
+// EnableCompatibilityCheck makes the generator check that the schemas can
+// read the data written with the schemas already on disk, if any, before
+// overwriting them. If they cannot, the generation fails.
+func (g *Generator) EnableCompatibilityCheck() {
+	g.checkCompat = true
+}
+
+// Generate generates the Avro schemas of the messages and enums of the given
+// package and writes them to disk.
+func (g *Generator) Generate(pkg *protobuf.Package) error {
+	b := newBuilder(pkg)
+	for _, msg := range pkg.Messages {
+		if b.generated[msg.Name] {
+			continue
+		}
+
+		if err := g.writeSchema(pkg.Path, msg.Name, b.schema(msg.Name)); err != nil {
+			return err
+		}
+	}
+
+	for _, enum := range pkg.Enums {
+		if err := g.writeSchema(pkg.Path, enum.Name, b.schema(enum.Name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g *Generator) writeSchema(path, name string, s interface{}) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path = filepath.Join(g.basePath, path)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return err
+	}
+
+	file := filepath.Join(path, name+".avsc")
+	if g.checkCompat {
+		if err := checkFile(file, data); err != nil {
+			return err
+		}
+	}
+
+	if err := ioutil.WriteFile(file, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+
+	report.Info("Generated Avro schema: %s", file)
+	return nil
+}
+
+// checkFile checks that the schema can read the data written with the
+// schema in the given file, if it exists.
+func checkFile(file string, schema []byte) error {
+	old, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	problems, err := CheckCompatibility(schema, old)
+	if err != nil {
+		return fmt.Errorf("cannot check the compatibility of %s: %s", file, err)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf(
+			"the new schema of %s cannot read the data written with the previous one:\n\t%s",
+			file,
+			strings.Join(problems, "\n\t"),
+		)
+	}
+
+	return nil
+}
+
+type record struct {
+	Type      string   `json:"type"`
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace,omitempty"`
+	Doc       string   `json:"doc,omitempty"`
+	Fields    []*field `json:"fields"`
+}
+
+type field struct {
+	Name    string          `json:"name"`
+	Doc     string          `json:"doc,omitempty"`
+	Type    interface{}     `json:"type"`
+	Default json.RawMessage `json:"default,omitempty"`
+}
+
+type enum struct {
+	Type      string   `json:"type"`
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace,omitempty"`
+	Doc       string   `json:"doc,omitempty"`
+	Symbols   []string `json:"symbols"`
+	Default   string   `json:"default,omitempty"`
+}
+
+type array struct {
+	Type  string      `json:"type"`
+	Items interface{} `json:"items"`
+}
+
+type mapSchema struct {
+	Type   string      `json:"type"`
+	Values interface{} `json:"values"`
+}
+
+type logical struct {
+	Type        string `json:"type"`
+	LogicalType string `json:"logicalType"`
+}
+
+// builder builds the schemas of a package.
+type builder struct {
+	pkg      *protobuf.Package
+	messages map[string]*protobuf.Message
+	enums    map[string]*protobuf.Enum
+	// generated are the messages generated for the requests and responses
+	// of the RPCs.
+	generated map[string]bool
+	// defined are the names already defined in the schema being built.
+	defined map[string]bool
+}
+
+func newBuilder(pkg *protobuf.Package) *builder {
+	b := &builder{
+		pkg:       pkg,
+		messages:  make(map[string]*protobuf.Message),
+		enums:     make(map[string]*protobuf.Enum),
+		generated: make(map[string]bool),
+	}
+
+	for _, msg := range pkg.Messages {
+		b.messages[msg.Name] = msg
+	}
+
+	for _, e := range pkg.Enums {
+		b.enums[e.Name] = e
+	}
+
+	for _, rpc := range pkg.RPCs {
+		for _, typ := range []protobuf.Type{rpc.Input, rpc.Output} {
+			if named, ok := typ.(*protobuf.Named); ok && named.Generated && named.Package == pkg.Name {
+				b.generated[named.Name] = true
+			}
+		}
+	}
+
+	return b
+}
+
+// schema returns the self-contained schema of the record or enum with the
+// given name, in the namespace of the package.
+func (b *builder) schema(name string) interface{} {
+	b.defined = make(map[string]bool)
+	switch s := b.localSchema(name).(type) {
+	case *record:
+		s.Namespace = b.pkg.Name
+		return s
+	case *enum:
+		s.Namespace = b.pkg.Name
+		return s
+	default:
+		return s
+	}
+}
+
+// localSchema returns the definition of a record or enum of the package, or
+// its name if it was already defined.
+func (b *builder) localSchema(name string) interface{} {
+	if b.defined[name] {
+		return name
+	}
+
+	if msg, ok := b.messages[name]; ok {
+		b.defined[name] = true
+		return b.record(msg)
+	}
+
+	if e, ok := b.enums[name]; ok {
+		b.defined[name] = true
+		return enumSchema(e)
+	}
+
+	return name
+}
+
+func (b *builder) record(msg *protobuf.Message) *record {
+	r := &record{
+		Type:   "record",
+		Name:   msg.Name,
+		Doc:    protobuf.Description(msg.Docs),
+		Fields: make([]*field, 0, len(msg.Fields)),
+	}
+
+	for _, f := range msg.Fields {
+		r.Fields = append(r.Fields, b.field(f))
+	}
+
+	return r
+}
+
+func (b *builder) field(f *protobuf.Field) *field {
+	fs := &field{Name: f.Name, Doc: protobuf.Description(f.Docs)}
+	switch {
+	case f.Repeated:
+		fs.Type = &array{Type: "array", Items: b.typeSchema(f.Type)}
+		fs.Default = json.RawMessage("[]")
+	case b.isNullable(f.Type):
+		fs.Type = []interface{}{"null", b.typeSchema(f.Type)}
+		fs.Default = json.RawMessage("null")
+	default:
+		fs.Type = b.typeSchema(f.Type)
+		fs.Default = b.defaultValue(f.Type)
+	}
+
+	return fs
+}
+
+func enumSchema(e *protobuf.Enum) *enum {
+	s := &enum{
+		Type:    "enum",
+		Name:    e.Name,
+		Doc:     protobuf.Description(e.Docs),
+		Symbols: make([]string, len(e.Values)),
+	}
+
+	for i, v := range e.Values {
+		s.Symbols[i] = v.Name
+	}
+
+	if len(s.Symbols) > 0 {
+		s.Default = s.Symbols[0]
+	}
+
+	return s
+}
+
+// isNullable reports whether the type is a record that can be null in Go or
+// a wrapper of the well-known types.
+func (b *builder) isNullable(typ protobuf.Type) bool {
+	named, ok := typ.(*protobuf.Named)
+	if !ok {
+		return false
+	}
+
+	if named.Package == "google.protobuf" {
+		_, wrapper := protobuf.WrapperScalar(named.Name)
+		return wrapper || named.IsNullable()
+	}
+
+	_, isMessage := b.messages[named.Name]
+	return (isMessage || named.Package != b.pkg.Name) && named.IsNullable()
+}
+
+// defaultValue returns the default value of a field of the given type, which
+// is its zero value, or nil if it has no default value.
+func (b *builder) defaultValue(typ protobuf.Type) json.RawMessage {
+	switch t := typ.(type) {
+	case *protobuf.Alias:
+		return b.defaultValue(t.Underlying)
+	case *protobuf.Map:
+		return json.RawMessage("{}")
+	case *protobuf.Named:
+		if e, ok := b.enums[t.Name]; ok && t.Package == b.pkg.Name && len(e.Values) > 0 {
+			return json.RawMessage(fmt.Sprintf("%q", e.Values[0].Name))
+		}
+
+		if t.Package != "google.protobuf" {
+			return nil
+		}
+	}
+
+	switch b.typeSchema(typ) {
+	case "boolean":
+		return json.RawMessage("false")
+	case "string", "bytes":
+		return json.RawMessage(`""`)
+	case "int", "long", "float", "double", timestampMicros:
+		return json.RawMessage("0")
+	}
+
+	return nil
+}
+
+func (b *builder) typeSchema(typ protobuf.Type) interface{} {
+	switch t := typ.(type) {
+	case *protobuf.Basic:
+		return basicSchema(t.Name)
+	case *protobuf.Alias:
+		return b.typeSchema(t.Underlying)
+	case *protobuf.Map:
+		return &mapSchema{Type: "map", Values: b.typeSchema(t.Value)}
+	case *protobuf.Named:
+		return b.namedSchema(t)
+	}
+
+	return "bytes"
+}
+
+func (b *builder) namedSchema(t *protobuf.Named) interface{} {
+	if t.Package == b.pkg.Name {
+		return b.localSchema(t.Name)
+	}
+
+	if t.Package == "google.protobuf" {
+		return wellKnownSchema(t.Name)
+	}
+
+	if _, ok := t.Source().(*scanner.Named); ok {
+		return fmt.Sprintf("%s.%s", t.Package, t.Name)
+	}
+
+	report.Warn("type %s cannot be referenced from the Avro schemas of %s, using bytes", t, b.pkg.Name)
+	return "bytes"
+}
+
+// basicSchema returns the Avro type of a scalar type. Avro has no unsigned
+// integers, so they are signed integers of the next size, except for 64-bit
+// integers, which keep their bits.
+func basicSchema(name string) string {
+	switch name {
+	case "bool":
+		return "boolean"
+	case "int32", "sint32", "sfixed32":
+		return "int"
+	case "uint32", "fixed32", "int64", "sint64", "sfixed64", "uint64", "fixed64":
+		return "long"
+	case "float", "double", "string", "bytes":
+		return name
+	}
+
+	return "bytes"
+}
+
+var timestampMicros = logical{Type: "long", LogicalType: "timestamp-micros"}
+
+// wellKnownSchema returns the Avro type of a well-known type. Wrappers are
+// their scalar type, in a union with null, and the types with no Avro
+// counterpart, such as google.protobuf.Struct, are JSON strings.
+func wellKnownSchema(name string) interface{} {
+	if scalar, ok := protobuf.WrapperScalar(name); ok {
+		return basicSchema(scalar)
+	}
+
+	switch name {
+	case "Timestamp":
+		return timestampMicros
+	case "Duration":
+		return "long"
+	}
+
+	return "string"
+}
@@ -0,0 +1,204 @@
+package avro
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/protobuf/protobuftest"
+)
+
+// mockPackage returns the fixture package with a record that is not
+// nullable.
+func mockPackage() *protobuf.Package {
+	pkg := protobuftest.Package()
+	pkg.Messages[0].Fields = append(pkg.Messages[0].Fields, &protobuf.Field{
+		Name: "home",
+		Pos:  13,
+		Type: protobuftest.NotNullable(protobuftest.Named("Address")),
+	})
+	return pkg
+}
+
+const expectedUser = `{
+  "type": "record",
+  "name": "User",
+  "namespace": "example.foo",
+  "doc": "User is an user.",
+  "fields": [
+    {
+      "name": "id",
+      "doc": "ID of the user.",
+      "type": "long",
+      "default": 0
+    },
+    {
+      "name": "first_name",
+      "type": "string",
+      "default": ""
+    },
+    {
+      "name": "age",
+      "type": "long",
+      "default": 0
+    },
+    {
+      "name": "status",
+      "type": {
+        "type": "enum",
+        "name": "Status",
+        "doc": "Status of an user.",
+        "symbols": [
+          "ACTIVE",
+          "BANNED"
+        ],
+        "default": "ACTIVE"
+      },
+      "default": "ACTIVE"
+    },
+    {
+      "name": "address",
+      "doc": "Address of the user.",
+      "type": [
+        "null",
+        {
+          "type": "record",
+          "name": "Address",
+          "fields": [
+            {
+              "name": "city",
+              "type": "string",
+              "default": ""
+            }
+          ]
+        }
+      ],
+      "default": null
+    },
+    {
+      "name": "tags",
+      "type": {
+        "type": "array",
+        "items": "string"
+      },
+      "default": []
+    },
+    {
+      "name": "created_at",
+      "type": [
+        "null",
+        {
+          "type": "long",
+          "logicalType": "timestamp-micros"
+        }
+      ],
+      "default": null
+    },
+    {
+      "name": "nickname",
+      "type": [
+        "null",
+        "string"
+      ],
+      "default": null
+    },
+    {
+      "name": "score",
+      "type": [
+        "null",
+        "double"
+      ],
+      "default": null
+    },
+    {
+      "name": "labels",
+      "type": {
+        "type": "map",
+        "values": "int"
+      },
+      "default": {}
+    },
+    {
+      "name": "group",
+      "type": "example.bar.Group"
+    },
+    {
+      "name": "avatar",
+      "type": "bytes",
+      "default": ""
+    },
+    {
+      "name": "home",
+      "type": "Address"
+    }
+  ]
+}
+`
+
+const expectedStatus = `{
+  "type": "enum",
+  "name": "Status",
+  "namespace": "example.foo",
+  "doc": "Status of an user.",
+  "symbols": [
+    "ACTIVE",
+    "BANNED"
+  ],
+  "default": "ACTIVE"
+}
+`
+
+func TestGenerate(t *testing.T) {
+	path, err := ioutil.TempDir("", "proteus")
+	require.Nil(t, err)
+	defer os.RemoveAll(path)
+
+	require.Nil(t, NewGenerator(path).Generate(mockPackage()))
+
+	dir := filepath.Join(path, "github.com/example/foo")
+	data, err := ioutil.ReadFile(filepath.Join(dir, "User.avsc"))
+	require.Nil(t, err)
+	require.Equal(t, expectedUser, string(data))
+
+	data, err = ioutil.ReadFile(filepath.Join(dir, "Status.avsc"))
+	require.Nil(t, err)
+	require.Equal(t, expectedStatus, string(data))
+
+	_, err = os.Stat(filepath.Join(dir, "Address.avsc"))
+	require.Nil(t, err)
+
+	_, err = os.Stat(filepath.Join(dir, "GetUserRequest.avsc"))
+	require.True(t, os.IsNotExist(err), "generated requests are not written")
+}
+
+func TestGenerateCompatibilityCheck(t *testing.T) {
+	path, err := ioutil.TempDir("", "proteus")
+	require.Nil(t, err)
+	defer os.RemoveAll(path)
+
+	g := NewGenerator(path)
+	g.EnableCompatibilityCheck()
+	require.Nil(t, g.Generate(mockPackage()))
+
+	pkg := mockPackage()
+	pkg.Messages[0].Fields = append(pkg.Messages[0].Fields, &protobuf.Field{
+		Name: "email", Pos: 14, Type: protobuf.NewBasic("string"),
+	})
+	require.Nil(t, g.Generate(pkg), "new fields have default values")
+
+	pkg = mockPackage()
+	pkg.Messages[0].Fields[1].Type = protobuf.NewBasic("int32")
+	err = g.Generate(pkg)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "User.first_name: string cannot be read as int")
+
+	data, err := ioutil.ReadFile(filepath.Join(path, "github.com/example/foo", "User.avsc"))
+	require.Nil(t, err)
+	require.Contains(t, string(data), `"email"`, "incompatible schemas are not written")
+
+	require.Nil(t, NewGenerator(path).Generate(pkg), "the check is disabled by default")
+}
@@ -0,0 +1,300 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CheckCompatibility checks that the data written with the writer schema can
+// be read with the reader schema, following the schema resolution rules of
+// the Avro specification, and returns the problems found, if any. This is
+// the backward compatibility of schema registries, where the reader schema
+// is the new one.
+func CheckCompatibility(reader, writer []byte) ([]string, error) {
+	var r, w interface{}
+	if err := json.Unmarshal(reader, &r); err != nil {
+		return nil, fmt.Errorf("invalid reader schema: %s", err)
+	}
+
+	if err := json.Unmarshal(writer, &w); err != nil {
+		return nil, fmt.Errorf("invalid writer schema: %s", err)
+	}
+
+	c := &checker{
+		reader:  newNames(r),
+		writer:  newNames(w),
+		checked: make(map[string]bool),
+	}
+	return c.check(r, w, ""), nil
+}
+
+// names are the named types defined in a schema, by full name.
+type names map[string]map[string]interface{}
+
+func newNames(schema interface{}) names {
+	n := make(names)
+	n.collect(schema, "")
+	return n
+}
+
+func (n names) collect(schema interface{}, namespace string) {
+	switch s := schema.(type) {
+	case []interface{}:
+		for _, branch := range s {
+			n.collect(branch, namespace)
+		}
+	case map[string]interface{}:
+		switch s["type"] {
+		case "record", "error":
+			name := fullName(s, namespace)
+			n[name] = s
+			fields, _ := s["fields"].([]interface{})
+			for _, f := range fields {
+				if f, ok := f.(map[string]interface{}); ok {
+					n.collect(f["type"], namespaceOf(name))
+				}
+			}
+		case "enum", "fixed":
+			n[fullName(s, namespace)] = s
+		case "array":
+			n.collect(s["items"], namespace)
+		case "map":
+			n.collect(s["values"], namespace)
+		}
+	}
+}
+
+// resolve returns the definition of the schema if it is a reference to a
+// named type, and the namespace of its inner references.
+func (n names) resolve(schema interface{}, namespace string) (interface{}, string) {
+	if name, ok := schema.(string); ok && !isPrimitive(name) {
+		if !strings.Contains(name, ".") && namespace != "" {
+			name = namespace + "." + name
+		}
+
+		if def, ok := n[name]; ok {
+			return def, namespaceOf(name)
+		}
+	}
+
+	if s, ok := schema.(map[string]interface{}); ok {
+		switch s["type"] {
+		case "record", "error", "enum", "fixed":
+			return s, namespaceOf(fullName(s, namespace))
+		}
+	}
+
+	return schema, namespace
+}
+
+func fullName(s map[string]interface{}, namespace string) string {
+	name, _ := s["name"].(string)
+	if strings.Contains(name, ".") {
+		return name
+	}
+
+	if ns, ok := s["namespace"].(string); ok {
+		namespace = ns
+	}
+
+	if namespace == "" {
+		return name
+	}
+
+	return namespace + "." + name
+}
+
+func namespaceOf(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+func shortName(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func isPrimitive(name string) bool {
+	switch name {
+	case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+		return true
+	}
+	return false
+}
+
+// promotions are the primitive types the data of every primitive type can
+// be read as, besides its own type.
+var promotions = map[string][]string{
+	"int":    {"long", "float", "double"},
+	"long":   {"float", "double"},
+	"float":  {"double"},
+	"string": {"bytes"},
+	"bytes":  {"string"},
+}
+
+// checker checks the compatibility of two schemas.
+type checker struct {
+	reader, writer names
+	// checked are the pairs of named types already checked, to stop at
+	// recursive types.
+	checked map[string]bool
+}
+
+func (c *checker) check(r, w interface{}, path string) []string {
+	return c.checkIn(r, "", w, "", path)
+}
+
+func (c *checker) checkIn(r interface{}, rns string, w interface{}, wns string, path string) []string {
+	r, rns = c.reader.resolve(r, rns)
+	w, wns = c.writer.resolve(w, wns)
+
+	if branches, ok := w.([]interface{}); ok {
+		var problems []string
+		for _, branch := range branches {
+			problems = append(problems, c.checkIn(r, rns, branch, wns, path)...)
+		}
+		return problems
+	}
+
+	if branches, ok := r.([]interface{}); ok {
+		for _, branch := range branches {
+			if len(c.checkIn(branch, rns, w, wns, path)) == 0 {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s: %s is not in the union %s", location(path), typeName(w), typeName(r))}
+	}
+
+	rt, wt := typeOf(r), typeOf(w)
+	if rt != wt {
+		for _, t := range promotions[wt] {
+			if t == rt {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s: %s cannot be read as %s", location(path), typeName(w), typeName(r))}
+	}
+
+	rs, _ := r.(map[string]interface{})
+	ws, _ := w.(map[string]interface{})
+	switch rt {
+	case "record", "error":
+		return c.checkRecord(rs, rns, ws, wns, path)
+	case "enum":
+		return checkEnum(rs, ws, path)
+	case "fixed":
+		if rs["name"] != ws["name"] || rs["size"] != ws["size"] {
+			return []string{fmt.Sprintf("%s: %s cannot be read as %s", location(path), typeName(w), typeName(r))}
+		}
+	case "array":
+		return c.checkIn(rs["items"], rns, ws["items"], wns, path+"[]")
+	case "map":
+		return c.checkIn(rs["values"], rns, ws["values"], wns, path+"{}")
+	}
+
+	return nil
+}
+
+func (c *checker) checkRecord(r map[string]interface{}, rns string, w map[string]interface{}, wns string, path string) []string {
+	rname, wname := fullName(r, rns), fullName(w, wns)
+	if shortName(rname) != shortName(wname) {
+		return []string{fmt.Sprintf("%s: record %s cannot be read as record %s", location(path), wname, rname)}
+	}
+
+	key := rname + " " + wname
+	if c.checked[key] {
+		return nil
+	}
+	c.checked[key] = true
+
+	written := make(map[string]map[string]interface{})
+	fields, _ := w["fields"].([]interface{})
+	for _, f := range fields {
+		if f, ok := f.(map[string]interface{}); ok {
+			name, _ := f["name"].(string)
+			written[name] = f
+		}
+	}
+
+	if path == "" {
+		path = shortName(rname)
+	}
+
+	var problems []string
+	fields, _ = r["fields"].([]interface{})
+	for _, f := range fields {
+		rf, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, _ := rf["name"].(string)
+		wf, ok := written[name]
+		if !ok {
+			if _, ok := rf["default"]; !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s: new field has no default value", path, name))
+			}
+			continue
+		}
+
+		problems = append(problems, c.checkIn(rf["type"], namespaceOf(rname), wf["type"], namespaceOf(wname), path+"."+name)...)
+	}
+
+	return problems
+}
+
+func checkEnum(r, w map[string]interface{}, path string) []string {
+	if _, ok := r["default"]; ok {
+		return nil
+	}
+
+	symbols := make(map[interface{}]bool)
+	rsymbols, _ := r["symbols"].([]interface{})
+	for _, s := range rsymbols {
+		symbols[s] = true
+	}
+
+	var problems []string
+	wsymbols, _ := w["symbols"].([]interface{})
+	for _, s := range wsymbols {
+		if !symbols[s] {
+			problems = append(problems, fmt.Sprintf("%s: enum symbol %v was removed", location(path), s))
+		}
+	}
+
+	return problems
+}
+
+func typeOf(schema interface{}) string {
+	switch s := schema.(type) {
+	case string:
+		return s
+	case map[string]interface{}:
+		t, _ := s["type"].(string)
+		return t
+	}
+	return ""
+}
+
+func typeName(schema interface{}) string {
+	if s, ok := schema.(map[string]interface{}); ok {
+		if name, ok := s["name"].(string); ok {
+			return fmt.Sprintf("%s %s", s["type"], name)
+		}
+	}
+
+	if _, ok := schema.([]interface{}); ok {
+		return "union"
+	}
+
+	return typeOf(schema)
+}
+
+func location(path string) string {
+	if path == "" {
+		return "schema"
+	}
+	return path
+}
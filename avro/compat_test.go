@@ -0,0 +1,82 @@
+package avro
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const userSchema = `{
+  "type": "record",
+  "name": "User",
+  "namespace": "example.foo",
+  "fields": [
+    {"name": "id", "type": "int"},
+    {"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["ACTIVE", "BANNED"]}},
+    {"name": "address", "type": ["null", {"type": "record", "name": "Address", "fields": [{"name": "city", "type": "string"}]}]},
+    {"name": "friends", "type": {"type": "array", "items": "User"}}
+  ]
+}`
+
+func TestCheckCompatibility(t *testing.T) {
+	cases := []struct {
+		name     string
+		reader   string
+		problems []string
+	}{
+		{"same schema", userSchema, nil},
+		{
+			"promotion and new field with default",
+			`{"type": "record", "name": "User", "namespace": "example.foo", "fields": [
+				{"name": "id", "type": "long"},
+				{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["ACTIVE", "BANNED", "DELETED"]}},
+				{"name": "address", "type": ["null", {"type": "record", "name": "Address", "fields": [{"name": "city", "type": "bytes"}]}]},
+				{"name": "friends", "type": {"type": "array", "items": "User"}},
+				{"name": "email", "type": "string", "default": ""}
+			]}`,
+			nil,
+		},
+		{
+			"incompatible changes",
+			`{"type": "record", "name": "User", "namespace": "example.foo", "fields": [
+				{"name": "id", "type": "string"},
+				{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["ACTIVE"]}},
+				{"name": "address", "type": {"type": "record", "name": "Address", "fields": [{"name": "city", "type": "string"}]}},
+				{"name": "friends", "type": {"type": "array", "items": "User"}},
+				{"name": "email", "type": "string"}
+			]}`,
+			[]string{
+				"User.id: int cannot be read as string",
+				"User.status: enum symbol BANNED was removed",
+				"User.address: null cannot be read as record Address",
+				"User.email: new field has no default value",
+			},
+		},
+		{
+			"enum with default",
+			`{"type": "record", "name": "User", "namespace": "example.foo", "fields": [
+				{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["ACTIVE"], "default": "ACTIVE"}}
+			]}`,
+			nil,
+		},
+		{
+			"renamed record",
+			`{"type": "record", "name": "Customer", "namespace": "example.foo", "fields": []}`,
+			[]string{"schema: record example.foo.User cannot be read as record example.foo.Customer"},
+		},
+	}
+
+	for _, c := range cases {
+		problems, err := CheckCompatibility([]byte(c.reader), []byte(userSchema))
+		require.Nil(t, err, c.name)
+		require.Equal(t, c.problems, problems, c.name)
+	}
+}
+
+func TestCheckCompatibilityInvalid(t *testing.T) {
+	_, err := CheckCompatibility([]byte(`{`), []byte(userSchema))
+	require.NotNil(t, err)
+
+	_, err = CheckCompatibility([]byte(userSchema), []byte(`[`))
+	require.NotNil(t, err)
+}
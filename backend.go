@@ -0,0 +1,154 @@
+package proteus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/src-d/proteus.v1/glue"
+	"gopkg.in/src-d/proteus.v1/plugin"
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/rpc"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+// Backend generates the artifacts of the scanned packages, such as the
+// .proto files or the gRPC server implementation.
+type Backend interface {
+	// Generate writes the artifacts of the given packages, which are
+	// already resolved, according to the given options.
+	Generate(options Options, pkgs []*scanner.Package) error
+}
+
+// BackendFunc is a function that implements the Backend interface.
+type BackendFunc func(Options, []*scanner.Package) error
+
+// Generate calls f(options, pkgs).
+func (f BackendFunc) Generate(options Options, pkgs []*scanner.Package) error {
+	return f(options, pkgs)
+}
+
+var (
+	backendsMu sync.RWMutex
+	backends   = make(map[string]Backend)
+)
+
+// RegisterBackend makes a backend available by the given name, so it can be
+// used with Generate. If RegisterBackend is called twice with the same name
+// or if backend is nil, it panics.
+func RegisterBackend(name string, backend Backend) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	if backend == nil {
+		panic("proteus: RegisterBackend backend is nil")
+	}
+
+	if _, ok := backends[name]; ok {
+		panic("proteus: RegisterBackend called twice for backend " + name)
+	}
+
+	backends[name] = backend
+}
+
+// Backends returns the sorted names of the registered backends.
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	var names []string
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Generate scans and resolves the packages in the given options and
+// generates their artifacts with the backend registered by the given name
+// or, if there is none, with the plugin with that name.
+func Generate(backend string, options Options) error {
+	backendsMu.RLock()
+	b, ok := backends[backend]
+	backendsMu.RUnlock()
+
+	if !ok {
+		if _, err := plugin.Lookup(backend); err != nil {
+			return fmt.Errorf(
+				"unknown backend %q and there is no %s%s plugin in the PATH, the available backends are: %s",
+				backend, plugin.Prefix, backend, strings.Join(Backends(), ", "),
+			)
+		}
+		b = pluginBackend(backend)
+	}
+
+	pkgs, err := scanPackages(options)
+	if err != nil {
+		return err
+	}
+
+	return b.Generate(options, pkgs)
+}
+
+// ProtobufBackend is a backend that generates the artifacts of the packages
+// from their protobuf representation, with the generator it creates for the
+// options.
+type ProtobufBackend func(Options) ProtobufGenerator
+
+// Generate transforms the packages and generates their artifacts with the
+// generator created for the options.
+func (b ProtobufBackend) Generate(options Options, pkgs []*scanner.Package) error {
+	return TransformToProtobuf(options, pkgs, b(options))
+}
+
+// pluginBackend is a backend that generates the files returned by the
+// plugin with its name.
+type pluginBackend string
+
+func (b pluginBackend) Generate(options Options, pkgs []*scanner.Package) error {
+	var protos []*protobuf.Package
+	err := TransformToProtobuf(options, pkgs, func(_ *scanner.Package, pkg *protobuf.Package) error {
+		protos = append(protos, pkg)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	name := string(b)
+	resp, err := plugin.Run(name, plugin.NewRequest(name, pkgs, protos))
+	if err != nil {
+		return err
+	}
+
+	if len(resp.Patches) > 0 {
+		report.Warn("plugin %s was used as backend, its patches are ignored", name)
+	}
+
+	return resp.WriteFiles(options.BasePath)
+}
+
+func init() {
+	RegisterBackend("proto", BackendFunc(generateProtos))
+	RegisterBackend("rpc", ProtobufBackend(rpcGenerator))
+	RegisterBackend("glue", ProtobufBackend(glueGenerator))
+}
+
+func rpcGenerator(options Options) ProtobufGenerator {
+	g := rpc.NewGenerator()
+	if options.ValidateRequests {
+		g.EnableValidation()
+	}
+	return func(p *scanner.Package, pkg *protobuf.Package) error {
+		return g.Generate(pkg, p.Path)
+	}
+}
+
+func glueGenerator(options Options) ProtobufGenerator {
+	g := glue.NewGenerator()
+	return func(p *scanner.Package, pkg *protobuf.Package) error {
+		return g.Generate(pkg, p.Path)
+	}
+}
@@ -0,0 +1,53 @@
+package proteus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+const fixturesPkg = "gopkg.in/src-d/proteus.v1/fixtures"
+
+// unregisterBackend removes the backend registered by the given name, so
+// tests registering backends can run more than once.
+func unregisterBackend(name string) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	delete(backends, name)
+}
+
+func TestGenerateWithBackend(t *testing.T) {
+	var generated []*scanner.Package
+	RegisterBackend("test", BackendFunc(func(options Options, pkgs []*scanner.Package) error {
+		require.Equal(t, "foo", options.BasePath)
+		generated = pkgs
+		return nil
+	}))
+	defer unregisterBackend("test")
+
+	require.Nil(t, Generate("test", Options{BasePath: "foo", Packages: []string{fixturesPkg}}))
+	require.Len(t, generated, 1)
+	require.Equal(t, fixturesPkg, generated[0].Path)
+	require.True(t, generated[0].Resolved, "packages are resolved")
+}
+
+func TestGenerateUnknownBackend(t *testing.T) {
+	err := Generate("nope", Options{Packages: []string{fixturesPkg}})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), `unknown backend "nope"`)
+	require.Contains(t, err.Error(), "proto, ")
+}
+
+func TestRegisterBackend(t *testing.T) {
+	require.Equal(t, []string{"glue", "proto", "rpc"}, Backends())
+
+	require.Panics(t, func() {
+		RegisterBackend("proto", BackendFunc(func(Options, []*scanner.Package) error { return nil }))
+	})
+
+	require.Panics(t, func() {
+		RegisterBackend("nil", nil)
+	})
+}
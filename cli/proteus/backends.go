@@ -0,0 +1,81 @@
+package main
+
+import (
+	"gopkg.in/src-d/proteus.v1"
+	"gopkg.in/src-d/proteus.v1/avro"
+	"gopkg.in/src-d/proteus.v1/flatbuffers"
+	"gopkg.in/src-d/proteus.v1/graphql"
+	"gopkg.in/src-d/proteus.v1/jsonschema"
+	"gopkg.in/src-d/proteus.v1/openapi"
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/scanner"
+	"gopkg.in/src-d/proteus.v1/thrift"
+	"gopkg.in/src-d/proteus.v1/typescript"
+)
+
+// The backends of the formats other than the proto files, the gRPC server
+// implementation and the glue code, which are registered by proteus itself.
+func init() {
+	proteus.RegisterBackend("openapi", proteus.ProtobufBackend(openAPIGenerator))
+	proteus.RegisterBackend("jsonschema", proteus.ProtobufBackend(jsonSchemaGenerator))
+	proteus.RegisterBackend("typescript", proteus.ProtobufBackend(typeScriptGenerator))
+	proteus.RegisterBackend("graphql", proteus.ProtobufBackend(graphQLGenerator))
+	proteus.RegisterBackend("thrift", proteus.ProtobufBackend(thriftGenerator))
+	proteus.RegisterBackend("avro", proteus.ProtobufBackend(avroGenerator))
+	proteus.RegisterBackend("flatbuffers", proteus.ProtobufBackend(flatBuffersGenerator))
+}
+
+func openAPIGenerator(options proteus.Options) proteus.ProtobufGenerator {
+	g := openapi.NewGenerator(options.BasePath)
+	if options.APIVersion != "" {
+		g.SetAPIVersion(options.APIVersion)
+	}
+	return func(_ *scanner.Package, pkg *protobuf.Package) error {
+		return g.Generate(pkg)
+	}
+}
+
+func jsonSchemaGenerator(options proteus.Options) proteus.ProtobufGenerator {
+	g := jsonschema.NewGenerator(options.BasePath)
+	return func(_ *scanner.Package, pkg *protobuf.Package) error {
+		return g.Generate(pkg)
+	}
+}
+
+func typeScriptGenerator(options proteus.Options) proteus.ProtobufGenerator {
+	g := typescript.NewGenerator(options.BasePath)
+	return func(_ *scanner.Package, pkg *protobuf.Package) error {
+		return g.Generate(pkg)
+	}
+}
+
+func graphQLGenerator(options proteus.Options) proteus.ProtobufGenerator {
+	g := graphql.NewGenerator(options.BasePath)
+	return func(_ *scanner.Package, pkg *protobuf.Package) error {
+		return g.Generate(pkg)
+	}
+}
+
+func thriftGenerator(options proteus.Options) proteus.ProtobufGenerator {
+	g := thrift.NewGenerator(options.BasePath)
+	return func(_ *scanner.Package, pkg *protobuf.Package) error {
+		return g.Generate(pkg)
+	}
+}
+
+func avroGenerator(options proteus.Options) proteus.ProtobufGenerator {
+	g := avro.NewGenerator(options.BasePath)
+	if options.CheckCompatibility {
+		g.EnableCompatibilityCheck()
+	}
+	return func(_ *scanner.Package, pkg *protobuf.Package) error {
+		return g.Generate(pkg)
+	}
+}
+
+func flatBuffersGenerator(options proteus.Options) proteus.ProtobufGenerator {
+	g := flatbuffers.NewGenerator(options.BasePath)
+	return func(_ *scanner.Package, pkg *protobuf.Package) error {
+		return g.Generate(pkg)
+	}
+}
@@ -6,18 +6,37 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/src-d/proteus.v1"
 	"gopkg.in/src-d/proteus.v1/protobuf"
 	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/resolver"
 
 	"gopkg.in/urfave/cli.v1"
 )
 
 var (
-	packages cli.StringSlice
-	path     string
-	verbose  bool
+	packages       cli.StringSlice
+	plugins        cli.StringSlice
+	allowTypes     cli.StringSlice
+	allowPkgs      cli.StringSlice
+	followPrefixes cli.StringSlice
+	protoPaths     cli.StringSlice
+	path           string
+	verbose        bool
+	noStdlibTypes  bool
+	timeFormat     string
+	durationFormat string
+	validation     string
+	validate       bool
+	apiVersion     string
+	checkCompat    bool
+	backend        string
+	mappingsFile   string
+	follow         bool
+	followDepth    int
+	graphFormat    string
 )
 
 func main() {
@@ -32,11 +51,77 @@ func main() {
 			Usage: "Use `PACKAGE` as input for the generation. You can use this flag multiple times to specify more than one package.",
 			Value: &packages,
 		},
+		cli.StringSliceFlag{
+			Name:  "plugin",
+			Usage: "Run the proteus-gen-`NAME` plugin on the packages before generating them. You can use this flag multiple times to run more than one plugin.",
+			Value: &plugins,
+		},
 		cli.BoolFlag{
 			Name:        "verbose",
 			Usage:       "Print all warnings and info messages.",
 			Destination: &verbose,
 		},
+		cli.StringFlag{
+			Name:        "mappings",
+			Usage:       "Load custom mappings of Go types to protobuf types from the YAML or JSON `FILE`.",
+			Destination: &mappingsFile,
+		},
+		cli.StringSliceFlag{
+			Name:  "proto-path",
+			Usage: "Find the proto files imported by the mappings and the //proteus:proto directives in `FOLDER`, besides the output folder, the GOPATH and the current folder. You can use this flag multiple times.",
+			Value: &protoPaths,
+		},
+		cli.StringSliceFlag{
+			Name:  "allow-type",
+			Usage: "Keep the fields of `TYPE`, e.g. github.com/google/uuid.UUID or *.ID, even if its package is not scanned. It must have a mapping. You can use this flag multiple times.",
+			Value: &allowTypes,
+		},
+		cli.StringSliceFlag{
+			Name:  "allow-pkg",
+			Usage: "Keep the fields of the types of `PACKAGE` even if it is not scanned. They must have a mapping. You can use this flag multiple times.",
+			Value: &allowPkgs,
+		},
+		cli.BoolFlag{
+			Name:        "follow",
+			Usage:       "Load the packages that are not scanned but have types used by the generated ones, and generate those types, instead of ignoring the fields using them.",
+			Destination: &follow,
+		},
+		cli.StringSliceFlag{
+			Name:  "follow-prefix",
+			Usage: "Only follow the packages whose path starts with `PREFIX`. By default, the packages in the same module or repository are followed. You can use this flag multiple times.",
+			Value: &followPrefixes,
+		},
+		cli.IntFlag{
+			Name:        "follow-depth",
+			Usage:       "Follow at most `N` packages away from the scanned ones.",
+			Value:       resolver.DefaultFollowDepth,
+			Destination: &followDepth,
+		},
+		cli.BoolFlag{
+			Name:        "no-stdlib-types",
+			Usage:       "Do not use the built-in mappings for standard library types such as net.IP and their wrappers such as stdtypes.URL or stdtypes.BigInt.",
+			Destination: &noStdlibTypes,
+		},
+		cli.StringFlag{
+			Name:        "time-format",
+			Usage:       "Represent time.Time as `FORMAT` by default: wkt (google.protobuf.Timestamp), seconds, millis, nanos or string (RFC 3339).",
+			Destination: &timeFormat,
+		},
+		cli.StringFlag{
+			Name:        "duration-format",
+			Usage:       "Represent time.Duration as `FORMAT` by default: wkt (google.protobuf.Duration), seconds, millis, nanos or string.",
+			Destination: &durationFormat,
+		},
+		cli.StringFlag{
+			Name:        "validation",
+			Usage:       "Translate validate struct tags to `RULES`: pgv (protoc-gen-validate) or protovalidate.",
+			Destination: &validation,
+		},
+		cli.BoolFlag{
+			Name:        "validate-requests",
+			Usage:       "Validate the requests with their validate struct tags in the generated RPC server.",
+			Destination: &validate,
+		},
 	}
 
 	folderFlag := cli.StringFlag{
@@ -45,13 +130,38 @@ func main() {
 		Destination: &path,
 	}
 
+	apiVersionFlag := cli.StringFlag{
+		Name:        "api-version",
+		Usage:       "Use `VERSION` as the version of the API in the generated documents.",
+		Destination: &apiVersion,
+	}
+
+	checkCompatFlag := cli.BoolFlag{
+		Name:        "check-compat",
+		Usage:       "Fail if the generated schemas cannot read the data written with the schemas already in the folder.",
+		Destination: &checkCompat,
+	}
+
+	backendFlag := cli.StringFlag{
+		Name:        "backend, b",
+		Usage:       "Generate the artifacts of the backend registered as `NAME`.",
+		Destination: &backend,
+	}
+
+	graphFormatFlag := cli.StringFlag{
+		Name:        "format",
+		Usage:       "Print the graph in `FORMAT`, which is dot or json.",
+		Value:       "dot",
+		Destination: &graphFormat,
+	}
+
 	app.Flags = append(baseFlags, folderFlag)
 	app.Commands = []cli.Command{
 		{
 			Name:        "proto",
 			Description: "Generates .proto files from your Go source code.",
 			Usage:       "Generates .proto files from Go packages",
-			Action:      initCmd(genProtos),
+			Action:      initCmd(genWith("proto")),
 			Flags:       append(baseFlags, folderFlag),
 		},
 		{
@@ -61,6 +171,77 @@ func main() {
 			Action:      initCmd(genRPCServer),
 			Flags:       baseFlags,
 		},
+		{
+			Name:        "jsonschema",
+			Description: "Generates JSON schemas of the messages and enums generated from your Go source code.",
+			Usage:       "Generates JSON schemas from Go packages",
+			Action:      initCmd(genWith("jsonschema")),
+			Flags:       append(baseFlags, folderFlag),
+		},
+		{
+			Name:        "typescript",
+			Description: "Generates TypeScript definitions of the messages and services generated from your Go source code.",
+			Usage:       "Generates TypeScript definitions from Go packages",
+			Action:      initCmd(genWith("typescript")),
+			Flags:       append(baseFlags, folderFlag),
+		},
+		{
+			Name:        "graphql",
+			Description: "Generates GraphQL schemas of the types and functions of your Go source code.",
+			Usage:       "Generates GraphQL schemas from Go packages",
+			Action:      initCmd(genWith("graphql")),
+			Flags:       append(baseFlags, folderFlag),
+		},
+		{
+			Name:        "thrift",
+			Description: "Generates Thrift IDL files of the types and functions of your Go source code.",
+			Usage:       "Generates Thrift IDL files from Go packages",
+			Action:      initCmd(genWith("thrift")),
+			Flags:       append(baseFlags, folderFlag),
+		},
+		{
+			Name:        "avro",
+			Description: "Generates Avro schemas of the types of your Go source code.",
+			Usage:       "Generates Avro schemas from Go packages",
+			Action:      initCmd(genWith("avro")),
+			Flags:       append(baseFlags, folderFlag, checkCompatFlag),
+		},
+		{
+			Name:        "flatbuffers",
+			Description: "Generates FlatBuffers schemas of the types and functions of your Go source code.",
+			Usage:       "Generates FlatBuffers schemas from Go packages",
+			Action:      initCmd(genWith("flatbuffers")),
+			Flags:       append(baseFlags, folderFlag),
+		},
+		{
+			Name:        "openapi",
+			Description: "Generates OpenAPI 3 documents of the services defined by your Go source code.",
+			Usage:       "Generates OpenAPI documents from Go packages",
+			Action:      initCmd(genWith("openapi")),
+			Flags:       append(baseFlags, folderFlag, apiVersionFlag),
+		},
+		{
+			Name:        "explain",
+			Description: "Explains why the type, field or func with the given full name, such as github.com/org/pkg.Type.Field, is generated or excluded, with the decisions that led to it.",
+			Usage:       "Explains why a type, field or func is generated or excluded",
+			ArgsUsage:   "NAME",
+			Action:      initCmd(explain),
+			Flags:       baseFlags,
+		},
+		{
+			Name:        "graph",
+			Description: "Prints the graph of the messages, enums and RPCs generated from your Go source code, with the messages they reference, in the DOT language of Graphviz or in JSON.",
+			Usage:       "Prints the graph of the generated messages and RPCs",
+			Action:      initCmd(printGraph),
+			Flags:       append(baseFlags, graphFormatFlag),
+		},
+		{
+			Name:        "gen",
+			Description: "Generates the artifacts of the given backend or proteus-gen-NAME plugin from your Go source code. The available backends are: " + strings.Join(proteus.Backends(), ", ") + ".",
+			Usage:       "Generates the artifacts of a backend from Go packages",
+			Action:      initCmd(genBackend),
+			Flags:       append(baseFlags, folderFlag, backendFlag, apiVersionFlag, checkCompatFlag),
+		},
 	}
 	app.Action = initCmd(genAll)
 
@@ -83,23 +264,141 @@ func initCmd(next action) func(c *cli.Context) error {
 	}
 }
 
-func genProtos(c *cli.Context) error {
-	if path == "" {
-		return errors.New("destination path cannot be empty")
+func explain(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("expecting the full name of a type, field or func to explain")
 	}
 
-	if err := checkFolder(path); err != nil {
+	options, err := generationOptions()
+	if err != nil {
 		return err
 	}
 
-	return proteus.GenerateProtos(proteus.Options{
-		BasePath: path,
-		Packages: packages,
-	})
+	e, err := proteus.Explain(options, c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(e)
+	return nil
+}
+
+func printGraph(c *cli.Context) error {
+	if graphFormat != "dot" && graphFormat != "json" {
+		return fmt.Errorf("unknown graph format %q, expecting dot or json", graphFormat)
+	}
+
+	options, err := generationOptions()
+	if err != nil {
+		return err
+	}
+
+	g, err := proteus.BuildGraph(options)
+	if err != nil {
+		return err
+	}
+
+	if graphFormat == "json" {
+		return g.WriteJSON(os.Stdout)
+	}
+	return g.WriteDOT(os.Stdout)
+}
+
+// genWith returns the action generating the artifacts of the backend with
+// the given name in the destination folder.
+func genWith(name string) action {
+	return func(c *cli.Context) error {
+		if path == "" {
+			return errors.New("destination path cannot be empty")
+		}
+
+		if err := checkFolder(path); err != nil {
+			return err
+		}
+
+		return generate(name)
+	}
+}
+
+func genBackend(c *cli.Context) error {
+	if backend == "" {
+		return fmt.Errorf("no backend provided, the available backends are: %s", strings.Join(proteus.Backends(), ", "))
+	}
+
+	if path != "" {
+		if err := checkFolder(path); err != nil {
+			return err
+		}
+	}
+
+	return generate(backend)
+}
+
+// generate generates the artifacts of the backend with the given name with
+// the options given by the flags.
+func generate(name string) error {
+	options, err := generationOptions()
+	if err != nil {
+		return err
+	}
+
+	options.BasePath = path
+	options.APIVersion = apiVersion
+	options.CheckCompatibility = checkCompat
+	return proteus.Generate(name, options)
 }
 
 func genRPCServer(c *cli.Context) error {
-	return proteus.GenerateRPCServer(packages)
+	options, err := generationOptions()
+	if err != nil {
+		return err
+	}
+
+	return proteus.GenerateRPCServerWithOptions(options)
+}
+
+func generationOptions() (proteus.Options, error) {
+	options := proteus.Options{
+		Packages:         packages,
+		Plugins:          plugins,
+		AllowedTypes:     allowTypes,
+		AllowedPackages:  allowPkgs,
+		Follow:           follow,
+		FollowPrefixes:   followPrefixes,
+		FollowDepth:      followDepth,
+		ProtoPaths:       protoPaths,
+		NoStdlibTypes:    noStdlibTypes,
+		ValidateRequests: validate,
+	}
+
+	var err error
+	if timeFormat != "" {
+		if options.TimeFormat, err = protobuf.ParseTimeFormat(timeFormat); err != nil {
+			return options, err
+		}
+	}
+
+	if durationFormat != "" {
+		if options.DurationFormat, err = protobuf.ParseTimeFormat(durationFormat); err != nil {
+			return options, err
+		}
+	}
+
+	if options.Validation, err = protobuf.ParseValidation(validation); err != nil {
+		return options, err
+	}
+
+	if mappingsFile != "" {
+		m, err := protobuf.LoadMappingsFile(mappingsFile)
+		if err != nil {
+			return options, err
+		}
+
+		options.Mappings = m.Mappings
+		options.PackageMappings = m.Packages
+	}
+
+	return options, nil
 }
 
 var (
@@ -117,15 +416,28 @@ func genAll(c *cli.Context) error {
 		return fmt.Errorf("github.com/gogo/protobuf is not installed")
 	}
 
-	if err := genProtos(c); err != nil {
+	if err := genWith("proto")(c); err != nil {
+		return err
+	}
+
+	options, err := generationOptions()
+	if err != nil {
+		return err
+	}
+
+	// with --follow, proto files are also generated for the followed
+	// packages, which need their Go code too.
+	paths, err := proteus.PackagePaths(options)
+	if err != nil {
 		return err
 	}
 
-	for _, p := range packages {
+	for _, p := range paths {
 		outPath := goSrc
 		proto := filepath.Join(path, p, "generated.proto")
 
-		if err := protocExec(protocPath, p, outPath, proto); err != nil {
+		mappings := []protobuf.TypeMappings{options.Mappings, options.PackageMappings[p]}
+		if err := protocExec(protocPath, p, outPath, proto, mappings); err != nil {
 			return fmt.Errorf("error generating Go files from %q: %s", proto, err)
 		}
 
@@ -140,12 +452,31 @@ func genAll(c *cli.Context) error {
 		}
 	}
 
+	if err := genGlue(c); err != nil {
+		return err
+	}
+
 	return genRPCServer(c)
 }
 
-func protocExec(protocPath, pkg, outPath, protoFile string) error {
+func genGlue(c *cli.Context) error {
+	options, err := generationOptions()
+	if err != nil {
+		return err
+	}
+
+	return proteus.GenerateGlue(options)
+}
+
+func protocExec(protocPath, pkg, outPath, protoFile string, mappings []protobuf.TypeMappings) error {
+	var extraPaths string
+	for _, p := range protoPaths {
+		extraPaths += p + ":"
+	}
+
 	protocArgs := fmt.Sprintf(
-		"--proto_path=%s:%s:%s:%s:.",
+		"--proto_path=%s%s:%s:%s:%s:.",
+		extraPaths,
 		goSrc,
 		path,
 		filepath.Join(protobufSrc, "protobuf"),
@@ -157,7 +488,7 @@ func protocExec(protocPath, pkg, outPath, protoFile string) error {
 	cmd := exec.Command(
 		protocPath,
 		protocArgs,
-		genAllGoFastOutOption(outPath),
+		genAllGoFastOutOption(outPath, mappings),
 		protoFile,
 	)
 	cmd.Stdout = os.Stdout
@@ -174,12 +505,12 @@ func mv(from, to string) error {
 	return cmd.Run()
 }
 
-func genAllGoFastOutOption(outPath string) string {
+func genAllGoFastOutOption(outPath string, mappings []protobuf.TypeMappings) string {
 	str := "--gofast_out=plugins=grpc"
-	importMappings := protobuf.DefaultMappings.ToGoOutPath()
-
-	if importMappings != "" {
-		str += fmt.Sprintf(",%s", importMappings)
+	for _, m := range append([]protobuf.TypeMappings{protobuf.DefaultMappings}, mappings...) {
+		if importMappings := m.ToGoOutPath(); importMappings != "" {
+			str += fmt.Sprintf(",%s", importMappings)
+		}
 	}
 
 	str += fmt.Sprintf(":%s", outPath)
@@ -0,0 +1,48 @@
+package proteus
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+// generateProtos generates the proto files of the given packages. It fails
+// if the proto files would import each other, which protoc rejects.
+func generateProtos(options Options, pkgs []*scanner.Package) error {
+	var protos []*protobuf.Package
+	err := TransformToProtobuf(options, pkgs, func(_ *scanner.Package, pkg *protobuf.Package) error {
+		protos = append(protos, pkg)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	cycles := protobuf.ImportCycles(protos)
+	if len(cycles) > 0 {
+		return importCyclesError(cycles)
+	}
+
+	g := protobuf.NewGenerator(options.BasePath)
+	for _, p := range protos {
+		if err := g.Generate(p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func importCyclesError(cycles []*protobuf.ImportCycle) error {
+	var msgs []string
+	for _, c := range cycles {
+		msgs = append(msgs, c.String())
+	}
+
+	return fmt.Errorf(
+		"the proto files of the packages would import each other, which protoc rejects, move the shared types to another package:\n%s",
+		strings.Join(msgs, "\n"),
+	)
+}
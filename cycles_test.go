@@ -0,0 +1,89 @@
+package proteus
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+const cyclesPkg = fixturesPkg + "/cycles"
+
+var cyclesFixtures = map[string]string{
+	"orders/orders.go": `package orders
+
+import "gopkg.in/src-d/proteus.v1/fixtures/cycles/customers"
+
+//proteus:generate
+type Order struct {
+	ID       string
+	Customer customers.Customer
+}
+`,
+	"customers/customers.go": `package customers
+
+//proteus:generate
+type Customer struct {
+	Name        string
+	LastOrderID string
+}
+`,
+}
+
+func writeCyclesFixtures(t *testing.T) func() {
+	root := filepath.Join(os.Getenv("GOPATH"), "src", cyclesPkg)
+	for file, content := range cyclesFixtures {
+		path := filepath.Join(root, filepath.FromSlash(file))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+
+	return func() {
+		require.NoError(t, os.RemoveAll(root))
+	}
+}
+
+// lastOrderHook makes the LastOrderID field of Customer reference the
+// message of the orders package, as a mapping to its generated proto file
+// would do, so the packages import each other.
+func lastOrderHook(pkg *protobuf.Package, msg *protobuf.Message, f *protobuf.Field, src *scanner.Field) bool {
+	if src.Name == "LastOrderID" {
+		f.Name = "last_order"
+		f.Type = protobuf.NewNamed("gopkg.in.srcd.proteus.v1.fixtures.cycles.orders", "Order")
+		pkg.ImportFromPath(cyclesPkg + "/orders")
+	}
+	return true
+}
+
+func TestGenerateProtosImportCycle(t *testing.T) {
+	require := require.New(t)
+	report.TestMode()
+	defer report.EndTestMode()
+	defer writeCyclesFixtures(t)()
+
+	dir, err := ioutil.TempDir("", "proteus")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	options := Options{
+		BasePath: dir,
+		Packages: []string{cyclesPkg + "/orders", cyclesPkg + "/customers"},
+		Hooks:    protobuf.Hooks{Field: []protobuf.FieldHook{lastOrderHook}},
+	}
+
+	err = GenerateProtos(options)
+	require.Error(err)
+	require.Contains(err.Error(), `import cycle between packages `+cyclesPkg+`/customers, `+cyclesPkg+`/orders:
+  `+cyclesPkg+`/orders imports `+cyclesPkg+`/customers: field gopkg.in.srcd.proteus.v1.fixtures.cycles.orders.Order.customer uses gopkg.in.srcd.proteus.v1.fixtures.cycles.customers.Customer
+  `+cyclesPkg+`/customers imports `+cyclesPkg+`/orders: field gopkg.in.srcd.proteus.v1.fixtures.cycles.customers.Customer.last_order uses gopkg.in.srcd.proteus.v1.fixtures.cycles.orders.Order`)
+	for _, pkg := range []string{"orders", "customers"} {
+		_, err = os.Stat(filepath.Join(dir, cyclesPkg, pkg, "generated.proto"))
+		require.True(os.IsNotExist(err), "nothing is generated")
+	}
+}
@@ -0,0 +1,739 @@
+package proteus
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/resolver"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+// Explanation explains why a type, struct field or func is generated or
+// excluded, with the decisions that led to it.
+type Explanation struct {
+	// Name is the full name of the explained type, field or func.
+	Name string
+	// Result is the outcome, such as "generated" or "excluded".
+	Result string
+	// Steps are the decisions that led to the result, in order.
+	Steps []ExplanationStep
+}
+
+// ExplanationStep is a decision of an Explanation.
+type ExplanationStep struct {
+	// Pos is the source position of the entity the decision is about, if
+	// it is known.
+	Pos  string
+	Text string
+}
+
+// String returns the explanation with one decision per line.
+func (e *Explanation) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s: %s\n", e.Name, e.Result)
+	for _, s := range e.Steps {
+		if s.Pos != "" {
+			fmt.Fprintf(&buf, "  %s: %s\n", s.Pos, s.Text)
+		} else {
+			fmt.Fprintf(&buf, "  %s\n", s.Text)
+		}
+	}
+	return buf.String()
+}
+
+// Explain explains why the type, struct field or func with the given name is
+// generated or excluded when generating the packages in the given options.
+// The name is the full name of a type, such as "github.com/org/pkg.Type", or
+// of one of its fields or methods, such as "github.com/org/pkg.Type.Field",
+// or of a func, such as "github.com/org/pkg.Func".
+func Explain(options Options, name string) (*Explanation, error) {
+	path, typeName, member, err := splitExplainName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgs, r, err := loadPackages(options)
+	if err != nil {
+		return nil, err
+	}
+
+	directives, err := protoMappings(pkgs)
+	if err != nil {
+		return nil, err
+	}
+
+	x := &explainer{
+		options:    options,
+		r:          r,
+		pkgs:       pkgs,
+		directives: directives,
+		aliases:    make(map[string]scanner.Type),
+		sources:    make(map[string]*sourceInfo),
+		e:          &Explanation{Name: name},
+	}
+
+	var pkg *scanner.Package
+	for _, p := range pkgs {
+		for n, t := range p.Aliases {
+			x.aliases[n] = t
+		}
+
+		if p.Path == path {
+			pkg = p
+		}
+	}
+
+	if pkg == nil {
+		x.explainNotScanned(path, typeName, member)
+		return x.e, nil
+	}
+
+	if err := x.explain(pkg, typeName, member); err != nil {
+		return nil, err
+	}
+	return x.e, nil
+}
+
+// splitExplainName splits the name of the entity to explain into the path
+// of its package, the name of its type or func and the name of its field or
+// method, if any.
+func splitExplainName(name string) (path, typeName, member string, err error) {
+	idx := strings.LastIndex(name, "/")
+	parts := strings.Split(name[idx+1:], ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return "", "", "", fmt.Errorf("invalid name %q, expecting PACKAGE.Type or PACKAGE.Type.Field", name)
+	}
+
+	for _, p := range parts {
+		if p == "" {
+			return "", "", "", fmt.Errorf("invalid name %q, expecting PACKAGE.Type or PACKAGE.Type.Field", name)
+		}
+	}
+
+	path = name[:idx+1] + parts[0]
+	typeName = parts[1]
+	if len(parts) == 3 {
+		member = parts[2]
+	}
+	return path, typeName, member, nil
+}
+
+type explainer struct {
+	options Options
+	r       *resolver.Resolver
+	pkgs    []*scanner.Package
+	// directives are the mappings of the types with the proto directive.
+	directives protobuf.TypeMappings
+	// aliases are the type declarations of all the packages.
+	aliases map[string]scanner.Type
+	sources map[string]*sourceInfo
+	e       *Explanation
+}
+
+func (x *explainer) step(pos, format string, args ...interface{}) {
+	x.e.Steps = append(x.e.Steps, ExplanationStep{Pos: pos, Text: fmt.Sprintf(format, args...)})
+}
+
+// pos returns the source position of the declaration with the given name in
+// the package with the given path.
+func (x *explainer) pos(path, name string) string {
+	return x.source(path).pos(name)
+}
+
+func (x *explainer) source(path string) *sourceInfo {
+	if s, ok := x.sources[path]; ok {
+		return s
+	}
+
+	s := parseSource(path)
+	x.sources[path] = s
+	return s
+}
+
+func (x *explainer) explainNotScanned(path, typeName, member string) {
+	name := fmt.Sprintf("%s.%s", path, typeName)
+	pos := x.pos(path, typeName)
+	if x.options.Follow {
+		x.step("", "package %s is not scanned nor followed", path)
+	} else {
+		x.step("", "package %s is not scanned", path)
+	}
+
+	if member != "" {
+		x.e.Result = "excluded"
+		x.step(x.pos(path, typeName+"."+member), "only the types of the scanned packages are generated")
+		return
+	}
+
+	mapping, from := x.findMapping("", name)
+	if mapping != nil {
+		x.step(pos, "type %s is mapped to %s by %s", typeName, describeProtoType(mapping), from)
+	}
+
+	n := scanner.NewNamed(path, typeName).(*scanner.Named)
+	switch {
+	case !x.r.IsCustomType(n):
+		x.e.Result = "excluded"
+		x.step(pos, "fields using type %s are removed because it is not a custom type: scan or follow its package, or allow it with a mapping", typeName)
+	case mapping == nil:
+		x.e.Result = "excluded"
+		x.step(pos, "type %s is allowed but it has no mapping, so the generation fails if a generated type uses it", typeName)
+	default:
+		x.e.Result = "mapped"
+		x.step(pos, "fields using type %s are kept because it is a custom type", typeName)
+	}
+}
+
+func (x *explainer) explain(pkg *scanner.Package, typeName, member string) error {
+	if contains(x.options.Packages, pkg.Path) {
+		x.step("", "package %s is scanned", pkg.Path)
+	} else {
+		x.step("", "package %s is not scanned, but it is followed because the scanned packages use its types", pkg.Path)
+	}
+
+	// the resolver and the transformer modify the packages, so the scanned
+	// entities are kept before they do.
+	var (
+		s      *scanner.Struct
+		fields []*scanner.Field
+		types  = make(map[*scanner.Field]scanner.Type)
+		fn     *scanner.Func
+		enum   *scanner.Enum
+	)
+	for _, st := range pkg.Structs {
+		if st.Name == typeName {
+			s = st
+			fields = append(fields, st.Fields...)
+			for _, f := range st.Fields {
+				types[f] = f.Type
+			}
+		}
+	}
+
+	for _, f := range pkg.Funcs {
+		if resolver.FuncName(pkg, f) == joinName(pkg.Path, typeName, member) {
+			fn = f
+		}
+	}
+
+	for _, e := range pkg.Enums {
+		if e.Name == typeName {
+			enum = e
+		}
+	}
+
+	x.r.Resolve(x.pkgs)
+	protos, err := transformPackages(x.options, x.pkgs)
+	if err != nil {
+		return err
+	}
+
+	var proto *protobuf.Package
+	for i, p := range x.pkgs {
+		if p == pkg {
+			proto = protos[i]
+		}
+	}
+
+	_, isProto := pkg.Protos[typeName]
+	funcName := strings.TrimPrefix(joinName(pkg.Path, typeName, member), pkg.Path+".")
+	switch {
+	case fn != nil:
+		x.explainFunc(pkg, proto, fn)
+	case !hasField(fields, member) && x.source(pkg.Path).funcs[funcName] != nil:
+		x.explainMissingFunc(pkg, funcName)
+	case s != nil:
+		if !x.explainStruct(pkg, proto, s) || member == "" {
+			return nil
+		}
+		return x.explainField(pkg, proto, s, fields, types, member)
+	case member != "" && !isProto:
+		return fmt.Errorf("%s is not a struct of package %s", typeName, pkg.Path)
+	case enum != nil:
+		x.explainEnum(pkg, proto, enum)
+	default:
+		return x.explainOtherType(pkg, typeName)
+	}
+
+	return nil
+}
+
+// explainStruct explains the given struct and reports whether it is
+// generated.
+func (x *explainer) explainStruct(pkg *scanner.Package, proto *protobuf.Package, s *scanner.Struct) bool {
+	name := fmt.Sprintf("%s.%s", pkg.Path, s.Name)
+	x.explainMarked(pkg.Path, s.Name, s.Generate)
+
+	if reason, ok := x.r.Trace().Removed[name]; ok {
+		x.e.Result = "excluded"
+		x.step(x.pos(pkg.Path, s.Name), "struct %s is excluded: %s", s.Name, reason)
+		return false
+	}
+
+	for _, msg := range proto.Messages {
+		if msg.Name == s.Name {
+			x.e.Result = "generated"
+			x.step(x.pos(pkg.Path, s.Name), "struct %s is generated as message %s.%s", s.Name, proto.Name, msg.Name)
+			return true
+		}
+	}
+
+	x.e.Result = "excluded"
+	x.step(x.pos(pkg.Path, s.Name), "struct %s is excluded by a transformer hook", s.Name)
+	return false
+}
+
+// explainMarked explains why the struct with the given name is marked for
+// generation, following the fields and funcs using it up to a struct or
+// func marked with //proteus:generate.
+func (x *explainer) explainMarked(path, name string, generate bool) {
+	visited := make(map[string]bool)
+	for {
+		full := fmt.Sprintf("%s.%s", path, name)
+		pos := x.pos(path, name)
+		if generate {
+			if contains(x.options.Packages, path) {
+				x.step(pos, "struct %s is marked with //proteus:generate", name)
+			} else {
+				x.step(pos, "struct %s is used by a generated type of another package, so it is followed", name)
+			}
+			return
+		}
+
+		from, ok := x.r.Trace().MarkedBy[full]
+		if !ok || visited[full] {
+			x.step(pos, "struct %s is not marked with //proteus:generate", name)
+			return
+		}
+		visited[full] = true
+
+		fromPath, fromType, fromMember, _ := splitExplainName(from)
+		if fromMember == "" {
+			x.step(x.pos(fromPath, fromType), "struct %s is used by func %s, which is marked with //proteus:generate", name, from)
+			return
+		}
+
+		if x.isFunc(fromPath, from) {
+			x.step(x.pos(fromPath, fromType+"."+fromMember), "struct %s is used by method %s, which is marked with //proteus:generate", name, from)
+			return
+		}
+
+		x.step(x.pos(fromPath, fromType+"."+fromMember), "struct %s is used by field %s, so it is generated", name, from)
+		path, name, generate = fromPath, fromType, x.isGenerated(fromPath, fromType)
+	}
+}
+
+func (x *explainer) isFunc(path, name string) bool {
+	for _, p := range x.pkgs {
+		if p.Path != path {
+			continue
+		}
+
+		for _, f := range p.Funcs {
+			if resolver.FuncName(p, f) == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (x *explainer) isGenerated(path, name string) bool {
+	for _, p := range x.pkgs {
+		if p.Path != path {
+			continue
+		}
+
+		for _, s := range p.Structs {
+			if s.Name == name {
+				return s.Generate
+			}
+		}
+	}
+	return false
+}
+
+func (x *explainer) explainField(pkg *scanner.Package, proto *protobuf.Package, s *scanner.Struct, fields []*scanner.Field, types map[*scanner.Field]scanner.Type, name string) error {
+	full := joinName(pkg.Path, s.Name, name)
+	pos := x.pos(pkg.Path, s.Name+"."+name)
+
+	var field *scanner.Field
+	for _, f := range fields {
+		if f.Name == name {
+			field = f
+		}
+	}
+
+	if field == nil {
+		x.e.Result = "excluded"
+		reason, ok := x.source(pkg.Path).ignoredReason(s.Name, name)
+		if !ok {
+			return fmt.Errorf("%s has no field or method %s", s.Name, name)
+		}
+		x.step(pos, "field %s is ignored by the scanner: %s", name, reason)
+		return nil
+	}
+
+	typ := types[field]
+	x.step(pos, "field %s has type %s", name, typ)
+	x.explainAliases(typ)
+
+	if reason, ok := x.r.Trace().Removed[full]; ok {
+		x.e.Result = "excluded"
+		x.step(pos, "field %s is excluded: %s", name, reason)
+		return nil
+	}
+
+	for _, name := range typeNames(field.Type) {
+		x.explainMapping(pkg.Path, name)
+	}
+
+	fieldPos := -1
+	for i, f := range s.Fields {
+		if f == field {
+			fieldPos = i + 1
+		}
+	}
+
+	for _, msg := range proto.Messages {
+		if msg.Name != s.Name {
+			continue
+		}
+
+		for _, f := range msg.Fields {
+			if f.Pos == fieldPos {
+				x.e.Result = "generated"
+				x.step(pos, "field %s is generated as field %s = %d of type %s", name, f.Name, f.Pos, f.Type)
+				return nil
+			}
+		}
+	}
+
+	x.e.Result = "excluded"
+	x.step(pos, "field %s is excluded by the transformer, because its type cannot be transformed or a transformer hook dropped it, and its position is reserved", name)
+	return nil
+}
+
+// explainAliases explains the type declarations replaced by their types in
+// the given type.
+func (x *explainer) explainAliases(typ scanner.Type) {
+	visited := make(map[string]bool)
+	for _, n := range namedTypes(typ) {
+		for {
+			alias, ok := x.aliases[n.String()]
+			if !ok || visited[n.String()] {
+				break
+			}
+			visited[n.String()] = true
+
+			x.step(x.pos(n.Path, n.Name), "type %s is declared as %s, which is used instead", n, alias)
+			next, ok := alias.(*scanner.Named)
+			if !ok {
+				break
+			}
+			n = next
+		}
+	}
+}
+
+// explainMapping explains the mapping of the Go type with the given name
+// used in the package with the given path, if any.
+func (x *explainer) explainMapping(path, name string) {
+	if mapping, from := x.findMapping(path, name); mapping != nil {
+		pkg, typ, _, _ := splitExplainName(name)
+		x.step(x.pos(pkg, typ), "type %s is mapped to %s by %s", name, describeProtoType(mapping), from)
+	}
+}
+
+func (x *explainer) explainFunc(pkg *scanner.Package, proto *protobuf.Package, f *scanner.Func) {
+	name := resolver.FuncName(pkg, f)
+	pos := x.pos(pkg.Path, strings.TrimPrefix(name, pkg.Path+"."))
+	x.step(pos, "func %s is marked with //proteus:generate", f.Name)
+
+	if reason, ok := x.r.Trace().Removed[name]; ok {
+		x.e.Result = "excluded"
+		x.step(pos, "func %s is excluded: %s", f.Name, reason)
+		return
+	}
+
+	for _, rpc := range proto.RPCs {
+		if rpc.Src == f {
+			x.e.Result = "generated"
+			x.step(pos, "func %s is generated as RPC %s", f.Name, rpc.Name)
+			return
+		}
+	}
+
+	x.e.Result = "excluded"
+	x.step(pos, "func %s is excluded by the transformer, because its types cannot be transformed or a transformer hook dropped it", f.Name)
+}
+
+func (x *explainer) explainMissingFunc(pkg *scanner.Package, name string) {
+	x.e.Result = "excluded"
+	x.step(x.pos(pkg.Path, name), "func %s is not marked with //proteus:generate", name)
+}
+
+func (x *explainer) explainEnum(pkg *scanner.Package, proto *protobuf.Package, e *scanner.Enum) {
+	pos := x.pos(pkg.Path, e.Name)
+	x.step(pos, "enum %s is marked with //proteus:generate", e.Name)
+	for _, enum := range proto.Enums {
+		if enum.Src == e {
+			x.e.Result = "generated"
+			x.step(pos, "enum %s is generated as enum %s.%s", e.Name, proto.Name, enum.Name)
+			return
+		}
+	}
+
+	x.e.Result = "excluded"
+	x.step(pos, "enum %s is excluded by a transformer hook", e.Name)
+}
+
+func (x *explainer) explainOtherType(pkg *scanner.Package, name string) error {
+	full := fmt.Sprintf("%s.%s", pkg.Path, name)
+	pos := x.pos(pkg.Path, name)
+
+	if args, ok := pkg.Protos[name]; ok {
+		x.e.Result = "mapped"
+		x.step(pos, "type %s is an existing protobuf message with //proteus:proto %s, so it is not generated", name, args)
+		x.explainMapping(pkg.Path, full)
+		return nil
+	}
+
+	if _, ok := x.aliases[full]; ok {
+		x.e.Result = "not generated"
+		if x.source(pkg.Path).hasConsts(name) {
+			x.step(pos, "type %s has constants but it is not an enum because it is not marked with //proteus:generate", name)
+		}
+		x.explainAliases(scanner.NewNamed(pkg.Path, name))
+		x.explainMapping(pkg.Path, full)
+		return nil
+	}
+
+	if _, ok := x.source(pkg.Path).types[name]; ok {
+		x.e.Result = "excluded"
+		x.step(pos, "type %s is not exported", name)
+		return nil
+	}
+
+	return fmt.Errorf("type %s is not declared in package %s", name, pkg.Path)
+}
+
+// findMapping returns the mapping of the Go type with the given name used in
+// the package with the given path, as the transformer does, along with where
+// the mapping comes from.
+func (x *explainer) findMapping(path, name string) (*protobuf.ProtoType, string) {
+	if typ := x.options.PackageMappings[path].Find(name); typ != nil {
+		return typ, fmt.Sprintf("the custom mappings of package %s", path)
+	}
+
+	if typ := x.options.Mappings.Find(name); typ != nil {
+		return typ, "the custom mappings"
+	}
+
+	if typ := x.directives.Find(name); typ != nil {
+		return typ, "its //proteus:proto directive"
+	}
+
+	if typ := protobuf.DefaultMappings[name]; typ != nil {
+		return typ, "the built-in mappings"
+	}
+
+	if typ := protobuf.StdlibMappings[name]; typ != nil && !x.options.NoStdlibTypes {
+		return typ, "the built-in mappings of the standard library"
+	}
+
+	return nil, ""
+}
+
+// typeNames returns the names of the named and basic types in the given
+// type, which can have a mapping.
+func typeNames(typ scanner.Type) []string {
+	switch t := typ.(type) {
+	case *scanner.Named:
+		return []string{t.String()}
+	case *scanner.Basic:
+		return []string{t.Name}
+	case *scanner.Map:
+		return append(typeNames(t.Key), typeNames(t.Value)...)
+	case *scanner.Alias:
+		return append(typeNames(t.Type), typeNames(t.Underlying)...)
+	}
+	return nil
+}
+
+func describeProtoType(t *protobuf.ProtoType) string {
+	name := t.Name
+	if t.Package != "" {
+		name = t.Package + "." + t.Name
+	}
+
+	if t.Import != "" {
+		return fmt.Sprintf("%s of %s", name, t.Import)
+	}
+	return name
+}
+
+func hasField(fields []*scanner.Field, name string) bool {
+	for _, f := range fields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func joinName(path, typeName, member string) string {
+	name := fmt.Sprintf("%s.%s", path, typeName)
+	if member != "" {
+		name += "." + member
+	}
+	return name
+}
+
+// sourceInfo contains the declarations of the types, struct fields and funcs
+// of a package, by name, which is "Type.Field" for fields and "Type.Method"
+// for methods, to find their source positions.
+type sourceInfo struct {
+	fset   *token.FileSet
+	types  map[string]*ast.TypeSpec
+	fields map[string]*ast.Field
+	funcs  map[string]*ast.FuncDecl
+	consts map[string]bool
+}
+
+// parseSource parses the package with the given path. If it cannot be
+// parsed, no position is known.
+func parseSource(path string) *sourceInfo {
+	s := &sourceInfo{
+		fset:   token.NewFileSet(),
+		types:  make(map[string]*ast.TypeSpec),
+		fields: make(map[string]*ast.Field),
+		funcs:  make(map[string]*ast.FuncDecl),
+		consts: make(map[string]bool),
+	}
+
+	bp, err := build.Import(path, "", build.FindOnly)
+	if err != nil {
+		return s
+	}
+
+	pkgs, err := parser.ParseDir(s.fset, bp.Dir, func(fi os.FileInfo) bool {
+		return strings.HasSuffix(fi.Name(), ".go") && !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return s
+	}
+
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			for _, decl := range f.Decls {
+				s.addDecl(decl)
+			}
+		}
+	}
+
+	return s
+}
+
+func (s *sourceInfo) addDecl(decl ast.Decl) {
+	switch d := decl.(type) {
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch spec := spec.(type) {
+			case *ast.TypeSpec:
+				s.types[spec.Name.Name] = spec
+				if st, ok := spec.Type.(*ast.StructType); ok {
+					for _, f := range st.Fields.List {
+						for _, n := range f.Names {
+							s.fields[spec.Name.Name+"."+n.Name] = f
+						}
+					}
+				}
+			case *ast.ValueSpec:
+				if ident, ok := spec.Type.(*ast.Ident); ok && d.Tok == token.CONST {
+					s.consts[ident.Name] = true
+				}
+			}
+		}
+	case *ast.FuncDecl:
+		name := d.Name.Name
+		if d.Recv != nil && len(d.Recv.List) > 0 {
+			typ := d.Recv.List[0].Type
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ = star.X
+			}
+			if ident, ok := typ.(*ast.Ident); ok {
+				name = ident.Name + "." + name
+			}
+		}
+		s.funcs[name] = d
+	}
+}
+
+func (s *sourceInfo) pos(name string) string {
+	var pos token.Pos
+	if t, ok := s.types[name]; ok {
+		pos = t.Pos()
+	} else if f, ok := s.fields[name]; ok {
+		pos = f.Pos()
+	} else if f, ok := s.funcs[name]; ok {
+		pos = f.Name.Pos()
+	} else {
+		return ""
+	}
+
+	p := s.fset.Position(pos)
+	for _, gopath := range filepath.SplitList(os.Getenv("GOPATH")) {
+		if rel, err := filepath.Rel(filepath.Join(gopath, "src"), p.Filename); err == nil && !strings.HasPrefix(rel, "..") {
+			p.Filename = filepath.ToSlash(rel)
+			break
+		}
+	}
+	return p.String()
+}
+
+func (s *sourceInfo) hasConsts(typeName string) bool {
+	return s.consts[typeName]
+}
+
+// ignoredReason returns why the scanner ignores the field of the struct
+// with the given names, if the field is declared in the struct.
+func (s *sourceInfo) ignoredReason(typeName, name string) (string, bool) {
+	f, ok := s.fields[typeName+"."+name]
+	if !ok {
+		return "", false
+	}
+
+	if !ast.IsExported(name) {
+		return "it is not exported", true
+	}
+
+	if f.Tag != nil {
+		if tag, err := strconv.Unquote(f.Tag.Value); err == nil {
+			if strings.Split(reflect.StructTag(tag).Get("proteus"), ",")[0] == "-" {
+				return `it has the proteus:"-" struct tag`, true
+			}
+		}
+	}
+
+	return "its type is not supported or it is declared twice", true
+}
@@ -0,0 +1,118 @@
+package proteus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/report"
+)
+
+func explain(t *testing.T, options Options, name string) *Explanation {
+	report.TestMode()
+	defer report.EndTestMode()
+
+	e, err := Explain(options, name)
+	require.NoError(t, err)
+	return e
+}
+
+func requireSteps(t *testing.T, e *Explanation, steps ...string) {
+	var texts []string
+	for _, s := range e.Steps {
+		text := s.Text
+		if s.Pos != "" {
+			text = s.Pos + ": " + text
+		}
+		texts = append(texts, text)
+	}
+	require.Equal(t, steps, texts, e.String())
+}
+
+func TestExplainMarkedStruct(t *testing.T) {
+	e := explain(t, Options{Packages: []string{fixturesPkg}}, fixturesPkg+".Jur")
+	require.Equal(t, "generated", e.Result)
+	requireSteps(t, e,
+		"package "+fixturesPkg+" is scanned",
+		fixturesPkg+"/foo.go:15:2: struct Jur is used by field "+fixturesPkg+".Foo.AliasedMap, so it is generated",
+		fixturesPkg+"/foo.go:10:6: struct Foo is marked with //proteus:generate",
+		fixturesPkg+"/foo.go:30:6: struct Jur is generated as message gopkg.in.srcd.proteus.v1.fixtures.Jur",
+	)
+}
+
+func TestExplainExcludedStruct(t *testing.T) {
+	e := explain(t, Options{Packages: []string{fixturesPkg, subpkg}}, subpkg+".NotGenerated")
+	require.Equal(t, "excluded", e.Result)
+	require.Contains(t, e.String(), subpkg+"/foo.go:16:6: struct NotGenerated is excluded: it is not marked with //proteus:generate and no struct or func of the scanned packages uses it")
+}
+
+func TestExplainField(t *testing.T) {
+	options := Options{Packages: []string{fixturesPkg}}
+
+	e := explain(t, options, fixturesPkg+".Saz.Point")
+	require.Equal(t, "excluded", e.Result)
+	requireSteps(t, e,
+		"package "+fixturesPkg+" is scanned",
+		fixturesPkg+"/bar.go:18:6: struct Saz is marked with //proteus:generate",
+		fixturesPkg+"/bar.go:18:6: struct Saz is generated as message gopkg.in.srcd.proteus.v1.fixtures.Saz",
+		fixturesPkg+"/bar.go:19:2: field Point has type "+subpkg+".Point",
+		fixturesPkg+"/bar.go:19:2: field Point is excluded: type "+subpkg+".Point is not in the scanned packages and it is not a custom type",
+	)
+
+	e = explain(t, options, fixturesPkg+".Foo.AliasedMap")
+	require.Equal(t, "generated", e.Result)
+	require.Contains(t, e.String(), fixturesPkg+"/foo.go:27:6: type "+fixturesPkg+".MyMap is declared as map[string]"+fixturesPkg+".Jur, which is used instead")
+	require.Contains(t, e.String(), "type string is mapped to string by the built-in mappings")
+	require.Contains(t, e.String(), "field AliasedMap is generated as field aliased_map = 6 of type map<string, gopkg.in.srcd.proteus.v1.fixtures.Jur>")
+
+	e = explain(t, options, fixturesPkg+".Foo.Timestamp")
+	require.Equal(t, "generated", e.Result)
+	require.Contains(t, e.String(), "type time.Time is mapped to google.protobuf.Timestamp of google/protobuf/timestamp.proto by the built-in mappings")
+}
+
+func TestExplainFunc(t *testing.T) {
+	options := Options{Packages: []string{fixturesPkg, subpkg}}
+
+	e := explain(t, options, subpkg+".Point.GeneratedMethod")
+	require.Equal(t, "generated", e.Result)
+	require.Contains(t, e.String(), "func GeneratedMethod is generated as RPC Point_GeneratedMethod")
+
+	e = explain(t, options, subpkg+".Foo")
+	require.Equal(t, "excluded", e.Result)
+	require.Contains(t, e.String(), subpkg+"/foo.go:19:6: func Foo is not marked with //proteus:generate")
+}
+
+func TestExplainNotScanned(t *testing.T) {
+	options := Options{Packages: []string{fixturesPkg}}
+
+	e := explain(t, options, "net.IP")
+	require.Equal(t, "mapped", e.Result)
+	require.Contains(t, e.String(), "type IP is mapped to bytes by the built-in mappings of the standard library")
+
+	e = explain(t, options, "net/url.URL")
+	require.Equal(t, "excluded", e.Result)
+	require.Contains(t, e.String(), "fields using type URL are removed because it is not a custom type")
+
+	e = explain(t, options, "github.com/google/uuid.UUID")
+	require.Equal(t, "excluded", e.Result)
+	require.Contains(t, e.String(), "fields using type UUID are removed because it is not a custom type")
+
+	options.AllowedTypes = []string{"*.UUID"}
+	e = explain(t, options, "github.com/google/uuid.UUID")
+	require.Equal(t, "excluded", e.Result)
+	require.Contains(t, e.String(), "type UUID is allowed but it has no mapping")
+}
+
+func TestExplainInvalidName(t *testing.T) {
+	for _, name := range []string{"Foo", fixturesPkg, fixturesPkg + ".A.B.C", fixturesPkg + ".Foo."} {
+		_, err := Explain(Options{Packages: []string{fixturesPkg}}, name)
+		require.Error(t, err, name)
+	}
+
+	report.TestMode()
+	defer report.EndTestMode()
+
+	_, err := Explain(Options{Packages: []string{fixturesPkg}}, fixturesPkg+".Nope")
+	require.Error(t, err)
+	require.Equal(t, "type Nope is not declared in package "+fixturesPkg, err.Error())
+}
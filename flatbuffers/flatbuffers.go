@@ -0,0 +1,323 @@
+package flatbuffers // import "gopkg.in/src-d/proteus.v1/flatbuffers"
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+// Generator is in charge of generating the FlatBuffers schema of a protobuf
+// package and write it to disk at the given path, in a file named after the
+// last element of the package path, e.g. "foo.fbs" for "example.com/foo".
+//
+// Messages are tables in the namespace of the proto package, with their
+// fields in the same order, enums have the integer type of the Go type they
+// are declared as, e.g. ubyte for "type Kind byte", repeated fields are
+// vectors and the service of the package is a rpc_service with a method for
+// every RPC.
+//
+// FlatBuffers has no maps, so they are lowered to vectors of key/value
+// tables, named after the message and the field with an "Entry" suffix,
+// e.g. a "labels" field of type map<string, int32> of a message "User" is
+// a [UserLabelsEntry] whose entries have a "key" field, with the key
+// attribute, and a "value" field. The entries must be sorted by key, as
+// the builders do with CreateVectorOfSortedTables, so they can be looked
+// up with LookupByKey.
+//
+// Timestamps and durations are the number of nanoseconds in a long.
+type Generator struct {
+	basePath string
+}
+
+// NewGenerator creates a new Generator with the given base path.
+func NewGenerator(basePath string) *Generator {
+	return &Generator{basePath}
+}
+
+// Generate generates the FlatBuffers schema of the given package and writes
+// it to disk.
+func (g *Generator) Generate(pkg *protobuf.Package) error {
+	w := newWriter(pkg)
+
+	var body bytes.Buffer
+	w.writeDefinitions(&body)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by proteus. DO NOT EDIT.\n\n")
+	w.writeHeaders(&buf)
+	buf.Write(bytes.TrimRight(body.Bytes(), "\n"))
+	buf.WriteRune('\n')
+
+	return g.writeFile(pkg.Path, buf.Bytes())
+}
+
+func (g *Generator) writeFile(pkgPath string, data []byte) error {
+	dir := filepath.Join(g.basePath, pkgPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	file := filepath.Join(dir, fileName(pkgPath))
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		return err
+	}
+
+	report.Info("Generated FlatBuffers schema: %s", file)
+	return nil
+}
+
+// fileName returns the name of the FlatBuffers schema of a package.
+func fileName(pkgPath string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			return r
+		}
+		return '_'
+	}, path.Base(pkgPath)) + ".fbs"
+}
+
+// bytesTable is the table wrapping the byte vectors in vectors, as
+// FlatBuffers does not support nested vectors.
+const bytesTable = "Bytes"
+
+// writer writes the definitions of a package and keeps track of the
+// schemas it needs to include.
+type writer struct {
+	pkg      *protobuf.Package
+	includes map[string]bool
+	// entries are the key/value tables of the maps of the table being
+	// written.
+	entries []string
+	// bytesTable is whether the table wrapping byte vectors is used.
+	bytesTable bool
+}
+
+func newWriter(pkg *protobuf.Package) *writer {
+	return &writer{pkg: pkg, includes: make(map[string]bool)}
+}
+
+func (w *writer) writeHeaders(buf *bytes.Buffer) {
+	var includes []string
+	for include := range w.includes {
+		includes = append(includes, include)
+	}
+	sort.Strings(includes)
+
+	for _, include := range includes {
+		buf.WriteString(fmt.Sprintf("include %q;\n", include))
+	}
+	if len(includes) > 0 {
+		buf.WriteRune('\n')
+	}
+
+	buf.WriteString(fmt.Sprintf("namespace %s;\n\n", w.pkg.Name))
+}
+
+func (w *writer) writeDefinitions(buf *bytes.Buffer) {
+	for _, enum := range w.pkg.Enums {
+		writeEnum(buf, enum)
+		buf.WriteRune('\n')
+	}
+
+	for _, msg := range w.pkg.Messages {
+		w.writeTable(buf, msg)
+		buf.WriteRune('\n')
+
+		for _, entry := range w.entries {
+			buf.WriteString(entry)
+			buf.WriteRune('\n')
+		}
+		w.entries = nil
+	}
+
+	if w.bytesTable {
+		protobuf.WriteLineComment(buf, []string{bytesTable + " is a vector of bytes in a vector."}, "", "///")
+		buf.WriteString(fmt.Sprintf("table %s {\n  data:[ubyte];\n}\n\n", bytesTable))
+	}
+
+	if len(w.pkg.RPCs) > 0 {
+		w.writeService(buf)
+	}
+}
+
+func writeEnum(buf *bytes.Buffer, enum *protobuf.Enum) {
+	protobuf.WriteLineComment(buf, enum.Docs, "", "///")
+	buf.WriteString(fmt.Sprintf("enum %s : %s {\n", enum.Name, enumType(enum)))
+	for _, v := range enum.Values {
+		protobuf.WriteLineComment(buf, v.Docs, "  ", "///")
+		buf.WriteString(fmt.Sprintf("  %s = %d,\n", v.Name, v.Value))
+	}
+	buf.WriteString("}\n")
+}
+
+// enumTypes are the FlatBuffers integer types of the Go basic types. int and
+// uint are 64-bit, as in protobuf.DefaultMappings.
+var enumTypes = map[string]string{
+	"int8":   "byte",
+	"uint8":  "ubyte",
+	"byte":   "ubyte",
+	"int16":  "short",
+	"uint16": "ushort",
+	"int32":  "int",
+	"rune":   "int",
+	"uint32": "uint",
+	"int64":  "long",
+	"uint64": "ulong",
+	"int":    "long",
+	"uint":   "ulong",
+}
+
+// enumType returns the integer type of an enum, which is the type of the Go
+// type the enum is declared as, or int if it is not an integer type.
+func enumType(enum *protobuf.Enum) string {
+	if enum.Src != nil {
+		if typ, ok := enumTypes[enum.Src.Underlying]; ok {
+			return typ
+		}
+	}
+	return "int"
+}
+
+func (w *writer) writeTable(buf *bytes.Buffer, msg *protobuf.Message) {
+	protobuf.WriteLineComment(buf, msg.Docs, "", "///")
+	buf.WriteString(fmt.Sprintf("table %s {\n", msg.Name))
+	for _, f := range msg.Fields {
+		protobuf.WriteLineComment(buf, f.Docs, "  ", "///")
+		buf.WriteString(fmt.Sprintf("  %s:%s;\n", f.Name, w.fieldType(msg, f)))
+	}
+	buf.WriteString("}\n")
+}
+
+func (w *writer) fieldType(msg *protobuf.Message, f *protobuf.Field) string {
+	if m, ok := f.Type.(*protobuf.Map); ok {
+		return fmt.Sprintf("[%s]", w.entry(msg, f, m))
+	}
+
+	typ := w.typeName(f.Type)
+	if !f.Repeated {
+		return typ
+	}
+
+	if strings.HasPrefix(typ, "[") {
+		w.bytesTable = true
+		typ = bytesTable
+	}
+
+	return fmt.Sprintf("[%s]", strings.TrimSuffix(typ, " = null"))
+}
+
+// entry adds the key/value table of a map field and returns its name.
+func (w *writer) entry(msg *protobuf.Message, f *protobuf.Field, m *protobuf.Map) string {
+	name := msg.Name + camelCase(f.Name) + "Entry"
+
+	var buf bytes.Buffer
+	protobuf.WriteLineComment(&buf, []string{fmt.Sprintf("%s is an entry of %s.%s, sorted by key.", name, msg.Name, f.Name)}, "", "///")
+	buf.WriteString(fmt.Sprintf("table %s {\n", name))
+	buf.WriteString(fmt.Sprintf("  key:%s (key);\n", w.typeName(m.Key)))
+	buf.WriteString(fmt.Sprintf("  value:%s;\n", w.typeName(m.Value)))
+	buf.WriteString("}\n")
+
+	w.entries = append(w.entries, buf.String())
+	return name
+}
+
+func camelCase(name string) string {
+	var buf bytes.Buffer
+	for _, part := range strings.Split(name, "_") {
+		if part != "" {
+			buf.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return buf.String()
+}
+
+func (w *writer) writeService(buf *bytes.Buffer) {
+	buf.WriteString(fmt.Sprintf("rpc_service %s {\n", w.pkg.ServiceName()))
+	for _, rpc := range w.pkg.RPCs {
+		protobuf.WriteLineComment(buf, rpc.Docs, "  ", "///")
+		buf.WriteString(fmt.Sprintf("  %s(%s):%s;\n", rpc.Name, w.typeName(rpc.Input), w.typeName(rpc.Output)))
+	}
+	buf.WriteString("}\n")
+}
+
+func (w *writer) typeName(typ protobuf.Type) string {
+	switch t := typ.(type) {
+	case *protobuf.Basic:
+		return basicType(t.Name)
+	case *protobuf.Alias:
+		return w.typeName(t.Underlying)
+	case *protobuf.Named:
+		return w.namedType(t)
+	}
+
+	return "[ubyte]"
+}
+
+func (w *writer) namedType(t *protobuf.Named) string {
+	if t.Package == w.pkg.Name {
+		return t.Name
+	}
+
+	if t.Package == "google.protobuf" {
+		return wellKnownType(t.Name)
+	}
+
+	if src, ok := t.Source().(*scanner.Named); ok && src.Path != "" {
+		rel, err := filepath.Rel(w.pkg.Path, src.Path)
+		if err == nil {
+			w.includes[filepath.ToSlash(filepath.Join(rel, fileName(src.Path)))] = true
+			return fmt.Sprintf("%s.%s", t.Package, t.Name)
+		}
+	}
+
+	report.Warn("type %s cannot be included in the FlatBuffers schema of %s, using [ubyte]", t, w.pkg.Name)
+	return "[ubyte]"
+}
+
+// basicType returns the FlatBuffers type of a scalar type.
+func basicType(name string) string {
+	switch name {
+	case "bool", "float", "double", "string":
+		return name
+	case "int32", "sint32", "sfixed32":
+		return "int"
+	case "uint32", "fixed32":
+		return "uint"
+	case "int64", "sint64", "sfixed64":
+		return "long"
+	case "uint64", "fixed64":
+		return "ulong"
+	}
+
+	return "[ubyte]"
+}
+
+// wellKnownType returns the FlatBuffers type of a well-known type. Wrappers
+// are optional scalars, which default to null, and the types with no
+// FlatBuffers counterpart, such as google.protobuf.Struct, are JSON strings.
+func wellKnownType(name string) string {
+	if scalar, ok := protobuf.WrapperScalar(name); ok {
+		typ := basicType(scalar)
+		if typ == "string" || strings.HasPrefix(typ, "[") {
+			return typ
+		}
+		return typ + " = null"
+	}
+
+	switch name {
+	case "Timestamp", "Duration":
+		return "long"
+	}
+
+	return "string"
+}
@@ -0,0 +1,141 @@
+package flatbuffers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/protobuf/protobuftest"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+// mockPackage returns the fixture package with an enum with an underlying
+// type, another one without it and a repeated field of bytes.
+func mockPackage() *protobuf.Package {
+	pkg := protobuftest.Package()
+	pkg.Messages[0].Fields = append(pkg.Messages[0].Fields, &protobuf.Field{
+		Name:     "keys",
+		Pos:      13,
+		Type:     protobuf.NewBasic("bytes"),
+		Repeated: true,
+	})
+	pkg.Enums[0].Src = &scanner.Enum{Name: "Status", Underlying: "byte"}
+	pkg.Enums = append(pkg.Enums, &protobuf.Enum{
+		Name: "Level",
+		Values: []*protobuf.EnumValue{
+			{Name: "LOW", Value: 0},
+		},
+	})
+	return pkg
+}
+
+const expected = `// Code generated by proteus. DO NOT EDIT.
+
+include "../bar/bar.fbs";
+
+namespace example.foo;
+
+/// Status of an user.
+enum Status : ubyte {
+  /// ACTIVE users can log in.
+  ACTIVE = 0,
+  BANNED = 1,
+}
+
+enum Level : int {
+  LOW = 0,
+}
+
+/// User is an user.
+table User {
+  /// ID of the user.
+  id:ulong;
+  first_name:string;
+  age:uint;
+  status:Status;
+  /// Address of the user.
+  address:Address;
+  tags:[string];
+  created_at:long;
+  nickname:string;
+  score:double = null;
+  labels:[UserLabelsEntry];
+  group:example.bar.Group;
+  avatar:[ubyte];
+  keys:[Bytes];
+}
+
+/// UserLabelsEntry is an entry of User.labels, sorted by key.
+table UserLabelsEntry {
+  key:string (key);
+  value:int;
+}
+
+table Address {
+  city:string;
+}
+
+table GetUserRequest {
+  arg1:ulong;
+  arg2:Status;
+  arg3:Address;
+}
+
+/// Bytes is a vector of bytes in a vector.
+table Bytes {
+  data:[ubyte];
+}
+
+rpc_service FooService {
+  /// GetUser returns an user.
+  GetUser(GetUserRequest):User;
+  UpdateAddress(User):User;
+  CreateUser(User):User;
+}
+`
+
+func TestGenerate(t *testing.T) {
+	path, err := ioutil.TempDir("", "proteus")
+	require.Nil(t, err)
+	defer os.RemoveAll(path)
+
+	require.Nil(t, NewGenerator(path).Generate(mockPackage()))
+
+	data, err := ioutil.ReadFile(filepath.Join(path, "github.com/example/foo", "foo.fbs"))
+	require.Nil(t, err)
+	require.Equal(t, expected, string(data))
+}
+
+func TestCamelCase(t *testing.T) {
+	require.Equal(t, "Labels", camelCase("labels"))
+	require.Equal(t, "ExtraLabels", camelCase("extra_labels"))
+	require.Equal(t, "ExtraLabels", camelCase("_extra__labels"))
+}
+
+func TestEnumType(t *testing.T) {
+	cases := map[string]string{
+		"int8":   "byte",
+		"byte":   "ubyte",
+		"uint8":  "ubyte",
+		"int16":  "short",
+		"uint16": "ushort",
+		"int32":  "int",
+		"uint32": "uint",
+		"int64":  "long",
+		"uint64": "ulong",
+		"int":    "long",
+		"uint":   "ulong",
+		"string": "int",
+	}
+
+	for underlying, expected := range cases {
+		enum := &protobuf.Enum{Src: &scanner.Enum{Underlying: underlying}}
+		require.Equal(t, expected, enumType(enum), underlying)
+	}
+
+	require.Equal(t, "int", enumType(&protobuf.Enum{}))
+}
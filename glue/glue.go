@@ -0,0 +1,184 @@
+package glue // import "gopkg.in/src-d/proteus.v1/glue"
+
+import (
+	"bytes"
+	"go/format"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/scanner"
+
+	"gopkg.in/src-d/go-parse-utils.v1"
+)
+
+// Generator generates the Go code that the code generated by protoc needs to
+// work with some of the Go types of a package. That is, the methods required
+// by gogo/protobuf custom types for the type declarations of fixed-size byte
+// arrays, such as `type Hash [32]byte`, which are mapped to `bytes`. Decoding
+// a value fails if the data does not have exactly the length of the array.
+//
+// Methods are not generated for the types that already have a Marshal
+// method, so you can provide your own implementation.
+//
+// A single file per package will be generated containing all the methods.
+// The file will be written to the package path and it will be named
+// "glue.proteus.go". If the package does not need any glue, no file is
+// written.
+type Generator struct {
+	importer *parseutil.Importer
+}
+
+// NewGenerator creates a new Generator.
+func NewGenerator() *Generator {
+	return &Generator{parseutil.NewImporter()}
+}
+
+// Generate creates a new file in the package at the given path with the glue
+// code needed by the given proto package.
+func (g *Generator) Generate(proto *protobuf.Package, path string) error {
+	names := byteArrays(proto)
+	if len(names) == 0 {
+		return nil
+	}
+
+	pkg, err := g.importer.ImportWithFilters(
+		path,
+		parseutil.FileFilters{
+			func(pkg, file string, typ parseutil.FileType) bool {
+				return !strings.HasSuffix(file, ".pb.go")
+			},
+			func(pkg, file string, typ parseutil.FileType) bool {
+				return !strings.HasSuffix(file, ".proteus.go")
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	var arrays []byteArray
+	for _, name := range names {
+		if hasMethod(pkg, name, "Marshal") {
+			report.Info("type %s already has a Marshal method, not generating its glue code", name)
+			continue
+		}
+
+		arrays = append(arrays, byteArray{Name: name, Recv: receiverName(name)})
+	}
+
+	if len(arrays) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, struct {
+		Package string
+		Arrays  []byteArray
+	}{pkg.Name(), arrays}); err != nil {
+		return err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(goSrc, path, "glue.proteus.go"), src, 0644)
+}
+
+type byteArray struct {
+	Name string
+	Recv string
+}
+
+// byteArrays returns the sorted names of the type declarations of byte
+// arrays of the package that are used in the fields of its messages.
+func byteArrays(proto *protobuf.Package) []string {
+	seen := make(map[string]struct{})
+	var names []string
+	for _, m := range proto.Messages {
+		for _, f := range m.Fields {
+			src := f.Type.Source()
+			if !protobuf.IsByteArray(src) {
+				continue
+			}
+
+			named, ok := src.(*scanner.Alias).Type.(*scanner.Named)
+			if !ok || named.Path != proto.Path {
+				continue
+			}
+
+			if _, ok := seen[named.Name]; !ok {
+				seen[named.Name] = struct{}{}
+				names = append(names, named.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func hasMethod(pkg *types.Package, typeName, method string) bool {
+	obj := pkg.Scope().Lookup(typeName)
+	if obj == nil {
+		return false
+	}
+
+	mset := types.NewMethodSet(types.NewPointer(obj.Type()))
+	return mset.Lookup(pkg, method) != nil
+}
+
+func receiverName(typeName string) string {
+	return string(unicode.ToLower([]rune(typeName)[0]))
+}
+
+var fileTemplate = template.Must(template.New("glue").Parse(`package {{.Package}}
+
+import (
+	"encoding/json"
+
+	"gopkg.in/src-d/proteus.v1/stdtypes"
+)
+{{range .Arrays}}
+// Marshal returns the bytes of the array.
+func ({{.Recv}} {{.Name}}) Marshal() ([]byte, error) {
+	return {{.Recv}}[:], nil
+}
+
+// MarshalTo copies the bytes of the array to data.
+func ({{.Recv}} *{{.Name}}) MarshalTo(data []byte) (int, error) {
+	return copy(data, {{.Recv}}[:]), nil
+}
+
+// Unmarshal copies data to the array. It fails if data does not have exactly
+// the length of the array.
+func ({{.Recv}} *{{.Name}}) Unmarshal(data []byte) error {
+	return stdtypes.UnmarshalFixedBytes({{.Recv}}[:], data)
+}
+
+// Size returns the length of the array.
+func ({{.Recv}} *{{.Name}}) Size() int {
+	return len({{.Recv}})
+}
+
+// MarshalJSON encodes the array as a base64 JSON string.
+func ({{.Recv}} {{.Name}}) MarshalJSON() ([]byte, error) {
+	return json.Marshal({{.Recv}}[:])
+}
+
+// UnmarshalJSON decodes the array from a base64 JSON string. It fails if the
+// decoded bytes do not have exactly the length of the array.
+func ({{.Recv}} *{{.Name}}) UnmarshalJSON(data []byte) error {
+	return stdtypes.UnmarshalFixedBytesJSON({{.Recv}}[:], data)
+}
+{{end}}`))
+
+var goSrc = filepath.Join(os.Getenv("GOPATH"), "src")
@@ -0,0 +1,149 @@
+package glue
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/resolver"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+const fixturePkg = "gopkg.in/src-d/proteus.v1/fixtures/glue"
+
+const fixtureFile = `package glue
+
+import "errors"
+
+type Hash [32]byte
+
+type Key [4]byte
+
+type Token []byte
+
+type Custom [8]byte
+
+func (c Custom) Marshal() ([]byte, error) { return nil, errors.New("custom") }
+
+//proteus:generate
+type Foo struct {
+	Hash   Hash
+	Key    *Key
+	Token  Token
+	Custom Custom
+	Raw    []byte
+}
+`
+
+const expectedFile = `package glue
+
+import (
+	"encoding/json"
+
+	"gopkg.in/src-d/proteus.v1/stdtypes"
+)
+
+// Marshal returns the bytes of the array.
+func (h Hash) Marshal() ([]byte, error) {
+	return h[:], nil
+}
+
+// MarshalTo copies the bytes of the array to data.
+func (h *Hash) MarshalTo(data []byte) (int, error) {
+	return copy(data, h[:]), nil
+}
+
+// Unmarshal copies data to the array. It fails if data does not have exactly
+// the length of the array.
+func (h *Hash) Unmarshal(data []byte) error {
+	return stdtypes.UnmarshalFixedBytes(h[:], data)
+}
+
+// Size returns the length of the array.
+func (h *Hash) Size() int {
+	return len(h)
+}
+
+// MarshalJSON encodes the array as a base64 JSON string.
+func (h Hash) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h[:])
+}
+
+// UnmarshalJSON decodes the array from a base64 JSON string. It fails if the
+// decoded bytes do not have exactly the length of the array.
+func (h *Hash) UnmarshalJSON(data []byte) error {
+	return stdtypes.UnmarshalFixedBytesJSON(h[:], data)
+}
+
+// Marshal returns the bytes of the array.
+func (k Key) Marshal() ([]byte, error) {
+	return k[:], nil
+}
+
+// MarshalTo copies the bytes of the array to data.
+func (k *Key) MarshalTo(data []byte) (int, error) {
+	return copy(data, k[:]), nil
+}
+
+// Unmarshal copies data to the array. It fails if data does not have exactly
+// the length of the array.
+func (k *Key) Unmarshal(data []byte) error {
+	return stdtypes.UnmarshalFixedBytes(k[:], data)
+}
+
+// Size returns the length of the array.
+func (k *Key) Size() int {
+	return len(k)
+}
+
+// MarshalJSON encodes the array as a base64 JSON string.
+func (k Key) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k[:])
+}
+
+// UnmarshalJSON decodes the array from a base64 JSON string. It fails if the
+// decoded bytes do not have exactly the length of the array.
+func (k *Key) UnmarshalJSON(data []byte) error {
+	return stdtypes.UnmarshalFixedBytesJSON(k[:], data)
+}
+`
+
+func TestGenerate(t *testing.T) {
+	require := require.New(t)
+
+	dir := projectPath("fixtures/glue")
+	require.Nil(os.MkdirAll(dir, 0777))
+	defer os.RemoveAll(dir)
+	require.Nil(ioutil.WriteFile(filepath.Join(dir, "foo.go"), []byte(fixtureFile), 0644))
+
+	s, err := scanner.New(fixturePkg)
+	require.Nil(err)
+
+	pkgs, err := s.Scan()
+	require.Nil(err)
+	resolver.New().Resolve(pkgs)
+
+	proto := protobuf.NewTransformer().Transform(pkgs[0])
+	require.Nil(NewGenerator().Generate(proto, fixturePkg))
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "glue.proteus.go"))
+	require.Nil(err)
+	require.Equal(expectedFile, string(data))
+}
+
+func TestGenerateNoGlue(t *testing.T) {
+	require.Nil(t, NewGenerator().Generate(&protobuf.Package{
+		Path: fixturePkg,
+		Messages: []*protobuf.Message{
+			{Name: "Foo", Fields: []*protobuf.Field{{Name: "foo", Type: protobuf.NewBasic("bytes")}}},
+		},
+	}, fixturePkg), "does not need to import the package")
+}
+
+func projectPath(path string) string {
+	return filepath.Join(goSrc, "gopkg.in/src-d/proteus.v1", path)
+}
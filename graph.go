@@ -0,0 +1,73 @@
+package proteus
+
+import (
+	"fmt"
+
+	"gopkg.in/src-d/proteus.v1/graph"
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/resolver"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+// BuildGraph scans, resolves and transforms the packages in the given options
+// and returns the graph of their messages, enums and RPCs, once the plugins
+// in the options are applied to them. The nodes of the messages that are
+// generated only because a field or func uses their struct, and not because
+// it is marked with //proteus:generate, have the name of that field or func
+// in MarkedBy.
+func BuildGraph(options Options) (*graph.Graph, error) {
+	pkgs, r, err := loadPackages(options)
+	if err != nil {
+		return nil, err
+	}
+	r.Resolve(pkgs)
+
+	if err := checkAllowedTypes(options, pkgs); err != nil {
+		return nil, err
+	}
+
+	var protos []*protobuf.Package
+	err = TransformToProtobuf(options, pkgs, func(_ *scanner.Package, pkg *protobuf.Package) error {
+		protos = append(protos, pkg)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g := graph.New(protos)
+	for i, p := range pkgs {
+		annotateGraph(g, r.Trace(), p, protos[i])
+	}
+
+	return g, nil
+}
+
+// annotateGraph sets the Go names of the nodes of the given package and the
+// fields or funcs marking the structs that are not marked with
+// //proteus:generate.
+func annotateGraph(g *graph.Graph, trace *resolver.Trace, p *scanner.Package, proto *protobuf.Package) {
+	for _, s := range p.Structs {
+		n := g.Node(proto.Name + "." + s.Name)
+		if n == nil {
+			continue
+		}
+
+		n.GoName = fmt.Sprintf("%s.%s", p.Path, s.Name)
+		if !s.Generate {
+			n.MarkedBy = trace.MarkedBy[n.GoName]
+		}
+	}
+
+	for _, e := range proto.Enums {
+		if n := g.Node(proto.Name + "." + e.Name); n != nil && e.Src != nil {
+			n.GoName = fmt.Sprintf("%s.%s", p.Path, e.Src.Name)
+		}
+	}
+
+	for _, rpc := range proto.RPCs {
+		if n := g.Node(proto.Name + "." + proto.ServiceName() + "." + rpc.Name); n != nil && rpc.Src != nil {
+			n.GoName = resolver.FuncName(p, rpc.Src)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package graph // import "gopkg.in/src-d/proteus.v1/graph"
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+)
+
+// NodeKind is the kind of a node of the graph.
+type NodeKind string
+
+const (
+	// Message is a message of the packages.
+	Message NodeKind = "message"
+	// Enum is an enum of the packages.
+	Enum NodeKind = "enum"
+	// RPC is an RPC of the service of a package.
+	RPC NodeKind = "rpc"
+	// External is a message or enum referenced by the packages but defined
+	// elsewhere, such as a well-known type or a mapped type.
+	External NodeKind = "external"
+)
+
+// EdgeKind is the kind of an edge of the graph.
+type EdgeKind string
+
+const (
+	// Field is an edge from a message to the type of one of its fields.
+	Field EdgeKind = "field"
+	// Input is an edge from an RPC to its request message.
+	Input EdgeKind = "input"
+	// Output is an edge from an RPC to its response message.
+	Output EdgeKind = "output"
+)
+
+// Graph is the graph of the messages, enums and RPCs of a set of protobuf
+// packages and of the types they reference.
+type Graph struct {
+	Nodes []*Node `json:"nodes"`
+	Edges []*Edge `json:"edges"`
+
+	index map[string]*Node
+}
+
+// Node is a message, enum or RPC of the graph.
+type Node struct {
+	// ID is the full name of the node, which is "PACKAGE.Name" for messages
+	// and enums and "PACKAGE.Service.Name" for RPCs.
+	ID      string   `json:"id"`
+	Kind    NodeKind `json:"kind"`
+	Package string   `json:"package"`
+	Name    string   `json:"name"`
+	// GoName is the full name of the Go type or func of the node, if any.
+	GoName string `json:"go_name,omitempty"`
+	// MarkedBy is the full name of the Go field or func using the struct of
+	// the node, if it is generated only because it is used and not because
+	// it is marked with //proteus:generate.
+	MarkedBy string `json:"marked_by,omitempty"`
+}
+
+// Edge is a reference from a message or RPC to a message or enum.
+type Edge struct {
+	From string   `json:"from"`
+	To   string   `json:"to"`
+	Kind EdgeKind `json:"kind"`
+	// Field is the name of the field, for field edges.
+	Field string `json:"field,omitempty"`
+	// CrossPackage reports whether the nodes are in different packages, so
+	// the proto file of the package of From imports the one of To.
+	CrossPackage bool `json:"cross_package"`
+}
+
+// New creates the graph of the given packages.
+func New(pkgs []*protobuf.Package) *Graph {
+	g := &Graph{
+		Nodes: []*Node{},
+		Edges: []*Edge{},
+		index: make(map[string]*Node),
+	}
+	for _, p := range pkgs {
+		for _, msg := range p.Messages {
+			g.addNode(Message, p.Name, msg.Name)
+		}
+
+		for _, enum := range p.Enums {
+			g.addNode(Enum, p.Name, enum.Name)
+		}
+
+		for _, rpc := range p.RPCs {
+			g.addNode(RPC, p.Name, p.ServiceName()+"."+rpc.Name)
+		}
+	}
+
+	for _, p := range pkgs {
+		for _, msg := range p.Messages {
+			from := p.Name + "." + msg.Name
+			for _, f := range msg.Fields {
+				g.addEdges(p.Name, from, Field, f.Name, f.Type)
+			}
+		}
+
+		for _, rpc := range p.RPCs {
+			from := p.Name + "." + p.ServiceName() + "." + rpc.Name
+			g.addEdges(p.Name, from, Input, "", rpc.Input)
+			g.addEdges(p.Name, from, Output, "", rpc.Output)
+		}
+	}
+
+	return g
+}
+
+// Node returns the node with the given ID, or nil if there is none.
+func (g *Graph) Node(id string) *Node {
+	return g.index[id]
+}
+
+func (g *Graph) addNode(kind NodeKind, pkg, name string) *Node {
+	n := &Node{
+		ID:      pkg + "." + name,
+		Kind:    kind,
+		Package: pkg,
+		Name:    name,
+	}
+	g.Nodes = append(g.Nodes, n)
+	g.index[n.ID] = n
+	return n
+}
+
+// addEdges adds the edges from the node with the given ID, of the given
+// package, to the named types in the given type.
+func (g *Graph) addEdges(pkg, from string, kind EdgeKind, field string, typ protobuf.Type) {
+	switch t := typ.(type) {
+	case *protobuf.Named:
+		to := g.Node(t.String())
+		if to == nil {
+			to = g.addNode(External, t.Package, t.Name)
+		}
+
+		g.Edges = append(g.Edges, &Edge{
+			From:         from,
+			To:           to.ID,
+			Kind:         kind,
+			Field:        field,
+			CrossPackage: to.Package != pkg,
+		})
+	case *protobuf.Map:
+		g.addEdges(pkg, from, kind, field, t.Key)
+		g.addEdges(pkg, from, kind, field, t.Value)
+	case *protobuf.Alias:
+		g.addEdges(pkg, from, kind, field, t.Underlying)
+	}
+}
+
+// WriteJSON writes the graph as a JSON document.
+func (g *Graph) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// nodeAttrs are the DOT attributes of every kind of node.
+var nodeAttrs = map[NodeKind]string{
+	Message:  "shape=box",
+	Enum:     "shape=ellipse",
+	RPC:      "shape=cds",
+	External: "shape=box, style=dashed",
+}
+
+// WriteDOT writes the graph in the DOT language of Graphviz. The nodes of
+// every package are grouped in a cluster, the messages generated only
+// because they are used are filled and the edges between packages are red.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var buf bytes.Buffer
+	buf.WriteString("digraph proteus {\n")
+	buf.WriteString("  rankdir=LR;\n")
+
+	var pkgs []string
+	byPkg := make(map[string][]*Node)
+	for _, n := range g.Nodes {
+		if _, ok := byPkg[n.Package]; !ok {
+			pkgs = append(pkgs, n.Package)
+		}
+		byPkg[n.Package] = append(byPkg[n.Package], n)
+	}
+
+	for i, pkg := range pkgs {
+		fmt.Fprintf(&buf, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(&buf, "    label=%q;\n", pkg)
+		for _, n := range byPkg[pkg] {
+			attrs := nodeAttrs[n.Kind]
+			if n.MarkedBy != "" {
+				attrs += fmt.Sprintf(", style=filled, fillcolor=lightyellow, tooltip=%q", "marked by "+n.MarkedBy)
+			}
+			fmt.Fprintf(&buf, "    %q [label=%q, %s];\n", n.ID, n.Name, attrs)
+		}
+		buf.WriteString("  }\n")
+	}
+
+	for _, e := range g.Edges {
+		label := string(e.Kind)
+		if e.Field != "" {
+			label = e.Field
+		}
+
+		attrs := fmt.Sprintf("label=%q", label)
+		if e.CrossPackage {
+			attrs += ", color=red"
+		}
+		fmt.Fprintf(&buf, "  %q -> %q [%s];\n", e.From, e.To, attrs)
+	}
+
+	buf.WriteString("}\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
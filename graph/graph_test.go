@@ -0,0 +1,137 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+)
+
+func mockPackages() []*protobuf.Package {
+	return []*protobuf.Package{
+		{
+			Name: "foo",
+			Path: "github.com/example/foo",
+			Messages: []*protobuf.Message{
+				{
+					Name: "User",
+					Fields: []*protobuf.Field{
+						{Name: "id", Pos: 1, Type: protobuf.NewBasic("uint64")},
+						{Name: "status", Pos: 2, Type: protobuf.NewNamed("foo", "Status")},
+						{Name: "group", Pos: 3, Type: protobuf.NewNamed("bar", "Group")},
+						{Name: "created_at", Pos: 4, Type: protobuf.NewNamed("google.protobuf", "Timestamp")},
+						{Name: "labels", Pos: 5, Type: protobuf.NewMap(protobuf.NewBasic("string"), protobuf.NewNamed("foo", "Label"))},
+					},
+				},
+				{
+					Name: "Label",
+					Fields: []*protobuf.Field{
+						{Name: "name", Pos: 1, Type: protobuf.NewAlias(protobuf.NewNamed("foo", "Name"), protobuf.NewBasic("string"))},
+					},
+				},
+				{
+					Name: "FooService_GetUserRequest",
+					Fields: []*protobuf.Field{
+						{Name: "arg1", Pos: 1, Type: protobuf.NewBasic("uint64")},
+					},
+				},
+			},
+			Enums: []*protobuf.Enum{
+				{Name: "Status"},
+			},
+			RPCs: []*protobuf.RPC{
+				{
+					Name:   "GetUser",
+					Input:  protobuf.NewGeneratedNamed("foo", "FooService_GetUserRequest"),
+					Output: protobuf.NewNamed("foo", "User"),
+				},
+			},
+		},
+		{
+			Name: "bar",
+			Path: "github.com/example/bar",
+			Messages: []*protobuf.Message{
+				{Name: "Group"},
+			},
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	require := require.New(t)
+	g := New(mockPackages())
+
+	var nodes []string
+	for _, n := range g.Nodes {
+		nodes = append(nodes, string(n.Kind)+" "+n.ID)
+	}
+	require.Equal([]string{
+		"message foo.User",
+		"message foo.Label",
+		"message foo.FooService_GetUserRequest",
+		"enum foo.Status",
+		"rpc foo.FooService.GetUser",
+		"message bar.Group",
+		"external google.protobuf.Timestamp",
+	}, nodes)
+
+	require.Equal([]*Edge{
+		{From: "foo.User", To: "foo.Status", Kind: Field, Field: "status"},
+		{From: "foo.User", To: "bar.Group", Kind: Field, Field: "group", CrossPackage: true},
+		{From: "foo.User", To: "google.protobuf.Timestamp", Kind: Field, Field: "created_at", CrossPackage: true},
+		{From: "foo.User", To: "foo.Label", Kind: Field, Field: "labels"},
+		{From: "foo.FooService.GetUser", To: "foo.FooService_GetUserRequest", Kind: Input},
+		{From: "foo.FooService.GetUser", To: "foo.User", Kind: Output},
+	}, g.Edges)
+
+	require.Equal(Enum, g.Node("foo.Status").Kind)
+	require.Nil(g.Node("foo.Name"))
+}
+
+func TestWriteDOT(t *testing.T) {
+	g := New(mockPackages()[1:])
+	g.Nodes[0].MarkedBy = "github.com/example/foo.User.Group"
+
+	var buf bytes.Buffer
+	require.NoError(t, g.WriteDOT(&buf))
+	require.Equal(t, `digraph proteus {
+  rankdir=LR;
+  subgraph cluster_0 {
+    label="bar";
+    "bar.Group" [label="Group", shape=box, style=filled, fillcolor=lightyellow, tooltip="marked by github.com/example/foo.User.Group"];
+  }
+}
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, New(mockPackages()).WriteDOT(&buf))
+	require.Contains(t, buf.String(), `  "foo.User" -> "bar.Group" [label="group", color=red];`)
+	require.Contains(t, buf.String(), `  "foo.FooService.GetUser" -> "foo.User" [label="output"];`)
+	require.Contains(t, buf.String(), `    "google.protobuf.Timestamp" [label="Timestamp", shape=box, style=dashed];`)
+}
+
+func TestWriteJSON(t *testing.T) {
+	require := require.New(t)
+	g := New(mockPackages())
+
+	var buf bytes.Buffer
+	require.NoError(g.WriteJSON(&buf))
+
+	var result Graph
+	require.NoError(json.Unmarshal(buf.Bytes(), &result))
+	require.Equal(g.Nodes, result.Nodes)
+	require.Equal(g.Edges, result.Edges)
+}
+
+func TestWriteJSONEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, New(mockPackages()[1:]).WriteJSON(&buf))
+	require.Contains(t, buf.String(), `"edges": []`)
+
+	buf.Reset()
+	require.NoError(t, New(nil).WriteJSON(&buf))
+	require.Contains(t, buf.String(), `"nodes": []`)
+}
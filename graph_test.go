@@ -0,0 +1,86 @@
+package proteus
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/graph"
+	"gopkg.in/src-d/proteus.v1/report"
+)
+
+func TestBuildGraph(t *testing.T) {
+	require := require.New(t)
+	report.TestMode()
+	defer report.EndTestMode()
+
+	g, err := BuildGraph(Options{Packages: []string{fixturesPkg, subpkg}})
+	require.NoError(err)
+
+	const pkg = "gopkg.in.srcd.proteus.v1.fixtures"
+	jur := g.Node(pkg + ".Jur")
+	require.NotNil(jur)
+	require.Equal(graph.Message, jur.Kind)
+	require.Equal(fixturesPkg+".Jur", jur.GoName)
+	require.Equal(fixturesPkg+".Foo.AliasedMap", jur.MarkedBy)
+
+	foo := g.Node(pkg + ".Foo")
+	require.NotNil(foo)
+	require.Equal(fixturesPkg+".Foo", foo.GoName)
+	require.Empty(foo.MarkedBy, "structs marked with //proteus:generate are not marked by others")
+
+	var rpc *graph.Node
+	for _, n := range g.Nodes {
+		if n.Kind == graph.RPC && n.Name == "SubpkgService.Point_GeneratedMethod" {
+			rpc = n
+		}
+	}
+	require.NotNil(rpc)
+	require.Equal(subpkg+".Point.GeneratedMethod", rpc.GoName)
+
+	var cross, timestamp bool
+	for _, e := range g.Edges {
+		if e.From == rpc.ID && e.Kind == graph.Input {
+			cross = e.CrossPackage
+		}
+		if e.From == foo.ID && e.To == "google.protobuf.Timestamp" {
+			timestamp = true
+		}
+	}
+	require.False(cross, "the request of the RPC is in its package")
+	require.True(timestamp)
+	require.Equal(graph.External, g.Node("google.protobuf.Timestamp").Kind)
+}
+
+func TestBuildGraphPlugins(t *testing.T) {
+	require := require.New(t)
+	report.TestMode()
+	defer report.EndTestMode()
+
+	dir, err := ioutil.TempDir("", "proteus")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	resp := `{"patches": [{"package": "` + fixturesPkg + `", "target": "Foo.aliased_map", "rename": "jurisdictions"}]}`
+	script := "#!/bin/sh\ncat > /dev/null\necho '" + resp + "'\n"
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "proteus-gen-rename"), []byte(script), 0755))
+
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+
+	g, err := BuildGraph(Options{Packages: []string{fixturesPkg}, Plugins: []string{"rename"}})
+	require.NoError(err)
+
+	const pkg = "gopkg.in.srcd.proteus.v1.fixtures"
+	var fields []string
+	for _, e := range g.Edges {
+		if e.From == pkg+".Foo" && e.To == pkg+".Jur" {
+			fields = append(fields, e.Field)
+		}
+	}
+	require.Equal([]string{"jurisdictions"}, fields, "fields renamed by plugins are in the graph")
+}
@@ -0,0 +1,410 @@
+package graphql // import "gopkg.in/src-d/proteus.v1/graphql"
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/report"
+)
+
+const (
+	operationDirective = "graphql"
+	query              = "query"
+	mutation           = "mutation"
+	skip               = "-"
+)
+
+// Generator is in charge of generating the GraphQL schema of a protobuf
+// package and write it to disk in a file named "schema.graphql" at the given
+// path.
+//
+// Messages are object types, with an input type named "NAMEInput" for the
+// ones used by the arguments of the operations, and enums are enums. Every
+// RPC is a field of the Query or Mutation type, chosen with the
+// `//proteus:graphql query` and `//proteus:graphql mutation` directives of
+// the function. Without the directive, RPCs with a GET HTTP rule are queries
+// and the rest of them are mutations. RPCs can be left out of the schema
+// with `//proteus:graphql -`.
+//
+// The arguments of the Go function are the arguments of the field and its
+// result is the type of the field, or a Boolean if it returns nothing.
+// Types of other packages are referenced by their name, as their input
+// types are not generated, so only their enums can be used by the arguments.
+// Types with no GraphQL counterpart are custom scalars: Int64, UInt64,
+// Bytes, Timestamp, Duration and JSON, which is also used for maps.
+type Generator struct {
+	basePath string
+}
+
+// NewGenerator creates a new Generator with the given base path.
+func NewGenerator(basePath string) *Generator {
+	return &Generator{basePath}
+}
+
+// Generate generates the GraphQL schema of the given package and writes it
+// to disk.
+func (g *Generator) Generate(pkg *protobuf.Package) error {
+	w := newWriter(pkg)
+
+	var body bytes.Buffer
+	w.writeSchema(&body)
+
+	var buf bytes.Buffer
+	buf.WriteString("# Code generated by proteus. DO NOT EDIT.\n\n")
+	w.writeScalars(&buf)
+	buf.Write(bytes.TrimRight(body.Bytes(), "\n"))
+	buf.WriteRune('\n')
+
+	return g.writeFile(pkg.Path, buf.Bytes())
+}
+
+func (g *Generator) writeFile(path string, data []byte) error {
+	path = filepath.Join(g.basePath, path)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return err
+	}
+
+	file := filepath.Join(path, "schema.graphql")
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		return err
+	}
+
+	report.Info("Generated GraphQL schema: %s", file)
+	return nil
+}
+
+// writer writes the schema of a package.
+type writer struct {
+	pkg      *protobuf.Package
+	messages map[string]*protobuf.Message
+	enums    map[string]bool
+	// wrappers are the generated messages wrapping the arguments and results
+	// of the functions, which are not types of the schema.
+	wrappers map[string]bool
+	// inputs are the messages used as input types.
+	inputs  map[string]bool
+	scalars map[string]bool
+}
+
+func newWriter(pkg *protobuf.Package) *writer {
+	w := &writer{
+		pkg:      pkg,
+		messages: make(map[string]*protobuf.Message),
+		enums:    make(map[string]bool),
+		wrappers: make(map[string]bool),
+		inputs:   make(map[string]bool),
+		scalars:  make(map[string]bool),
+	}
+
+	for _, msg := range pkg.Messages {
+		w.messages[msg.Name] = msg
+	}
+
+	for _, enum := range pkg.Enums {
+		w.enums[enum.Name] = true
+	}
+
+	for _, rpc := range pkg.RPCs {
+		if msg := w.generated(rpc.Input); msg != nil {
+			w.wrappers[msg.Name] = true
+			for _, f := range msg.Fields {
+				w.addInput(f.Type)
+			}
+		} else {
+			w.addInput(rpc.Input)
+		}
+
+		if msg := w.generated(rpc.Output); msg != nil && len(msg.Fields) <= 1 {
+			w.wrappers[msg.Name] = true
+		}
+	}
+
+	return w
+}
+
+// generated returns the message of the type if it was generated by proteus
+// to wrap the arguments or results of a function.
+func (w *writer) generated(typ protobuf.Type) *protobuf.Message {
+	if named, ok := typ.(*protobuf.Named); ok && named.Generated && named.Package == w.pkg.Name {
+		return w.messages[named.Name]
+	}
+	return nil
+}
+
+// addInput marks the message of the type, and the messages of its fields,
+// as input types.
+func (w *writer) addInput(typ protobuf.Type) {
+	switch t := typ.(type) {
+	case *protobuf.Alias:
+		w.addInput(t.Underlying)
+	case *protobuf.Named:
+		msg, ok := w.messages[t.Name]
+		if t.Package != w.pkg.Name || !ok || w.inputs[t.Name] {
+			return
+		}
+
+		w.inputs[t.Name] = true
+		for _, f := range msg.Fields {
+			w.addInput(f.Type)
+		}
+	}
+}
+
+func (w *writer) writeSchema(buf *bytes.Buffer) {
+	for _, msg := range w.pkg.Messages {
+		if w.wrappers[msg.Name] {
+			continue
+		}
+
+		w.writeObject(buf, "type", msg.Name, msg, false)
+		buf.WriteRune('\n')
+
+		if w.inputs[msg.Name] {
+			w.writeObject(buf, "input", msg.Name+"Input", msg, true)
+			buf.WriteRune('\n')
+		}
+	}
+
+	for _, enum := range w.pkg.Enums {
+		writeEnum(buf, enum)
+		buf.WriteRune('\n')
+	}
+
+	var queries, mutations []*protobuf.RPC
+	for _, rpc := range w.pkg.RPCs {
+		switch operation(rpc) {
+		case query:
+			queries = append(queries, rpc)
+		case mutation:
+			mutations = append(mutations, rpc)
+		}
+	}
+
+	w.writeOperations(buf, "Query", queries)
+	w.writeOperations(buf, "Mutation", mutations)
+}
+
+// operation returns the operation type of the RPC.
+func operation(rpc *protobuf.RPC) string {
+	if rpc.Src != nil {
+		if op, ok := rpc.Src.Directives.Get(operationDirective); ok {
+			switch op = strings.TrimSpace(op); op {
+			case query, mutation, skip:
+				return op
+			default:
+				report.Warn("invalid graphql directive %q of func %q, expecting query, mutation or -", op, rpc.Name)
+			}
+		}
+	}
+
+	if rule := rpc.HTTPRule(); rule != nil && rule.Method == "GET" {
+		return query
+	}
+	return mutation
+}
+
+func (w *writer) writeObject(buf *bytes.Buffer, kind, name string, msg *protobuf.Message, input bool) {
+	writeDescription(buf, msg.Docs, "")
+	buf.WriteString(fmt.Sprintf("%s %s {\n", kind, name))
+	for _, f := range msg.Fields {
+		writeDescription(buf, f.Docs, "  ")
+		buf.WriteString(fmt.Sprintf("  %s: %s\n", f.JSONName(), w.fieldType(f, input)))
+	}
+	buf.WriteString("}\n")
+}
+
+func writeEnum(buf *bytes.Buffer, enum *protobuf.Enum) {
+	writeDescription(buf, enum.Docs, "")
+	buf.WriteString(fmt.Sprintf("enum %s {\n", enum.Name))
+	for _, v := range enum.Values {
+		writeDescription(buf, v.Docs, "  ")
+		buf.WriteString(fmt.Sprintf("  %s\n", v.Name))
+	}
+	buf.WriteString("}\n")
+}
+
+func (w *writer) writeOperations(buf *bytes.Buffer, name string, rpcs []*protobuf.RPC) {
+	if len(rpcs) == 0 {
+		return
+	}
+
+	buf.WriteString(fmt.Sprintf("type %s {\n", name))
+	for _, rpc := range rpcs {
+		writeDescription(buf, rpc.Docs, "  ")
+		buf.WriteString(fmt.Sprintf("  %s%s: %s\n", fieldName(rpc.Name), w.arguments(rpc), w.result(rpc)))
+	}
+	buf.WriteString("}\n\n")
+}
+
+// arguments returns the arguments of the field of the RPC, which are the
+// fields of the request if it was generated by proteus or the request as
+// an input otherwise.
+func (w *writer) arguments(rpc *protobuf.RPC) string {
+	var args []string
+	if msg := w.generated(rpc.Input); msg != nil {
+		for _, f := range msg.Fields {
+			args = append(args, fmt.Sprintf("%s: %s", f.JSONName(), w.fieldType(f, true)))
+		}
+	} else {
+		args = append(args, fmt.Sprintf("input: %s", w.typeName(rpc.Input, true, false)))
+	}
+
+	if len(args) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("(%s)", strings.Join(args, ", "))
+}
+
+// result returns the type of the field of the RPC. Responses generated by
+// proteus with a single result are unwrapped and empty responses are
+// Boolean.
+func (w *writer) result(rpc *protobuf.RPC) string {
+	msg := w.generated(rpc.Output)
+	if msg == nil || !w.wrappers[msg.Name] {
+		return w.typeName(rpc.Output, false, true)
+	}
+
+	if len(msg.Fields) == 0 {
+		return "Boolean"
+	}
+
+	return w.fieldType(msg.Fields[0], false)
+}
+
+func (w *writer) fieldType(f *protobuf.Field, input bool) string {
+	typ := w.typeName(f.Type, input, f.Type.IsNullable())
+	if f.Repeated {
+		typ = fmt.Sprintf("[%s]", strings.TrimSuffix(typ, "!")+"!")
+	}
+	return typ
+}
+
+// typeName returns the name of the GraphQL type, which is non-null unless
+// it is nullable. Scalars and enums are never nullable, as they have a
+// default value in proto3.
+func (w *writer) typeName(typ protobuf.Type, input, nullable bool) string {
+	switch t := typ.(type) {
+	case *protobuf.Basic:
+		return w.scalar(basicScalar(t.Name)) + "!"
+	case *protobuf.Alias:
+		return w.typeName(t.Underlying, input, nullable)
+	case *protobuf.Map:
+		return w.scalar("JSON") + "!"
+	case *protobuf.Named:
+		name := w.namedType(t, input)
+		if w.enums[t.Name] && t.Package == w.pkg.Name {
+			return name + "!"
+		}
+
+		if wrapped, ok := protobuf.WrapperScalar(t.Name); ok && t.Package == "google.protobuf" {
+			return w.scalar(basicScalar(wrapped))
+		}
+
+		if !nullable {
+			return name + "!"
+		}
+		return name
+	}
+
+	return w.scalar("JSON")
+}
+
+func (w *writer) namedType(t *protobuf.Named, input bool) string {
+	if t.Package == "google.protobuf" {
+		return w.scalar(wellKnownScalar(t.Name))
+	}
+
+	if input && t.Package == w.pkg.Name && w.inputs[t.Name] {
+		return t.Name + "Input"
+	}
+	return t.Name
+}
+
+// scalar registers a scalar as used and returns its name.
+func (w *writer) scalar(name string) string {
+	w.scalars[name] = true
+	return name
+}
+
+// builtinScalars are the scalars defined by GraphQL.
+var builtinScalars = map[string]bool{
+	"Int": true, "Float": true, "String": true, "Boolean": true, "ID": true,
+}
+
+func (w *writer) writeScalars(buf *bytes.Buffer) {
+	var names []string
+	for name := range w.scalars {
+		if !builtinScalars[name] {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		buf.WriteString(fmt.Sprintf("scalar %s\n", name))
+	}
+	buf.WriteRune('\n')
+}
+
+// basicScalar returns the GraphQL scalar of a protobuf scalar type. Int is
+// a signed 32-bit integer, so unsigned 32-bit integers are Int64.
+func basicScalar(name string) string {
+	switch name {
+	case "int32", "sint32", "sfixed32":
+		return "Int"
+	case "uint32", "fixed32", "int64", "sint64", "sfixed64":
+		return "Int64"
+	case "uint64", "fixed64":
+		return "UInt64"
+	case "float", "double":
+		return "Float"
+	case "bool":
+		return "Boolean"
+	case "string":
+		return "String"
+	case "bytes":
+		return "Bytes"
+	}
+
+	return "JSON"
+}
+
+func wellKnownScalar(name string) string {
+	if scalar, ok := protobuf.WrapperScalar(name); ok {
+		return basicScalar(scalar)
+	}
+
+	switch name {
+	case "Timestamp", "Duration":
+		return name
+	}
+	return "JSON"
+}
+
+// fieldName returns the name of the field of a RPC in lower camel case.
+func fieldName(name string) string {
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+func writeDescription(buf *bytes.Buffer, docs []string, indent string) {
+	if len(docs) == 0 {
+		return
+	}
+
+	buf.WriteString(indent + `"""` + "\n")
+	for _, d := range docs {
+		buf.WriteString(strings.TrimRight(indent+strings.Replace(d, `"""`, `\"""`, -1), " "))
+		buf.WriteRune('\n')
+	}
+	buf.WriteString(indent + `"""` + "\n")
+}
@@ -0,0 +1,181 @@
+package graphql
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/protobuf/protobuftest"
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+func withDirective(name, value string) *scanner.Func {
+	return &scanner.Func{Docs: scanner.Docs{Directives: scanner.Directives{name: {value}}}}
+}
+
+// mockPackage returns the fixture package with RPCs with graphql directives
+// and generated responses. The User message is an input, so its field of
+// another package is an enum, as there are no input types of other packages.
+func mockPackage() *protobuf.Package {
+	pkg := protobuftest.Package()
+	pkg.Messages[0].Fields[10] = &protobuf.Field{Name: "role", Pos: 11, Type: protobuftest.External("Role")}
+	pkg.Messages = append(pkg.Messages,
+		&protobuf.Message{Name: "CountUsersRequest"},
+		&protobuf.Message{
+			Name: "CountUsersResponse",
+			Fields: []*protobuf.Field{
+				{Name: "result1", Pos: 1, Type: protobuf.NewBasic("int32")},
+			},
+		},
+		&protobuf.Message{Name: "DeleteUserResponse"},
+	)
+	pkg.RPCs = append(pkg.RPCs,
+		&protobuf.RPC{
+			Name:   "CountUsers",
+			Input:  protobuftest.Generated("CountUsersRequest"),
+			Output: protobuftest.Generated("CountUsersResponse"),
+			Src:    withDirective("graphql", "query"),
+		},
+		&protobuf.RPC{
+			Name:   "DeleteUser",
+			Input:  protobuftest.Generated("GetUserRequest"),
+			Output: protobuftest.Generated("DeleteUserResponse"),
+			Src:    withDirective("graphql", "mutation"),
+		},
+		&protobuf.RPC{
+			Name:   "Internal",
+			Input:  protobuftest.Named("User"),
+			Output: protobuftest.Named("User"),
+			Src:    withDirective("graphql", "-"),
+		},
+	)
+	return pkg
+}
+
+const expected = `# Code generated by proteus. DO NOT EDIT.
+
+scalar Bytes
+scalar Int64
+scalar JSON
+scalar Timestamp
+scalar UInt64
+
+"""
+User is an user.
+"""
+type User {
+  """
+  ID of the user.
+  """
+  id: UInt64!
+  firstName: String!
+  age: Int64!
+  status: Status!
+  """
+  Address of the user.
+  """
+  address: Address
+  tags: [String!]
+  createdAt: Timestamp
+  nickname: String
+  score: Float
+  labels: JSON!
+  role: Role!
+  avatar: Bytes!
+}
+
+"""
+User is an user.
+"""
+input UserInput {
+  """
+  ID of the user.
+  """
+  id: UInt64!
+  firstName: String!
+  age: Int64!
+  status: Status!
+  """
+  Address of the user.
+  """
+  address: AddressInput
+  tags: [String!]
+  createdAt: Timestamp
+  nickname: String
+  score: Float
+  labels: JSON!
+  role: Role!
+  avatar: Bytes!
+}
+
+type Address {
+  city: String!
+}
+
+input AddressInput {
+  city: String!
+}
+
+"""
+Status of an user.
+"""
+enum Status {
+  """
+  ACTIVE users can log in.
+  """
+  ACTIVE
+  BANNED
+}
+
+type Query {
+  """
+  GetUser returns an user.
+  """
+  getUser(arg1: UInt64!, arg2: Status!, arg3: AddressInput): User
+  countUsers: Int!
+}
+
+type Mutation {
+  updateAddress(input: UserInput!): User
+  createUser(input: UserInput!): User
+  deleteUser(arg1: UInt64!, arg2: Status!, arg3: AddressInput): Boolean
+}
+`
+
+func TestGenerate(t *testing.T) {
+	path, err := ioutil.TempDir("", "proteus")
+	require.Nil(t, err)
+	defer os.RemoveAll(path)
+
+	require.Nil(t, NewGenerator(path).Generate(mockPackage()))
+
+	data, err := ioutil.ReadFile(filepath.Join(path, "github.com/example/foo", "schema.graphql"))
+	require.Nil(t, err)
+	require.Equal(t, expected, string(data))
+}
+
+func TestNamedType(t *testing.T) {
+	w := newWriter(mockPackage())
+
+	require.Equal(t, "UserInput", w.namedType(protobuftest.Named("User"), true))
+	require.Equal(t, "User", w.namedType(protobuftest.Named("User"), false))
+	require.Equal(t, "Status", w.namedType(protobuftest.Named("Status"), true))
+	require.Equal(t, "Role", w.namedType(protobuf.NewNamed("bar", "Role"), true), "types of other packages have no input type")
+	require.Equal(t, "Timestamp", w.namedType(protobuf.NewNamed("google.protobuf", "Timestamp"), true))
+}
+
+func TestOperation(t *testing.T) {
+	report.TestMode()
+	defer report.EndTestMode()
+
+	require.Equal(t, mutation, operation(&protobuf.RPC{Name: "Foo"}))
+	require.Equal(t, query, operation(&protobuf.RPC{Name: "Foo", Src: withDirective("graphql", "query")}))
+	require.Equal(t, skip, operation(&protobuf.RPC{Name: "Foo", Src: withDirective("graphql", "-")}))
+	require.Equal(t, mutation, operation(&protobuf.RPC{Name: "Foo", Src: withDirective("graphql", "subscription")}))
+	require.Len(t, report.MessageStack(), 1, "invalid directives are reported")
+}
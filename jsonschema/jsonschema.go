@@ -0,0 +1,240 @@
+package jsonschema // import "gopkg.in/src-d/proteus.v1/jsonschema"
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+// Draft is the JSON Schema dialect of the generated schemas.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Generator is in charge of generating the JSON schemas of the messages and
+// enums of a protobuf package and write them to disk at the given path, in a
+// file named "NAME.schema.json" for each one of them.
+//
+// The schemas validate the JSON mapping of proto3 used by protojson: fields
+// are named in lower camel case, 64-bit integers can be strings, enums are
+// the names of their values and well-known types have their special JSON
+// representation. Messages are referenced with $ref to their own schema file,
+// even if they are in another package, so the packages of the referenced
+// messages must be generated as well.
+type Generator struct {
+	basePath string
+}
+
+// NewGenerator creates a new Generator with the given base path.
+func NewGenerator(basePath string) *Generator {
+	return &Generator{basePath}
+}
+
+// Generate generates the JSON schemas of the messages and enums of the given
+// package and writes them to disk.
+func (g *Generator) Generate(pkg *protobuf.Package) error {
+	b := &builder{pkg}
+	for _, msg := range pkg.Messages {
+		if err := g.writeSchema(pkg.Path, msg.Name, b.messageSchema(msg)); err != nil {
+			return err
+		}
+	}
+
+	for _, enum := range pkg.Enums {
+		if err := g.writeSchema(pkg.Path, enum.Name, enumSchema(enum)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g *Generator) writeSchema(path, name string, s *schema) error {
+	s.Schema = Draft
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path = filepath.Join(g.basePath, path)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return err
+	}
+
+	file := filepath.Join(path, fileName(name))
+	if err := ioutil.WriteFile(file, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+
+	report.Info("Generated JSON schema: %s", file)
+	return nil
+}
+
+func fileName(name string) string {
+	return name + ".schema.json"
+}
+
+type schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Minimum              *int64             `json:"minimum,omitempty"`
+	Maximum              *int64             `json:"maximum,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+}
+
+// builder builds the schemas of a package.
+type builder struct {
+	pkg *protobuf.Package
+}
+
+// messageSchema returns the schema of a message. Fields that are not in the
+// message are not allowed, so typos are caught by the validation.
+func (b *builder) messageSchema(msg *protobuf.Message) *schema {
+	s := &schema{
+		Title:                msg.Name,
+		Description:          protobuf.Description(msg.Docs),
+		Type:                 "object",
+		Properties:           make(map[string]*schema),
+		AdditionalProperties: false,
+	}
+
+	for _, f := range msg.Fields {
+		fs := b.typeSchema(f.Type)
+		if f.Repeated {
+			fs = &schema{Type: "array", Items: fs}
+		}
+		fs.Description = protobuf.Description(f.Docs)
+		s.Properties[f.JSONName()] = fs
+	}
+
+	return s
+}
+
+func enumSchema(enum *protobuf.Enum) *schema {
+	s := &schema{
+		Title:       enum.Name,
+		Description: protobuf.Description(enum.Docs),
+		Type:        "string",
+	}
+
+	for _, v := range enum.Values {
+		s.Enum = append(s.Enum, v.Name)
+	}
+
+	return s
+}
+
+func (b *builder) typeSchema(typ protobuf.Type) *schema {
+	switch t := typ.(type) {
+	case *protobuf.Basic:
+		return basicSchema(t.Name)
+	case *protobuf.Alias:
+		return b.typeSchema(t.Underlying)
+	case *protobuf.Map:
+		return &schema{
+			Type:                 "object",
+			AdditionalProperties: b.typeSchema(t.Value),
+		}
+	case *protobuf.Named:
+		return b.namedSchema(t)
+	}
+
+	return &schema{}
+}
+
+func (b *builder) namedSchema(t *protobuf.Named) *schema {
+	if t.Package == b.pkg.Name {
+		return &schema{Ref: fileName(t.Name)}
+	}
+
+	if t.Package == "google.protobuf" {
+		return wellKnownSchema(t.Name)
+	}
+
+	if src, ok := t.Source().(*scanner.Named); ok && src.Path != "" {
+		rel, err := filepath.Rel(b.pkg.Path, src.Path)
+		if err == nil {
+			return &schema{Ref: filepath.ToSlash(filepath.Join(rel, fileName(t.Name)))}
+		}
+	}
+
+	report.Warn("type %s cannot be referenced from the JSON schemas of %s, any value will be valid", t, b.pkg.Name)
+	return &schema{}
+}
+
+func bounds(min, max int64) *schema {
+	return &schema{Type: "integer", Minimum: &min, Maximum: &max}
+}
+
+// int64Pattern is the pattern of the 64-bit integers encoded as strings.
+const int64Pattern = `^-?[0-9]+$`
+
+// basicSchema returns the schema of a scalar type. 64-bit integers are
+// encoded as strings by protojson but numbers are also accepted.
+func basicSchema(name string) *schema {
+	switch name {
+	case "int32", "sint32", "sfixed32":
+		return bounds(math.MinInt32, math.MaxInt32)
+	case "uint32", "fixed32":
+		return bounds(0, math.MaxUint32)
+	case "int64", "sint64", "sfixed64", "uint64", "fixed64":
+		return &schema{Type: []string{"string", "integer"}, Pattern: int64Pattern}
+	case "float", "double":
+		return &schema{Type: "number"}
+	case "bool":
+		return &schema{Type: "boolean"}
+	case "string":
+		return &schema{Type: "string"}
+	case "bytes":
+		return &schema{Type: "string", ContentEncoding: "base64"}
+	}
+
+	return &schema{}
+}
+
+// durationPattern is the pattern of google.protobuf.Duration in JSON, which
+// is the number of seconds with up to 9 fractional digits and an "s" suffix.
+const durationPattern = `^-?[0-9]+(\.[0-9]{1,9})?s$`
+
+// wellKnownSchema returns the schema of the JSON representation of a
+// well-known type. Wrappers can be null.
+func wellKnownSchema(name string) *schema {
+	if scalar, ok := protobuf.WrapperScalar(name); ok {
+		s := basicSchema(scalar)
+		switch t := s.Type.(type) {
+		case string:
+			s.Type = []string{t, "null"}
+		case []string:
+			s.Type = append(t, "null")
+		}
+		return s
+	}
+
+	switch name {
+	case "Timestamp":
+		return &schema{Type: "string", Format: "date-time"}
+	case "Duration":
+		return &schema{Type: "string", Pattern: durationPattern}
+	case "FieldMask":
+		return &schema{Type: "string"}
+	case "ListValue":
+		return &schema{Type: "array"}
+	case "Value":
+		return &schema{}
+	}
+
+	return &schema{Type: "object"}
+}
@@ -0,0 +1,68 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/protobuf/protobuftest"
+)
+
+func int64p(n int64) *int64 {
+	return &n
+}
+
+func TestMessageSchema(t *testing.T) {
+	pkg := protobuftest.Package()
+	s := (&builder{pkg}).messageSchema(pkg.Messages[0])
+
+	require.Equal(t, "User", s.Title)
+	require.Equal(t, "User is an user.", s.Description)
+	require.Equal(t, "object", s.Type)
+	require.Equal(t, false, s.AdditionalProperties)
+	require.Equal(t, map[string]*schema{
+		"id":        {Type: []string{"string", "integer"}, Pattern: int64Pattern, Description: "ID of the user."},
+		"firstName": {Type: "string"},
+		"age":       {Type: "integer", Minimum: int64p(0), Maximum: int64p(4294967295)},
+		"status":    {Ref: "Status.schema.json"},
+		"address":   {Ref: "Address.schema.json", Description: "Address of the user."},
+		"tags":      {Type: "array", Items: &schema{Type: "string"}},
+		"createdAt": {Type: "string", Format: "date-time"},
+		"nickname":  {Type: []string{"string", "null"}},
+		"score":     {Type: []string{"number", "null"}},
+		"labels":    {Type: "object", AdditionalProperties: &schema{Type: "integer", Minimum: int64p(-2147483648), Maximum: int64p(2147483647)}},
+		"group":     {Ref: "../bar/Group.schema.json"},
+		"avatar":    {Type: "string", ContentEncoding: "base64"},
+	}, s.Properties)
+}
+
+func TestEnumSchema(t *testing.T) {
+	require.Equal(t, &schema{
+		Title:       "Status",
+		Description: "Status of an user.",
+		Type:        "string",
+		Enum:        []string{"ACTIVE", "BANNED"},
+	}, enumSchema(protobuftest.Package().Enums[0]))
+}
+
+func TestGenerate(t *testing.T) {
+	path, err := ioutil.TempDir("", "proteus")
+	require.Nil(t, err)
+	defer os.RemoveAll(path)
+
+	require.Nil(t, NewGenerator(path).Generate(protobuftest.Package()))
+
+	for _, name := range []string{"User", "Status"} {
+		data, err := ioutil.ReadFile(filepath.Join(path, "github.com/example/foo", name+".schema.json"))
+		require.Nil(t, err, name)
+
+		var s map[string]interface{}
+		require.Nil(t, json.Unmarshal(data, &s), name)
+		require.Equal(t, Draft, s["$schema"], name)
+		require.Equal(t, name, s["title"], name)
+	}
+}
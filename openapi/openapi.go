@@ -0,0 +1,440 @@
+package openapi // import "gopkg.in/src-d/proteus.v1/openapi"
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+// Version is the version of the OpenAPI specification of the generated
+// documents.
+const Version = "3.0.3"
+
+// DefaultAPIVersion is the version of the API used when none is given.
+const DefaultAPIVersion = "1.0.0"
+
+// Generator is in charge of generating the OpenAPI document of the service
+// of a protobuf package and write it to disk in a file named "openapi.json"
+// at the given path.
+//
+// The RPCs are exposed with the method and path of their HTTP rule, set with
+// the `//proteus:http` directive. The rest of them are exposed with a POST
+// to /PACKAGE.SERVICE/RPC with the whole request as the body, which is the
+// same convention used by gRPC. Messages and enums are defined as schemas in
+// the components of the document using the JSON mapping of proto3, and the
+// messages of other packages are referenced from their own documents.
+type Generator struct {
+	basePath   string
+	apiVersion string
+}
+
+// NewGenerator creates a new Generator with the given base path.
+func NewGenerator(basePath string) *Generator {
+	return &Generator{basePath, DefaultAPIVersion}
+}
+
+// SetAPIVersion sets the version of the API in the generated documents.
+func (g *Generator) SetAPIVersion(version string) {
+	g.apiVersion = version
+}
+
+// Generate generates the OpenAPI document of the given package and writes
+// it to disk.
+func (g *Generator) Generate(pkg *protobuf.Package) error {
+	doc := newBuilder(pkg).build(g.apiVersion)
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return g.writeFile(pkg.Path, append(data, '\n'))
+}
+
+func (g *Generator) writeFile(path string, data []byte) error {
+	path = filepath.Join(g.basePath, path)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return err
+	}
+
+	file := filepath.Join(path, "openapi.json")
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		return err
+	}
+
+	report.Info("Generated OpenAPI document: %s", file)
+	return nil
+}
+
+type document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       info                `json:"info"`
+	Paths      map[string]pathItem `json:"paths"`
+	Components components          `json:"components"`
+}
+
+type info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type components struct {
+	Schemas map[string]*schema `json:"schemas,omitempty"`
+}
+
+// pathItem contains the operations of a path by HTTP method in lower case.
+type pathItem map[string]*operation
+
+type operation struct {
+	OperationID string       `json:"operationId"`
+	Description string       `json:"description,omitempty"`
+	Tags        []string     `json:"tags,omitempty"`
+	Parameters  []*parameter `json:"parameters,omitempty"`
+	RequestBody *requestBody `json:"requestBody,omitempty"`
+	Responses   responses    `json:"responses"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool    `json:"required"`
+	Content  content `json:"content"`
+}
+
+type responses map[string]*response
+
+type response struct {
+	Description string  `json:"description"`
+	Content     content `json:"content,omitempty"`
+}
+
+type content map[string]*mediaType
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	AllOf                []*schema          `json:"allOf,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	AdditionalProperties *schema            `json:"additionalProperties,omitempty"`
+}
+
+func jsonContent(s *schema) content {
+	return content{"application/json": &mediaType{s}}
+}
+
+// builder builds the OpenAPI document of a package.
+type builder struct {
+	pkg *protobuf.Package
+	doc *document
+}
+
+func newBuilder(pkg *protobuf.Package) *builder {
+	return &builder{pkg: pkg}
+}
+
+func (b *builder) build(version string) *document {
+	b.doc = &document{
+		OpenAPI: Version,
+		Info: info{
+			Title:   b.pkg.ServiceName(),
+			Version: version,
+		},
+		Paths:      make(map[string]pathItem),
+		Components: components{Schemas: make(map[string]*schema)},
+	}
+
+	for _, msg := range b.pkg.Messages {
+		b.doc.Components.Schemas[msg.Name] = b.messageSchema(msg)
+	}
+
+	for _, enum := range b.pkg.Enums {
+		b.doc.Components.Schemas[enum.Name] = enumSchema(enum)
+	}
+
+	for _, rpc := range b.pkg.RPCs {
+		b.addOperation(rpc)
+	}
+
+	return b.doc
+}
+
+func (b *builder) messageSchema(msg *protobuf.Message) *schema {
+	s := &schema{
+		Type:        "object",
+		Description: protobuf.Description(msg.Docs),
+		Properties:  make(map[string]*schema),
+	}
+
+	for _, f := range msg.Fields {
+		s.Properties[f.JSONName()] = b.fieldSchema(f)
+	}
+
+	return s
+}
+
+func enumSchema(enum *protobuf.Enum) *schema {
+	s := &schema{
+		Type:        "string",
+		Description: protobuf.Description(enum.Docs),
+	}
+
+	for _, v := range enum.Values {
+		s.Enum = append(s.Enum, v.Name)
+	}
+
+	return s
+}
+
+func (b *builder) fieldSchema(f *protobuf.Field) *schema {
+	s := b.typeSchema(f.Type)
+	if f.Repeated {
+		s = &schema{Type: "array", Items: s}
+	}
+
+	return withDescription(s, protobuf.Description(f.Docs))
+}
+
+// withDescription adds the description to the schema. References cannot
+// have siblings, so they are wrapped in an allOf.
+func withDescription(s *schema, desc string) *schema {
+	if desc == "" {
+		return s
+	}
+
+	if s.Ref != "" {
+		return &schema{AllOf: []*schema{s}, Description: desc}
+	}
+
+	s.Description = desc
+	return s
+}
+
+func (b *builder) typeSchema(typ protobuf.Type) *schema {
+	switch t := typ.(type) {
+	case *protobuf.Basic:
+		return basicSchema(t.Name)
+	case *protobuf.Alias:
+		return b.typeSchema(t.Underlying)
+	case *protobuf.Map:
+		return &schema{
+			Type:                 "object",
+			AdditionalProperties: b.typeSchema(t.Value),
+		}
+	case *protobuf.Named:
+		return b.namedSchema(t)
+	}
+
+	return &schema{}
+}
+
+func (b *builder) namedSchema(t *protobuf.Named) *schema {
+	if t.Package == b.pkg.Name {
+		return &schema{Ref: "#/components/schemas/" + t.Name}
+	}
+
+	if t.Package == "google.protobuf" {
+		return wellKnownSchema(t.Name)
+	}
+
+	if src, ok := t.Source().(*scanner.Named); ok && src.Path != "" {
+		rel, err := filepath.Rel(b.pkg.Path, src.Path)
+		if err == nil {
+			return &schema{Ref: fmt.Sprintf(
+				"%s#/components/schemas/%s",
+				filepath.ToSlash(filepath.Join(rel, "openapi.json")),
+				t.Name,
+			)}
+		}
+	}
+
+	report.Warn("type %s cannot be referenced from the OpenAPI document of %s, using a generic object", t, b.pkg.Name)
+	return &schema{Type: "object", Description: t.String()}
+}
+
+// basicSchema returns the schema of a scalar type. 64-bit integers are
+// strings in the JSON mapping of proto3.
+func basicSchema(name string) *schema {
+	switch name {
+	case "int32", "sint32", "sfixed32":
+		return &schema{Type: "integer", Format: "int32"}
+	case "uint32", "fixed32":
+		return &schema{Type: "integer", Format: "int64"}
+	case "int64", "sint64", "sfixed64":
+		return &schema{Type: "string", Format: "int64"}
+	case "uint64", "fixed64":
+		return &schema{Type: "string", Format: "uint64"}
+	case "float":
+		return &schema{Type: "number", Format: "float"}
+	case "double":
+		return &schema{Type: "number", Format: "double"}
+	case "bool":
+		return &schema{Type: "boolean"}
+	case "string":
+		return &schema{Type: "string"}
+	case "bytes":
+		return &schema{Type: "string", Format: "byte"}
+	}
+
+	return &schema{}
+}
+
+func wellKnownSchema(name string) *schema {
+	if scalar, ok := protobuf.WrapperScalar(name); ok {
+		s := basicSchema(scalar)
+		s.Nullable = true
+		return s
+	}
+
+	switch name {
+	case "Timestamp":
+		return &schema{Type: "string", Format: "date-time"}
+	case "Duration":
+		return &schema{Type: "string"}
+	case "Value":
+		return &schema{}
+	}
+
+	return &schema{Type: "object"}
+}
+
+func (b *builder) addOperation(rpc *protobuf.RPC) {
+	rule := rpc.HTTPRule()
+	if rule == nil {
+		rule = &protobuf.HTTPRule{
+			Method: "POST",
+			Path:   fmt.Sprintf("/%s.%s/%s", b.pkg.Name, b.pkg.ServiceName(), rpc.Name),
+			Body:   "*",
+		}
+	}
+
+	path := rule.SimplePath()
+	method := strings.ToLower(rule.Method)
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = make(pathItem)
+		b.doc.Paths[path] = item
+	}
+
+	if _, ok := item[method]; ok {
+		report.Warn("RPC %s uses the same method and path as another RPC, it will not be added to the OpenAPI document: %s %s", rpc.Name, rule.Method, path)
+		return
+	}
+
+	op := &operation{
+		OperationID: rpc.Name,
+		Description: protobuf.Description(rpc.Docs),
+		Tags:        []string{b.pkg.ServiceName()},
+		Responses: responses{
+			"200": &response{
+				Description: "A successful response.",
+				Content:     jsonContent(b.typeSchema(rpc.Output)),
+			},
+		},
+	}
+
+	bound := make(map[string]bool)
+	for _, param := range rule.PathParams() {
+		bound[strings.Split(param, ".")[0]] = true
+		op.Parameters = append(op.Parameters, b.parameter(rpc.Input, param, "path"))
+	}
+
+	msg := b.pkg.Message(rpc.Input)
+	switch rule.Body {
+	case "*":
+		op.RequestBody = &requestBody{Required: true, Content: jsonContent(b.typeSchema(rpc.Input))}
+	case "":
+	default:
+		bound[rule.Body] = true
+		var s = &schema{}
+		if f := msg.Field(rule.Body); f != nil {
+			s = b.fieldSchema(f)
+		}
+		op.RequestBody = &requestBody{Required: true, Content: jsonContent(s)}
+	}
+
+	// the rest of fields of the request are query parameters, unless the
+	// whole request is the body
+	if msg != nil && rule.Body != "*" {
+		for _, f := range msg.Fields {
+			if bound[f.Name] {
+				continue
+			}
+
+			if b.isQueryParam(f) {
+				op.Parameters = append(op.Parameters, b.parameter(rpc.Input, f.Name, "query"))
+			}
+		}
+	}
+
+	item[method] = op
+}
+
+// parameter returns the parameter of the field with the given path in the
+// request. Path parameters are named as in the path template and query
+// parameters with the JSON name of the field.
+func (b *builder) parameter(input protobuf.Type, path, in string) *parameter {
+	p := &parameter{Name: path, In: in, Required: in == "path", Schema: &schema{Type: "string"}}
+
+	msg := b.pkg.Message(input)
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		f := msg.Field(name)
+		if f == nil {
+			break
+		}
+
+		if i == len(names)-1 {
+			if in == "query" {
+				p.Name = f.JSONName()
+			}
+			p.Description = protobuf.Description(f.Docs)
+			p.Schema = b.typeSchema(f.Type)
+			if f.Repeated {
+				p.Schema = &schema{Type: "array", Items: p.Schema}
+			}
+			break
+		}
+
+		msg = b.pkg.Message(f.Type)
+	}
+
+	return p
+}
+
+// isQueryParam reports whether the field can be a query parameter, which is
+// only possible for scalars, enums and lists of them.
+func (b *builder) isQueryParam(f *protobuf.Field) bool {
+	typ := f.Type
+	if alias, ok := typ.(*protobuf.Alias); ok {
+		typ = alias.Underlying
+	}
+
+	if named, ok := typ.(*protobuf.Named); ok && named.Package == b.pkg.Name {
+		return b.pkg.Message(named) == nil
+	}
+
+	s := b.typeSchema(typ)
+	return s.Ref == "" && s.Type != "" && s.Type != "object"
+}
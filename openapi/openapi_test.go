@@ -0,0 +1,93 @@
+package openapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/protobuf/protobuftest"
+)
+
+func TestBuildSchemas(t *testing.T) {
+	doc := newBuilder(protobuftest.Package()).build("2.0.0")
+	require.Equal(t, Version, doc.OpenAPI)
+	require.Equal(t, info{Title: "FooService", Version: "2.0.0"}, doc.Info)
+
+	user := doc.Components.Schemas["User"]
+	require.Equal(t, "object", user.Type)
+	require.Equal(t, "User is an user.", user.Description)
+	require.Equal(t, map[string]*schema{
+		"id":        {Type: "string", Format: "uint64", Description: "ID of the user."},
+		"firstName": {Type: "string"},
+		"age":       {Type: "integer", Format: "int64"},
+		"status":    {Ref: "#/components/schemas/Status"},
+		"address": {
+			AllOf:       []*schema{{Ref: "#/components/schemas/Address"}},
+			Description: "Address of the user.",
+		},
+		"tags":      {Type: "array", Items: &schema{Type: "string"}},
+		"createdAt": {Type: "string", Format: "date-time"},
+		"nickname":  {Type: "string", Nullable: true},
+		"score":     {Type: "number", Format: "double", Nullable: true},
+		"labels": {
+			Type:                 "object",
+			AdditionalProperties: &schema{Type: "integer", Format: "int32"},
+		},
+		"group":  {Ref: "../bar/openapi.json#/components/schemas/Group"},
+		"avatar": {Type: "string", Format: "byte"},
+	}, user.Properties)
+
+	require.Equal(t, &schema{
+		Type:        "string",
+		Description: "Status of an user.",
+		Enum:        []string{"ACTIVE", "BANNED"},
+	}, doc.Components.Schemas["Status"])
+}
+
+func TestBuildPaths(t *testing.T) {
+	doc := newBuilder(protobuftest.Package()).build(DefaultAPIVersion)
+	require.Len(t, doc.Paths, 3)
+
+	get := doc.Paths["/v1/users/{arg1}"]["get"]
+	require.NotNil(t, get)
+	require.Equal(t, "GetUser", get.OperationID)
+	require.Equal(t, "GetUser returns an user.", get.Description)
+	require.Nil(t, get.RequestBody)
+	require.Equal(t, []*parameter{
+		{Name: "arg1", In: "path", Required: true, Schema: &schema{Type: "string", Format: "uint64"}},
+		{Name: "arg2", In: "query", Schema: &schema{Ref: "#/components/schemas/Status"}},
+	}, get.Parameters)
+	require.Equal(t, &schema{Ref: "#/components/schemas/User"}, get.Responses["200"].Content["application/json"].Schema)
+
+	patch := doc.Paths["/v1/users/{id}/cities/{address.city}"]["patch"]
+	require.NotNil(t, patch)
+	require.Len(t, patch.Parameters, 10, "path parameters and scalar fields not in the body")
+	require.Equal(t, &parameter{Name: "address.city", In: "path", Required: true, Schema: &schema{Type: "string"}}, patch.Parameters[1])
+	require.Equal(t, &parameter{Name: "firstName", In: "query", Schema: &schema{Type: "string"}}, patch.Parameters[2])
+	require.Equal(t, "Address of the user.", patch.RequestBody.Content["application/json"].Schema.Description)
+
+	post := doc.Paths["/example.foo.FooService/CreateUser"]["post"]
+	require.NotNil(t, post)
+	require.Empty(t, post.Parameters)
+	require.Equal(t, &schema{Ref: "#/components/schemas/User"}, post.RequestBody.Content["application/json"].Schema)
+}
+
+func TestGenerate(t *testing.T) {
+	path, err := ioutil.TempDir("", "proteus")
+	require.Nil(t, err)
+	defer os.RemoveAll(path)
+
+	require.Nil(t, NewGenerator(path).Generate(protobuftest.Package()))
+
+	data, err := ioutil.ReadFile(filepath.Join(path, "github.com/example/foo", "openapi.json"))
+	require.Nil(t, err)
+
+	var doc map[string]interface{}
+	require.Nil(t, json.Unmarshal(data, &doc))
+	require.Equal(t, Version, doc["openapi"])
+	require.Contains(t, doc["paths"], "/v1/users/{arg1}")
+}
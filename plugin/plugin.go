@@ -0,0 +1,258 @@
+// Package plugin implements the protocol of the out-of-process plugins of
+// proteus, which are executables named "proteus-gen-NAME" in the PATH.
+//
+// A plugin is run with a Request, encoded as JSON, in its stdin, with the
+// Go and protobuf representations of the packages being generated, and it
+// must write a Response, encoded as JSON, to its stdout, with the files it
+// generated and the modifications of the protobuf packages, if any.
+// Everything the plugin writes to its stderr is shown to the user.
+//
+// As the protocol only relies on JSON and standard streams, plugins can be
+// written in any language and do not need to be built with the same
+// version of Go and of the dependencies as proteus.
+package plugin // import "gopkg.in/src-d/proteus.v1/plugin"
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gogo/protobuf/protoc-gen-gogo/generator"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/report"
+)
+
+// Prefix is the prefix of the name of the executables of the plugins.
+const Prefix = "proteus-gen-"
+
+const customNameOption = "(gogoproto.customname)"
+
+// Lookup returns the path of the executable of the plugin with the given
+// name, which must be in the PATH.
+func Lookup(name string) (string, error) {
+	return exec.LookPath(Prefix + name)
+}
+
+// Run runs the plugin with the given name with the given request and
+// returns its response.
+func Run(name string, req *Request) (*Response, error) {
+	path, err := Lookup(name)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s not found: %s", name, err)
+	}
+
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var output bytes.Buffer
+	cmd := exec.Command(path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &output
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("plugin %s failed: %s", name, err)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(output.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("plugin %s returned an invalid response: %s", name, err)
+	}
+
+	if resp.Error != "" {
+		return nil, fmt.Errorf("plugin %s failed: %s", name, resp.Error)
+	}
+
+	return &resp, nil
+}
+
+// WriteFiles writes the files of the response to the given folder. The
+// names of the files must be relative paths inside the folder.
+func (r *Response) WriteFiles(basePath string) error {
+	for _, f := range r.Files {
+		name := filepath.Clean(filepath.FromSlash(f.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("file %s of the plugin is outside of the output folder", f.Name)
+		}
+
+		file := filepath.Join(basePath, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return err
+		}
+
+		if err := ioutil.WriteFile(file, []byte(f.Content), 0644); err != nil {
+			return err
+		}
+
+		report.Info("Generated file: %s", file)
+	}
+
+	return nil
+}
+
+// Apply applies the patches of the response to the given packages.
+func (r *Response) Apply(pkgs []*protobuf.Package) error {
+	for _, p := range r.Patches {
+		if err := p.apply(pkgs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Patch) apply(pkgs []*protobuf.Package) error {
+	var pkg *protobuf.Package
+	for _, pk := range pkgs {
+		if pk.Path == p.Package {
+			pkg = pk
+		}
+	}
+
+	if pkg == nil {
+		return fmt.Errorf("cannot patch %s: package %s is not being generated", p, p.Package)
+	}
+
+	opts, err := p.parseOptions()
+	if err != nil {
+		return err
+	}
+
+	if p.Target == "" {
+		if p.Rename != "" {
+			return fmt.Errorf("cannot patch %s: packages cannot be renamed", p)
+		}
+		pkg.Options = merge(pkg.Options, opts)
+		return nil
+	}
+
+	parts := strings.SplitN(p.Target, ".", 2)
+	if len(parts) == 2 {
+		return p.applyMember(pkg, parts[0], parts[1], opts)
+	}
+
+	for _, msg := range pkg.Messages {
+		if msg.Name == p.Target {
+			if p.Rename != "" {
+				return fmt.Errorf("cannot patch %s: messages cannot be renamed, as the Go code generated by protoc uses their Go types", p)
+			}
+			msg.Options = merge(msg.Options, opts)
+			return nil
+		}
+	}
+
+	for _, enum := range pkg.Enums {
+		if enum.Name == p.Target {
+			if p.Rename != "" {
+				return fmt.Errorf("cannot patch %s: enums cannot be renamed, as the Go code generated by protoc uses their Go types", p)
+			}
+			enum.Options = merge(enum.Options, opts)
+			return nil
+		}
+	}
+
+	for _, rpc := range pkg.RPCs {
+		if rpc.Name == p.Target {
+			rpc.Options = merge(rpc.Options, opts)
+			if p.Rename != "" {
+				rpc.Name = p.Rename
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("cannot patch %s: there is no message, enum or RPC named %s", p, p.Target)
+}
+
+// applyMember applies the patch to a field of a message or a value of an
+// enum.
+func (p *Patch) applyMember(pkg *protobuf.Package, parent, member string, opts protobuf.Options) error {
+	for _, msg := range pkg.Messages {
+		if msg.Name != parent {
+			continue
+		}
+
+		for _, f := range msg.Fields {
+			if f.Name == member {
+				f.Options = merge(f.Options, opts)
+				if p.Rename != "" {
+					keepGoName(f)
+					f.Name = p.Rename
+				}
+				return nil
+			}
+		}
+	}
+
+	for _, enum := range pkg.Enums {
+		if enum.Name != parent {
+			continue
+		}
+
+		for _, v := range enum.Values {
+			if v.Name == member {
+				v.Options = merge(v.Options, opts)
+				if p.Rename != "" {
+					v.Name = p.Rename
+				}
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("cannot patch %s: there is no field or enum value %s", p, p.Target)
+}
+
+// keepGoName sets the gogoproto.customname option of the field to the name
+// of its Go field before it is renamed, so the Go code generated by protoc
+// still uses the Go field.
+func keepGoName(f *protobuf.Field) {
+	if _, ok := f.Options[customNameOption]; ok {
+		return
+	}
+
+	if f.Options == nil {
+		f.Options = make(protobuf.Options)
+	}
+	f.Options[customNameOption] = protobuf.NewStringValue(generator.CamelCase(f.Name))
+}
+
+func (p *Patch) parseOptions() (protobuf.Options, error) {
+	opts := make(protobuf.Options, len(p.Options))
+	for name, value := range p.Options {
+		name, v, err := protobuf.ParseOption(name + "=" + value)
+		if err != nil {
+			return nil, fmt.Errorf("cannot patch %s: %s", p, err)
+		}
+		opts[name] = v
+	}
+	return opts, nil
+}
+
+func (p *Patch) String() string {
+	if p.Target == "" {
+		return p.Package
+	}
+	return p.Package + " " + p.Target
+}
+
+func merge(opts, patch protobuf.Options) protobuf.Options {
+	if len(patch) == 0 {
+		return opts
+	}
+
+	if opts == nil {
+		opts = make(protobuf.Options, len(patch))
+	}
+
+	for name, v := range patch {
+		opts[name] = v
+	}
+	return opts
+}
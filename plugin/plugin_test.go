@@ -0,0 +1,183 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+)
+
+// pluginEnv makes the test binary behave as a plugin that returns the
+// response in the variable.
+const pluginEnv = "PROTEUS_TEST_PLUGIN_RESPONSE"
+
+func TestMain(m *testing.M) {
+	if resp, ok := os.LookupEnv(pluginEnv); ok {
+		var req Request
+		if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil || req.Version != Version {
+			fmt.Fprintln(os.Stderr, "invalid request")
+			os.Exit(1)
+		}
+		fmt.Print(resp)
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+// installPlugin installs the test binary as the plugin named "fake" and
+// makes it return the given response.
+func installPlugin(t *testing.T, resp string) func() {
+	dir, err := ioutil.TempDir("", "proteus")
+	require.Nil(t, err)
+
+	exe, err := filepath.Abs(os.Args[0])
+	require.Nil(t, err)
+	require.Nil(t, os.Symlink(exe, filepath.Join(dir, Prefix+"fake")))
+
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+	os.Setenv(pluginEnv, resp)
+
+	return func() {
+		os.Setenv("PATH", path)
+		os.Unsetenv(pluginEnv)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestRun(t *testing.T) {
+	defer installPlugin(t, `{"files": [{"name": "foo/bar.txt", "content": "bar"}]}`)()
+
+	resp, err := Run("fake", &Request{Version: Version, Plugin: "fake"})
+	require.Nil(t, err)
+	require.Equal(t, []*File{{Name: "foo/bar.txt", Content: "bar"}}, resp.Files)
+}
+
+func TestRunError(t *testing.T) {
+	defer installPlugin(t, `{"error": "oops"}`)()
+
+	_, err := Run("fake", &Request{Version: Version})
+	require.EqualError(t, err, "plugin fake failed: oops")
+
+	_, err = Run("fake", &Request{Version: 0})
+	require.NotNil(t, err, "the plugin exits with an error")
+
+	_, err = Run("missing", &Request{Version: Version})
+	require.NotNil(t, err)
+}
+
+func TestRunInvalidResponse(t *testing.T) {
+	defer installPlugin(t, `not json`)()
+
+	_, err := Run("fake", &Request{Version: Version})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "invalid response")
+}
+
+func TestWriteFiles(t *testing.T) {
+	path, err := ioutil.TempDir("", "proteus")
+	require.Nil(t, err)
+	defer os.RemoveAll(path)
+
+	resp := &Response{Files: []*File{{Name: "foo/bar.txt", Content: "bar"}}}
+	require.Nil(t, resp.WriteFiles(path))
+
+	data, err := ioutil.ReadFile(filepath.Join(path, "foo", "bar.txt"))
+	require.Nil(t, err)
+	require.Equal(t, "bar", string(data))
+
+	for _, name := range []string{"../bar.txt", "/bar.txt", "foo/../../bar.txt"} {
+		resp := &Response{Files: []*File{{Name: name}}}
+		require.NotNil(t, resp.WriteFiles(path), name)
+	}
+}
+
+func mockPackages() []*protobuf.Package {
+	user := protobuf.NewNamed("foo", "User")
+	return []*protobuf.Package{
+		{
+			Name: "foo",
+			Path: "example.com/foo",
+			Messages: []*protobuf.Message{
+				{
+					Name: "User",
+					Fields: []*protobuf.Field{
+						{Name: "name", Pos: 1, Type: protobuf.NewBasic("string")},
+						{Name: "friends", Pos: 2, Type: protobuf.NewMap(protobuf.NewBasic("string"), protobuf.NewNamed("foo", "User"))},
+						{Name: "id", Pos: 3, Type: protobuf.NewBasic("uint64"), Options: protobuf.Options{
+							"(gogoproto.customname)": protobuf.NewStringValue("ID"),
+						}},
+					},
+				},
+			},
+			Enums: []*protobuf.Enum{
+				{Name: "Status", Values: []*protobuf.EnumValue{{Name: "ACTIVE"}}},
+			},
+			RPCs: []*protobuf.RPC{
+				{Name: "GetUser", Input: protobuf.NewNamed("foo", "GetUserRequest"), Output: user},
+			},
+		},
+		{
+			Name: "bar",
+			Path: "example.com/bar",
+			Messages: []*protobuf.Message{
+				{
+					Name: "Group",
+					Fields: []*protobuf.Field{
+						{Name: "owner", Pos: 1, Type: protobuf.NewNamed("foo", "User")},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestApply(t *testing.T) {
+	pkgs := mockPackages()
+	resp := &Response{Patches: []*Patch{
+		{Package: "example.com/foo", Options: map[string]string{"go_package": `"foo"`}},
+		{Package: "example.com/foo", Target: "User", Options: map[string]string{"(gogoproto.equal)": "true"}},
+		{Package: "example.com/foo", Target: "User.name", Rename: "full_name"},
+		{Package: "example.com/foo", Target: "User.id", Rename: "user_id"},
+		{Package: "example.com/foo", Target: "Status.ACTIVE", Options: map[string]string{"deprecated": "true"}},
+		{Package: "example.com/foo", Target: "GetUser", Rename: "GetPerson"},
+	}}
+	require.Nil(t, resp.Apply(pkgs))
+
+	foo := pkgs[0]
+	require.Equal(t, protobuf.NewStringValue("foo"), foo.Options["go_package"])
+
+	user := foo.Messages[0]
+	require.Equal(t, protobuf.NewLiteralValue("true"), user.Options["(gogoproto.equal)"])
+	require.Equal(t, "full_name", user.Fields[0].Name)
+	require.Equal(t, protobuf.NewStringValue("Name"), user.Fields[0].Options["(gogoproto.customname)"], "renamed fields keep their Go name")
+	require.Equal(t, "user_id", user.Fields[2].Name)
+	require.Equal(t, protobuf.NewStringValue("ID"), user.Fields[2].Options["(gogoproto.customname)"])
+
+	require.Equal(t, protobuf.NewLiteralValue("true"), foo.Enums[0].Values[0].Options["deprecated"])
+	require.Equal(t, "GetPerson", foo.RPCs[0].Name)
+}
+
+func TestApplyErrors(t *testing.T) {
+	cases := []*Patch{
+		{Package: "example.com/baz"},
+		{Package: "example.com/foo", Rename: "baz"},
+		{Package: "example.com/foo", Target: "User", Rename: "Person"},
+		{Package: "example.com/foo", Target: "Status", Rename: "State"},
+		{Package: "example.com/foo", Target: "Nope"},
+		{Package: "example.com/foo", Target: "User.nope"},
+		{Package: "example.com/foo", Target: "User", Options: map[string]string{"": "true"}},
+	}
+
+	for _, p := range cases {
+		resp := &Response{Patches: []*Patch{p}}
+		require.NotNil(t, resp.Apply(mockPackages()), p.String())
+	}
+}
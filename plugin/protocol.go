@@ -0,0 +1,391 @@
+package plugin
+
+import (
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+// Version is the version of the protocol, sent in every request.
+const Version = 1
+
+// Request is the JSON document written to the stdin of a plugin, with the
+// packages being generated.
+type Request struct {
+	// Version is the version of the protocol.
+	Version int `json:"version"`
+	// Plugin is the name the plugin was run with.
+	Plugin   string     `json:"plugin"`
+	Packages []*Package `json:"packages"`
+}
+
+// Response is the JSON document a plugin writes to its stdout.
+type Response struct {
+	// Error is the error of the plugin, if any. The generation fails if it
+	// is not empty.
+	Error string `json:"error,omitempty"`
+	// Files are the files generated by the plugin.
+	Files []*File `json:"files,omitempty"`
+	// Patches are the modifications of the protobuf packages, which are
+	// applied before generating them.
+	Patches []*Patch `json:"patches,omitempty"`
+}
+
+// File is a file generated by a plugin.
+type File struct {
+	// Name is the path of the file, relative to the output folder.
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// Patch is a modification of an element of a protobuf package.
+type Patch struct {
+	// Package is the Go import path of the package.
+	Package string `json:"package"`
+	// Target is the element of the package to modify: a message, enum or
+	// RPC by name, a field of a message or a value of an enum, e.g.
+	// "User.name" or "Status.ACTIVE", or the package itself if it is empty.
+	Target string `json:"target,omitempty"`
+	// Rename is the new name of the element, if not empty. Renamed fields
+	// keep their Go name with the gogoproto.customname option. Messages and
+	// enums cannot be renamed, as the Go code generated by protoc uses their
+	// Go types, which keep their names.
+	Rename string `json:"rename,omitempty"`
+	// Options are the options to set in the element, by name, with the same
+	// syntax as the values of the //proteus:option directive.
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// Package is a package being generated, with both its Go and protobuf
+// representations.
+type Package struct {
+	// Path is the Go import path of the package.
+	Path  string        `json:"path"`
+	Go    *GoPackage    `json:"go"`
+	Proto *ProtoPackage `json:"proto"`
+}
+
+// GoPackage is the scanned and resolved Go package.
+type GoPackage struct {
+	Name       string              `json:"name"`
+	Directives map[string][]string `json:"directives,omitempty"`
+	Structs    []*Struct           `json:"structs,omitempty"`
+	Enums      []*GoEnum           `json:"enums,omitempty"`
+	Funcs      []*Func             `json:"funcs,omitempty"`
+}
+
+// Docs are the documentation and the directives of a Go entity.
+type Docs struct {
+	Doc        []string            `json:"doc,omitempty"`
+	Directives map[string][]string `json:"directives,omitempty"`
+}
+
+// Struct is a Go struct.
+type Struct struct {
+	Docs
+	Name   string     `json:"name"`
+	Fields []*GoField `json:"fields,omitempty"`
+}
+
+// GoField is a field of a Go struct.
+type GoField struct {
+	Docs
+	Name string `json:"name"`
+	Type *Type  `json:"type"`
+	Tag  string `json:"tag,omitempty"`
+}
+
+// GoEnum is a Go type used as an enum.
+type GoEnum struct {
+	Docs
+	Name string `json:"name"`
+	// Underlying is the name of the basic type the enum is declared as.
+	Underlying string     `json:"underlying,omitempty"`
+	Values     []*GoValue `json:"values,omitempty"`
+}
+
+// GoValue is a value of a Go enum.
+type GoValue struct {
+	Docs
+	Name string `json:"name"`
+}
+
+// Func is a Go function or method.
+type Func struct {
+	Docs
+	Name string `json:"name"`
+	// Receiver is the receiver of the method, if it is a method.
+	Receiver *Type   `json:"receiver,omitempty"`
+	Input    []*Type `json:"input,omitempty"`
+	Output   []*Type `json:"output,omitempty"`
+	Variadic bool    `json:"variadic,omitempty"`
+}
+
+// ProtoPackage is the protobuf package of a Go package.
+type ProtoPackage struct {
+	Name     string            `json:"name"`
+	Imports  []string          `json:"imports,omitempty"`
+	Options  map[string]string `json:"options,omitempty"`
+	Messages []*Message        `json:"messages,omitempty"`
+	Enums    []*Enum           `json:"enums,omitempty"`
+	RPCs     []*RPC            `json:"rpcs,omitempty"`
+}
+
+// Message is a protobuf message.
+type Message struct {
+	Docs     []string          `json:"docs,omitempty"`
+	Name     string            `json:"name"`
+	Reserved []uint            `json:"reserved,omitempty"`
+	Options  map[string]string `json:"options,omitempty"`
+	Fields   []*Field          `json:"fields,omitempty"`
+}
+
+// Field is a field of a protobuf message.
+type Field struct {
+	Docs     []string          `json:"docs,omitempty"`
+	Name     string            `json:"name"`
+	Number   int               `json:"number"`
+	Repeated bool              `json:"repeated,omitempty"`
+	Type     *Type             `json:"type"`
+	Options  map[string]string `json:"options,omitempty"`
+}
+
+// Enum is a protobuf enum.
+type Enum struct {
+	Docs    []string          `json:"docs,omitempty"`
+	Name    string            `json:"name"`
+	Options map[string]string `json:"options,omitempty"`
+	Values  []*EnumValue      `json:"values,omitempty"`
+}
+
+// EnumValue is a value of a protobuf enum.
+type EnumValue struct {
+	Docs    []string          `json:"docs,omitempty"`
+	Name    string            `json:"name"`
+	Number  uint              `json:"number"`
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// RPC is a RPC of the service of a protobuf package.
+type RPC struct {
+	Docs    []string          `json:"docs,omitempty"`
+	Name    string            `json:"name"`
+	Input   *Type             `json:"input"`
+	Output  *Type             `json:"output"`
+	Options map[string]string `json:"options,omitempty"`
+	// Receiver is the name of the receiver of the Go method, if it is a
+	// method.
+	Receiver string `json:"receiver,omitempty"`
+	// Method is the name of the Go function or method.
+	Method   string `json:"method"`
+	HasError bool   `json:"hasError,omitempty"`
+}
+
+// Kinds of types.
+const (
+	Basic = "basic"
+	Named = "named"
+	Map   = "map"
+	Alias = "alias"
+)
+
+// Type is a Go or protobuf type.
+type Type struct {
+	// Kind is the kind of type: basic, named, map or alias.
+	Kind string `json:"kind"`
+	// Name is the name of basic, named and alias types.
+	Name string `json:"name,omitempty"`
+	// Package is the package of named and alias types: the import path of
+	// Go types or the package of protobuf types.
+	Package string `json:"package,omitempty"`
+	// Repeated reports whether a Go type is a slice or an array.
+	Repeated bool `json:"repeated,omitempty"`
+	// Nullable reports whether the type can be null.
+	Nullable bool `json:"nullable,omitempty"`
+	// Generated reports whether a protobuf named type is a message generated
+	// by proteus for the requests and responses of the RPCs.
+	Generated bool `json:"generated,omitempty"`
+	// Key and Value are the types of the keys and values of maps.
+	Key   *Type `json:"key,omitempty"`
+	Value *Type `json:"value,omitempty"`
+	// Underlying is the type an alias type is declared as.
+	Underlying *Type `json:"underlying,omitempty"`
+}
+
+// NewRequest creates the request for the plugin with the given name from
+// the scanned packages and their protobuf packages, in the same order.
+func NewRequest(plugin string, scanned []*scanner.Package, pkgs []*protobuf.Package) *Request {
+	req := &Request{Version: Version, Plugin: plugin}
+	for i, p := range scanned {
+		req.Packages = append(req.Packages, &Package{
+			Path:  p.Path,
+			Go:    newGoPackage(p),
+			Proto: newProtoPackage(pkgs[i]),
+		})
+	}
+	return req
+}
+
+func newDocs(d scanner.Docs) Docs {
+	return Docs{Doc: d.Doc, Directives: d.Directives}
+}
+
+func newGoPackage(p *scanner.Package) *GoPackage {
+	pkg := &GoPackage{Name: p.Name, Directives: p.Directives}
+	for _, s := range p.Structs {
+		st := &Struct{Docs: newDocs(s.Docs), Name: s.Name}
+		for _, f := range s.Fields {
+			st.Fields = append(st.Fields, &GoField{
+				Docs: newDocs(f.Docs),
+				Name: f.Name,
+				Type: goType(f.Type),
+				Tag:  string(f.StructTag),
+			})
+		}
+		pkg.Structs = append(pkg.Structs, st)
+	}
+
+	for _, e := range p.Enums {
+		enum := &GoEnum{Docs: newDocs(e.Docs), Name: e.Name, Underlying: e.Underlying}
+		for _, v := range e.Values {
+			enum.Values = append(enum.Values, &GoValue{Docs: newDocs(v.Docs), Name: v.Name})
+		}
+		pkg.Enums = append(pkg.Enums, enum)
+	}
+
+	for _, f := range p.Funcs {
+		fn := &Func{
+			Docs:     newDocs(f.Docs),
+			Name:     f.Name,
+			Variadic: f.IsVariadic,
+		}
+		if f.Receiver != nil {
+			fn.Receiver = goType(f.Receiver)
+		}
+		for _, t := range f.Input {
+			fn.Input = append(fn.Input, goType(t))
+		}
+		for _, t := range f.Output {
+			fn.Output = append(fn.Output, goType(t))
+		}
+		pkg.Funcs = append(pkg.Funcs, fn)
+	}
+
+	return pkg
+}
+
+func goType(typ scanner.Type) *Type {
+	switch t := typ.(type) {
+	case *scanner.Basic:
+		// Basic types report that they are nullable, as they are in
+		// protobuf, so the pointer is taken from the base type.
+		var nullable bool
+		if t.BaseType != nil {
+			nullable = t.BaseType.Nullable
+		}
+		return &Type{Kind: Basic, Name: t.Name, Repeated: t.IsRepeated(), Nullable: nullable}
+	case *scanner.Named:
+		return &Type{Kind: Named, Name: t.Name, Package: t.Path, Repeated: t.IsRepeated(), Nullable: t.IsNullable()}
+	case *scanner.Map:
+		return &Type{Kind: Map, Key: goType(t.Key), Value: goType(t.Value), Repeated: t.IsRepeated(), Nullable: t.IsNullable()}
+	case *scanner.Alias:
+		alias := goType(t.Type)
+		alias.Kind = Alias
+		alias.Repeated = t.IsRepeated()
+		alias.Nullable = t.IsNullable()
+		alias.Underlying = goType(t.Underlying)
+		return alias
+	}
+
+	return nil
+}
+
+func newProtoPackage(p *protobuf.Package) *ProtoPackage {
+	pkg := &ProtoPackage{
+		Name:    p.Name,
+		Imports: p.Imports,
+		Options: options(p.Options),
+	}
+
+	for _, m := range p.Messages {
+		msg := &Message{
+			Docs:     m.Docs,
+			Name:     m.Name,
+			Reserved: m.Reserved,
+			Options:  options(m.Options),
+		}
+		for _, f := range m.Fields {
+			msg.Fields = append(msg.Fields, &Field{
+				Docs:     f.Docs,
+				Name:     f.Name,
+				Number:   f.Pos,
+				Repeated: f.Repeated,
+				Type:     protoType(f.Type),
+				Options:  options(f.Options),
+			})
+		}
+		pkg.Messages = append(pkg.Messages, msg)
+	}
+
+	for _, e := range p.Enums {
+		enum := &Enum{Docs: e.Docs, Name: e.Name, Options: options(e.Options)}
+		for _, v := range e.Values {
+			enum.Values = append(enum.Values, &EnumValue{
+				Docs:    v.Docs,
+				Name:    v.Name,
+				Number:  v.Value,
+				Options: options(v.Options),
+			})
+		}
+		pkg.Enums = append(pkg.Enums, enum)
+	}
+
+	for _, r := range p.RPCs {
+		pkg.RPCs = append(pkg.RPCs, &RPC{
+			Docs:     r.Docs,
+			Name:     r.Name,
+			Input:    protoType(r.Input),
+			Output:   protoType(r.Output),
+			Options:  options(r.Options),
+			Receiver: r.Recv,
+			Method:   r.Method,
+			HasError: r.HasError,
+		})
+	}
+
+	return pkg
+}
+
+func protoType(typ protobuf.Type) *Type {
+	switch t := typ.(type) {
+	case *protobuf.Basic:
+		return &Type{Kind: Basic, Name: t.Name}
+	case *protobuf.Named:
+		return &Type{Kind: Named, Name: t.Name, Package: t.Package, Nullable: t.IsNullable(), Generated: t.Generated}
+	case *protobuf.Map:
+		return &Type{Kind: Map, Key: protoType(t.Key), Value: protoType(t.Value)}
+	case *protobuf.Alias:
+		alias := protoType(t.Type)
+		if alias == nil {
+			alias = &Type{}
+		}
+		alias.Kind = Alias
+		alias.Underlying = protoType(t.Underlying)
+		return alias
+	}
+
+	return nil
+}
+
+// options returns the options with their values in the syntax of the proto
+// files, so string values are quoted.
+func options(opts protobuf.Options) map[string]string {
+	if len(opts) == 0 {
+		return nil
+	}
+
+	m := make(map[string]string, len(opts))
+	for name, v := range opts {
+		m[name] = v.String()
+	}
+	return m
+}
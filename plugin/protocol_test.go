@@ -0,0 +1,126 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+func TestNewRequest(t *testing.T) {
+	ptr := scanner.NewNamed("example.com/foo", "Address")
+	ptr.SetNullable(true)
+	tags := scanner.NewBasic("string")
+	tags.SetRepeated(true)
+
+	scanned := []*scanner.Package{
+		{
+			Path:       "example.com/foo",
+			Name:       "foo",
+			Directives: scanner.Directives{"option": {"go_package=foo"}},
+			Structs: []*scanner.Struct{
+				{
+					Docs: scanner.Docs{Doc: []string{"User is an user."}},
+					Name: "User",
+					Fields: []*scanner.Field{
+						{Name: "Address", Type: ptr, StructTag: `json:"address"`},
+						{Name: "Tags", Type: tags},
+						{Name: "Labels", Type: scanner.NewMap(scanner.NewBasic("string"), scanner.NewBasic("int"))},
+					},
+				},
+			},
+			Enums: []*scanner.Enum{
+				{Name: "Status", Underlying: "byte", Values: []*scanner.EnumValue{{Name: "Active"}}},
+			},
+			Funcs: []*scanner.Func{
+				{
+					Name:     "Get",
+					Receiver: scanner.NewNamed("example.com/foo", "Store"),
+					Input:    []scanner.Type{scanner.NewBasic("int")},
+					Output:   []scanner.Type{ptr, scanner.NewNamed("", "error")},
+				},
+			},
+		},
+	}
+
+	pkgs := []*protobuf.Package{
+		{
+			Name:    "foo",
+			Path:    "example.com/foo",
+			Options: protobuf.Options{"go_package": protobuf.NewStringValue("foo")},
+			Messages: []*protobuf.Message{
+				{
+					Name: "User",
+					Fields: []*protobuf.Field{
+						{Name: "address", Pos: 1, Type: protobuf.NewNamed("foo", "Address")},
+						{Name: "labels", Pos: 3, Type: protobuf.NewMap(protobuf.NewBasic("string"), protobuf.NewBasic("int64"))},
+					},
+				},
+			},
+			Enums: []*protobuf.Enum{
+				{Name: "Status", Values: []*protobuf.EnumValue{{Name: "ACTIVE", Value: 0}}},
+			},
+			RPCs: []*protobuf.RPC{
+				{
+					Name:     "Store_Get",
+					Recv:     "Store",
+					Method:   "Get",
+					HasError: true,
+					Input:    protobuf.NewGeneratedNamed("foo", "Store_GetRequest"),
+					Output:   protobuf.NewNamed("foo", "Address"),
+				},
+			},
+		},
+	}
+
+	req := NewRequest("test", scanned, pkgs)
+	require.Equal(t, Version, req.Version)
+	require.Equal(t, "test", req.Plugin)
+	require.Len(t, req.Packages, 1)
+
+	pkg := req.Packages[0]
+	require.Equal(t, "example.com/foo", pkg.Path)
+	require.Equal(t, map[string][]string{"option": {"go_package=foo"}}, pkg.Go.Directives)
+
+	user := pkg.Go.Structs[0]
+	require.Equal(t, []string{"User is an user."}, user.Doc)
+	require.Equal(t, &GoField{
+		Name: "Address",
+		Type: &Type{Kind: Named, Name: "Address", Package: "example.com/foo", Nullable: true},
+		Tag:  `json:"address"`,
+	}, user.Fields[0])
+	require.Equal(t, &Type{Kind: Basic, Name: "string", Repeated: true}, user.Fields[1].Type)
+	require.Equal(t, &Type{
+		Kind:  Map,
+		Key:   &Type{Kind: Basic, Name: "string"},
+		Value: &Type{Kind: Basic, Name: "int"},
+	}, user.Fields[2].Type)
+
+	require.Equal(t, &GoEnum{Name: "Status", Underlying: "byte", Values: []*GoValue{{Name: "Active"}}}, pkg.Go.Enums[0])
+	require.Equal(t, "Store", pkg.Go.Funcs[0].Receiver.Name)
+	require.Len(t, pkg.Go.Funcs[0].Output, 2)
+
+	proto := pkg.Proto
+	require.Equal(t, "foo", proto.Name)
+	require.Equal(t, map[string]string{"go_package": `"foo"`}, proto.Options)
+	require.Equal(t, &Field{
+		Name:   "labels",
+		Number: 3,
+		Type: &Type{
+			Kind:  Map,
+			Key:   &Type{Kind: Basic, Name: "string"},
+			Value: &Type{Kind: Basic, Name: "int64"},
+		},
+	}, proto.Messages[0].Fields[1])
+	require.Equal(t, &EnumValue{Name: "ACTIVE"}, proto.Enums[0].Values[0])
+	require.Equal(t, &RPC{
+		Name:     "Store_Get",
+		Input:    &Type{Kind: Named, Name: "Store_GetRequest", Package: "foo", Nullable: true, Generated: true},
+		Output:   &Type{Kind: Named, Name: "Address", Package: "foo", Nullable: true},
+		Receiver: "Store",
+		Method:   "Get",
+		HasError: true,
+	}, proto.RPCs[0])
+}
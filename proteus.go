@@ -1,9 +1,13 @@
 package proteus
 
 import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/src-d/proteus.v1/plugin"
 	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/report"
 	"gopkg.in/src-d/proteus.v1/resolver"
-	"gopkg.in/src-d/proteus.v1/rpc"
 	"gopkg.in/src-d/proteus.v1/scanner"
 )
 
@@ -11,30 +15,201 @@ import (
 type Options struct {
 	BasePath string
 	Packages []string
+	// NoStdlibTypes disables the built-in mappings for standard library types
+	// such as net.IP and for the wrappers of the stdtypes package, such as
+	// stdtypes.URL or stdtypes.BigInt.
+	NoStdlibTypes bool
+	// Mappings are the custom mappings of Go types to protobuf types, which
+	// take precedence over the built-in ones. Their types are allowed by
+	// the resolver even if their packages are not scanned.
+	Mappings protobuf.TypeMappings
+	// PackageMappings are the custom mappings only used in the package with
+	// the given path, which take precedence over Mappings. Their types are
+	// allowed by the resolver, but they must have a mapping in every package
+	// using them.
+	PackageMappings map[string]protobuf.TypeMappings
+	// AllowedTypes are the types of packages that are not scanned whose
+	// fields are kept anyway, such as "github.com/google/uuid.UUID", or
+	// patterns of them, such as "*.ID". They must have a mapping.
+	AllowedTypes []string
+	// AllowedPackages are the paths of packages that are not scanned whose
+	// types are all allowed, as if they were in AllowedTypes.
+	AllowedPackages []string
+	// ProtoPaths are the folders where the proto files imported by the
+	// mappings and by the types that are existing protobuf messages, with the
+	// `//proteus:proto` directive, are found to check that the messages
+	// exist, besides BasePath, the src folders of the GOPATH and the current
+	// folder.
+	ProtoPaths []string
+	// Follow loads the packages that are not scanned but have types used by
+	// the generated ones, and generates only those types, instead of
+	// ignoring the fields using them. Only the packages in FollowPrefixes
+	// are loaded or, if it is empty, the ones in the same module or
+	// repository as the scanned packages.
+	Follow bool
+	// FollowPrefixes are the prefixes of the paths of the packages that can
+	// be loaded with Follow.
+	FollowPrefixes []string
+	// FollowDepth is the maximum number of packages followed from a scanned
+	// package to reach a type. If it is 0, resolver.DefaultFollowDepth is
+	// used.
+	FollowDepth int
+	// TimeFormat is the default representation of time.Time. If empty, the
+	// google.protobuf.Timestamp well-known type is used.
+	TimeFormat protobuf.TimeFormat
+	// DurationFormat is the default representation of time.Duration. If
+	// empty, the google.protobuf.Duration well-known type is used.
+	DurationFormat protobuf.TimeFormat
+	// Validation is the set of validation rules the validate struct tags are
+	// translated to. If empty, validate struct tags are ignored.
+	Validation protobuf.Validation
+	// ValidateRequests makes the generated RPC server validate the requests
+	// with their validate struct tags before calling the methods.
+	ValidateRequests bool
+	// APIVersion is the version of the API in the generated API documents.
+	// If empty, openapi.DefaultAPIVersion is used.
+	APIVersion string
+	// CheckCompatibility makes the generation of Avro schemas fail if they
+	// cannot read the data written with the schemas already generated.
+	CheckCompatibility bool
+	// Plugins are the names of the plugins that can modify the protobuf
+	// packages or generate additional files before the packages are
+	// generated. Their proteus-gen-NAME executables must be in the PATH. See
+	// the plugin package.
+	Plugins []string
+	// Hooks are run by the transformer for every message, field, enum, enum
+	// value and RPC of the packages, which they can modify or drop, when
+	// proteus is used as a library.
+	Hooks protobuf.Hooks
 }
 
-type generator func(*scanner.Package, *protobuf.Package) error
+// ProtobufGenerator generates the artifacts of a scanned package from its
+// protobuf representation.
+type ProtobufGenerator func(*scanner.Package, *protobuf.Package) error
 
-func transformToProtobuf(packages []string, generate generator) error {
-	scanner, err := scanner.New(packages...)
+// scanPackages scans and resolves the packages in the given options.
+func scanPackages(options Options) ([]*scanner.Package, error) {
+	pkgs, r, err := loadPackages(options)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	r.Resolve(pkgs)
+
+	if err := checkAllowedTypes(options, pkgs); err != nil {
+		return nil, err
+	}
+
+	return pkgs, nil
+}
+
+// PackagePaths returns the paths of the packages whose artifacts are
+// generated with the given options: the packages in the options and, with
+// Follow, the packages they follow.
+func PackagePaths(options Options) ([]string, error) {
+	if !options.Follow {
+		return options.Packages, nil
+	}
+
+	pkgs, _, err := loadPackages(options)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, len(pkgs))
+	for i, p := range pkgs {
+		paths[i] = p.Path
+	}
+	return paths, nil
+}
+
+// loadPackages scans the packages in the given options, and the packages
+// they follow, and returns them along with the resolver configured to
+// resolve them.
+func loadPackages(options Options) ([]*scanner.Package, *resolver.Resolver, error) {
+	scanner, err := scanner.New(options.Packages...)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	pkgs, err := scanner.Scan()
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	r := resolver.New()
-	r.Resolve(pkgs)
+	if options.NoStdlibTypes {
+		r.DisableStdlibTypes()
+	}
+	if err := allowTypes(options, r); err != nil {
+		return nil, nil, err
+	}
+	if err := referenceProtos(options, r, pkgs); err != nil {
+		return nil, nil, err
+	}
 
-	t := protobuf.NewTransformer()
-	t.SetStructSet(createStructTypeSet(pkgs))
-	t.SetEnumSet(createEnumTypeSet(pkgs))
-	for _, p := range pkgs {
-		pkg := t.Transform(p)
-		if err := generate(p, pkg); err != nil {
+	if options.Follow {
+		pkgs = followPackages(options, r, pkgs)
+	}
+
+	return pkgs, r, nil
+}
+
+// followPackages loads the packages with types used by the given packages
+// and reports what was loaded.
+func followPackages(options Options, r *resolver.Resolver, pkgs []*scanner.Package) []*scanner.Package {
+	prefixes := options.FollowPrefixes
+	if len(prefixes) == 0 {
+		for _, p := range options.Packages {
+			if root := scanner.ModuleRoot(p); root != "" {
+				prefixes = append(prefixes, root)
+			}
+		}
+	}
+
+	pkgs, followed := r.Follow(pkgs, prefixes, options.FollowDepth)
+	for _, f := range followed {
+		types := strings.Join(f.Types, ", ")
+		if types == "" {
+			types = "no types, only its type declarations are used"
+		}
+
+		report.Notice(
+			"followed package %s (depth %d), used by %s: generating %s",
+			f.Path, f.Depth, strings.Join(f.By, ", "), types,
+		)
+	}
+
+	return pkgs
+}
+
+// TransformToProtobuf transforms the given resolved packages to protobuf
+// packages, configuring the transformer with the given options, runs the
+// plugins in the options on them and calls generate with every one of them.
+// It can be used by the backends that generate their artifacts from the
+// protobuf representation.
+func TransformToProtobuf(options Options, pkgs []*scanner.Package, generate ProtobufGenerator) error {
+	protos, err := transformPackages(options, pkgs)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range options.Plugins {
+		resp, err := plugin.Run(name, plugin.NewRequest(name, pkgs, protos))
+		if err != nil {
+			return err
+		}
+
+		if err := resp.Apply(protos); err != nil {
+			return fmt.Errorf("plugin %s: %s", name, err)
+		}
+
+		if err := resp.WriteFiles(options.BasePath); err != nil {
+			return fmt.Errorf("plugin %s: %s", name, err)
+		}
+	}
+
+	for i, p := range pkgs {
+		if err := generate(p, protos[i]); err != nil {
 			return err
 		}
 	}
@@ -42,6 +217,49 @@ func transformToProtobuf(packages []string, generate generator) error {
 	return nil
 }
 
+// transformPackages transforms the given packages to protobuf packages. The
+// types that are existing protobuf messages are mapped to them, unless they
+// have a custom mapping in the options.
+func transformPackages(options Options, pkgs []*scanner.Package) ([]*protobuf.Package, error) {
+	mappings, err := protoMappings(pkgs)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, typ := range options.Mappings {
+		mappings[name] = typ
+	}
+
+	t := protobuf.NewTransformer()
+	t.SetMappings(mappings)
+	for path, m := range options.PackageMappings {
+		t.SetPackageMappings(path, m)
+	}
+	if options.NoStdlibTypes {
+		t.DisableStdlibMappings()
+	}
+	if options.TimeFormat != "" {
+		t.SetTimeFormat(options.TimeFormat)
+	}
+	if options.DurationFormat != "" {
+		t.SetDurationFormat(options.DurationFormat)
+	}
+	t.SetValidation(options.Validation)
+	t.SetStructSet(createStructTypeSet(pkgs))
+	t.SetEnumSet(createEnumTypeSet(pkgs))
+	t.AddHooks(options.Hooks)
+
+	protos := make([]*protobuf.Package, len(pkgs))
+	for i, p := range pkgs {
+		protos[i] = t.Transform(p)
+	}
+	if err := t.Err(); err != nil {
+		return nil, err
+	}
+
+	return protos, nil
+}
+
 func createStructTypeSet(pkgs []*scanner.Package) protobuf.TypeSet {
 	ts := protobuf.NewTypeSet()
 	for _, p := range pkgs {
@@ -64,17 +282,26 @@ func createEnumTypeSet(pkgs []*scanner.Package) protobuf.TypeSet {
 
 // GenerateProtos generates proto files for the given options.
 func GenerateProtos(options Options) error {
-	g := protobuf.NewGenerator(options.BasePath)
-	return transformToProtobuf(options.Packages, func(_ *scanner.Package, pkg *protobuf.Package) error {
-		return g.Generate(pkg)
-	})
+	return Generate("proto", options)
 }
 
 // GenerateRPCServer generates the gRPC server implementation of the given
 // packages.
 func GenerateRPCServer(packages []string) error {
-	g := rpc.NewGenerator()
-	return transformToProtobuf(packages, func(p *scanner.Package, pkg *protobuf.Package) error {
-		return g.Generate(pkg, p.Path)
-	})
+	return GenerateRPCServerWithOptions(Options{Packages: packages})
+}
+
+// GenerateRPCServerWithOptions generates the gRPC server implementation of the
+// packages in the given options. BasePath is ignored, as the implementation
+// is written to the package itself.
+func GenerateRPCServerWithOptions(options Options) error {
+	return Generate("rpc", options)
+}
+
+// GenerateGlue generates the Go code needed by the code generated by protoc
+// for the packages in the given options, such as the custom type methods of
+// the byte arrays. BasePath is ignored, as the code is written to the package
+// itself. See the glue package.
+func GenerateGlue(options Options) error {
+	return Generate("glue", options)
 }
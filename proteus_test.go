@@ -0,0 +1,156 @@
+package proteus
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/report"
+)
+
+func TestScanPackagesFollow(t *testing.T) {
+	report.TestMode()
+	defer report.EndTestMode()
+
+	pkgs, err := scanPackages(Options{
+		Packages:       []string{fixturesPkg},
+		Follow:         true,
+		FollowPrefixes: []string{fixturesPkg},
+	})
+	require.NoError(t, err)
+	require.Len(t, pkgs, 2)
+	require.NotNil(t, findField(pkgs[0], "Saz", "Point"))
+
+	require.Equal(t, subpkg, pkgs[1].Path)
+	require.Len(t, pkgs[1].Structs, 1)
+	require.Equal(t, "Point", pkgs[1].Structs[0].Name)
+	require.Len(t, pkgs[1].Funcs, 0)
+
+	require.Contains(t, report.MessageStack(), "NOTICE: followed package "+subpkg+" (depth 1), used by "+fixturesPkg+".Saz: generating "+subpkg+".Point")
+}
+
+func TestPackagePaths(t *testing.T) {
+	report.TestMode()
+	defer report.EndTestMode()
+
+	options := Options{Packages: []string{fixturesPkg}, FollowPrefixes: []string{fixturesPkg}}
+	paths, err := PackagePaths(options)
+	require.NoError(t, err)
+	require.Equal(t, []string{fixturesPkg}, paths)
+
+	options.Follow = true
+	paths, err = PackagePaths(options)
+	require.NoError(t, err)
+	require.Equal(t, []string{fixturesPkg, subpkg}, paths, "followed packages are generated too")
+}
+
+const genericsPkg = fixturesPkg + "/generics"
+
+const genericsFixture = `package generics
+
+import "gopkg.in/src-d/proteus.v1/stdtypes"
+
+type Pair[K any] struct {
+	Key K
+}
+
+//proteus:generate
+type Row struct {
+	Name  *stdtypes.NullOfString
+	Count *stdtypes.Null[int64]
+	Pair  Pair[string]
+}
+`
+
+func TestTransformGenericTypes(t *testing.T) {
+	require := require.New(t)
+	report.TestMode()
+	defer report.EndTestMode()
+
+	pkg := filepath.Join(os.Getenv("GOPATH"), "src", genericsPkg)
+	require.NoError(os.MkdirAll(pkg, 0755))
+	defer os.RemoveAll(pkg)
+	require.NoError(ioutil.WriteFile(filepath.Join(pkg, "generics.go"), []byte(genericsFixture), 0644))
+
+	options := Options{Packages: []string{genericsPkg}}
+	pkgs, err := scanPackages(options)
+	require.NoError(err)
+
+	protos, err := transformPackages(options, pkgs)
+	require.NoError(err)
+
+	var row, pair *protobuf.Message
+	for _, msg := range protos[0].Messages {
+		switch msg.Name {
+		case "Row":
+			row = msg
+		case "Pair":
+			pair = msg
+		}
+	}
+	require.NotNil(row)
+	require.NotNil(pair, "generic structs used by generated structs are generated")
+
+	types := make(map[string]string)
+	customTypes := make(map[string]string)
+	for _, f := range row.Fields {
+		types[f.Name] = f.Type.String()
+		if v, ok := f.Options["(gogoproto.customtype)"]; ok {
+			customTypes[f.Name] = v.String()
+		}
+	}
+
+	require.Equal(map[string]string{
+		"name":  "google.protobuf.StringValue",
+		"count": "google.protobuf.Int64Value",
+		"pair":  "gopkg.in.srcd.proteus.v1.fixtures.generics.Pair",
+	}, types)
+	require.Equal(map[string]string{
+		"name":  `"gopkg.in/src-d/proteus.v1/stdtypes.NullOfString"`,
+		"count": `"gopkg.in/src-d/proteus.v1/stdtypes.NullOfInt64"`,
+	}, customTypes)
+}
+
+const nullsPkg = fixturesPkg + "/nulls"
+
+const nullsFixture = `package nulls
+
+import (
+	"database/sql"
+
+	"gopkg.in/src-d/proteus.v1/stdtypes"
+)
+
+//proteus:generate
+type Row struct {
+	Label  sql.Null[string]
+	Legacy sql.NullString
+	Name   stdtypes.NullString
+	Valid  *stdtypes.NullString
+}
+`
+
+func TestTransformNullTypes(t *testing.T) {
+	require := require.New(t)
+	report.TestMode()
+	defer report.EndTestMode()
+
+	pkg := filepath.Join(os.Getenv("GOPATH"), "src", nullsPkg)
+	require.NoError(os.MkdirAll(pkg, 0755))
+	defer os.RemoveAll(pkg)
+	require.NoError(ioutil.WriteFile(filepath.Join(pkg, "nulls.go"), []byte(nullsFixture), 0644))
+
+	options := Options{Packages: []string{nullsPkg}}
+	pkgs, err := scanPackages(options)
+	require.NoError(err)
+
+	_, err = transformPackages(options, pkgs)
+	require.Error(err)
+	require.Equal(`field "Label" is a database/sql.Null[string], which cannot be used as a protobuf custom type, use *gopkg.in/src-d/proteus.v1/stdtypes.NullOfString instead
+field "Legacy" is a database/sql.NullString, which cannot be used as a protobuf custom type, use *gopkg.in/src-d/proteus.v1/stdtypes.NullString instead
+field "Name" must be a *gopkg.in/src-d/proteus.v1/stdtypes.NullString, a nil pointer is a NULL value and any other value is sent as valid`, err.Error())
+}
@@ -0,0 +1,65 @@
+package protobuf
+
+import (
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+// bytesType returns the bytes type if the Go type of the field is a byte
+// slice, a byte array or a type declaration of any of them, decorating the
+// field so the Go type is kept. Otherwise, it returns nil.
+// Type declarations of byte slices are cast to bytes, while type
+// declarations of byte arrays are used as custom types, whose methods check
+// the length of the array and are generated by the glue package. Unnamed
+// byte arrays cannot be custom types, so they are reported as errors, which
+// are returned by Err.
+func (t *Transformer) bytesType(pkg *Package, field *scanner.Field, f *Field) Type {
+	var typ Type
+	switch ty := field.Type.(type) {
+	case *scanner.Basic:
+		if !isByteSlice(ty) {
+			return nil
+		}
+
+		if n := ty.ArrayLen(); n > 0 {
+			t.addError(
+				"field %q is a [%d]byte, which cannot be cast to bytes, use a type declaration such as `type %s [%d]byte` instead",
+				field.Name, n, field.Name, n,
+			)
+		}
+
+		typ = NewBasic("bytes")
+	case *scanner.Alias:
+		if ty.Type.IsRepeated() || !isByteSlice(ty.Underlying) {
+			return nil
+		}
+
+		if f.Options == nil {
+			f.Options = make(Options)
+		}
+
+		goType := castType(pkg, ty.Type)
+		if IsByteArray(ty) {
+			f.Options["(gogoproto.customtype)"] = NewStringValue(goType)
+			if !ty.Type.IsNullable() {
+				f.Options["(gogoproto.nullable)"] = NewLiteralValue("false")
+			}
+		} else {
+			f.Options["(gogoproto.casttype)"] = NewStringValue(goType)
+		}
+
+		typ = NewBasic("bytes")
+	default:
+		return nil
+	}
+
+	typ.SetSource(field.Type)
+	f.Repeated = false
+	return typ
+}
+
+// IsByteArray reports whether the given type is a type declaration of a
+// fixed-size byte array, such as `type Hash [32]byte`.
+func IsByteArray(typ scanner.Type) bool {
+	alias, ok := typ.(*scanner.Alias)
+	return ok && !alias.Type.IsRepeated() && isByteSlice(alias.Underlying) && alias.ArrayLen() > 0
+}
@@ -0,0 +1,94 @@
+package protobuf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+func TestBytesType(t *testing.T) {
+	cases := []struct {
+		name    string
+		typ     scanner.Type
+		options Options
+	}{
+		{"ByteSlice", repeated(scanner.NewBasic("byte")), nil},
+		{
+			"ByteSliceDecl",
+			scanner.NewAlias(scanner.NewNamed("foo", "Token"), repeated(scanner.NewBasic("byte"))),
+			Options{"(gogoproto.casttype)": NewStringValue("Token")},
+		},
+		{
+			"ByteArrayDecl",
+			scanner.NewAlias(scanner.NewNamed("foo", "Hash"), array(scanner.NewBasic("byte"), 32)),
+			Options{
+				"(gogoproto.customtype)": NewStringValue("Hash"),
+				"(gogoproto.nullable)":   NewLiteralValue("false"),
+			},
+		},
+		{
+			"NullableByteArrayDecl",
+			scanner.NewAlias(nullable(scanner.NewNamed("foo", "Hash")), array(scanner.NewBasic("byte"), 32)),
+			Options{"(gogoproto.customtype)": NewStringValue("Hash")},
+		},
+		{
+			"ExternalByteArrayDecl",
+			scanner.NewAlias(scanner.NewNamed("bar", "Hash"), array(scanner.NewBasic("byte"), 32)),
+			Options{
+				"(gogoproto.customtype)": NewStringValue("bar.Hash"),
+				"(gogoproto.nullable)":   NewLiteralValue("false"),
+			},
+		},
+	}
+
+	tr := NewTransformer()
+	for _, c := range cases {
+		f := tr.transformField(&Package{Path: "foo"}, &Message{}, &scanner.Field{
+			Name: c.name,
+			Type: c.typ,
+		}, 1)
+
+		require.Equal(t, "bytes", f.Type.String(), c.name)
+		require.False(t, f.Repeated, c.name)
+		require.Equal(t, c.typ, f.Type.Source(), c.name)
+		for k, v := range c.options {
+			require.Equal(t, v, f.Options[k], "%s: %s", c.name, k)
+		}
+		if _, ok := c.options["(gogoproto.nullable)"]; !ok {
+			require.NotContains(t, f.Options, "(gogoproto.nullable)", c.name)
+		}
+	}
+	require.Nil(t, tr.Err())
+}
+
+func TestBytesTypeUnnamedArray(t *testing.T) {
+	report.TestMode()
+	defer report.EndTestMode()
+
+	tr := NewTransformer()
+	f := tr.transformField(&Package{Path: "foo"}, &Message{}, &scanner.Field{
+		Name: "Hash",
+		Type: array(scanner.NewBasic("byte"), 32),
+	}, 1)
+
+	require.Equal(t, "bytes", f.Type.String())
+	require.EqualError(t, tr.Err(), "field \"Hash\" is a [32]byte, which cannot be cast to bytes, use a type declaration such as `type Hash [32]byte` instead")
+}
+
+func TestIsByteArray(t *testing.T) {
+	byteArray := array(scanner.NewBasic("byte"), 4)
+	require.True(t, IsByteArray(scanner.NewAlias(scanner.NewNamed("foo", "Key"), byteArray)))
+	require.False(t, IsByteArray(byteArray))
+	require.False(t, IsByteArray(scanner.NewAlias(scanner.NewNamed("foo", "Key"), repeated(scanner.NewBasic("byte")))))
+	require.False(t, IsByteArray(scanner.NewAlias(scanner.NewNamed("foo", "Key"), array(scanner.NewBasic("int"), 4))))
+	require.False(t, IsByteArray(nil))
+}
+
+func array(t scanner.Type, len int64) scanner.Type {
+	t.SetRepeated(true)
+	t.SetArrayLen(len)
+	return t
+}
@@ -0,0 +1,197 @@
+package protobuf
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ImportEdge is a reference from a message or RPC of a package to a message
+// or enum of another package, which makes the proto file of the former
+// import the proto file of the latter.
+type ImportEdge struct {
+	// From and To are the Go paths of the packages.
+	From, To string
+	// By is the field or RPC with the reference, such as "field foo.Bar.baz"
+	// or "rpc foo.FooService.Bar".
+	By string
+	// Type is the full name of the referenced type.
+	Type string
+}
+
+func (e *ImportEdge) String() string {
+	return fmt.Sprintf("%s imports %s: %s uses %s", e.From, e.To, e.By, e.Type)
+}
+
+// ImportCycle is a set of packages whose proto files import each other,
+// which protoc rejects.
+type ImportCycle struct {
+	// Packages are the sorted Go paths of the packages.
+	Packages []string
+	// Edges are the references between the packages causing the cycle.
+	Edges []*ImportEdge
+}
+
+func (c *ImportCycle) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "import cycle between packages %s:", strings.Join(c.Packages, ", "))
+	for _, e := range c.Edges {
+		fmt.Fprintf(&buf, "\n  %s", e)
+	}
+	return buf.String()
+}
+
+// ImportGraph returns the references between the given packages that make
+// their proto files import each other, in the order of the packages and of
+// their messages, fields and RPCs.
+func ImportGraph(pkgs []*Package) []*ImportEdge {
+	paths := make(map[string]string)
+	for _, p := range pkgs {
+		paths[p.Name] = p.Path
+	}
+
+	var edges []*ImportEdge
+	for _, p := range pkgs {
+		forEachReference(p, true, func(by string, typ *Named) {
+			if to, ok := paths[typ.Package]; ok && typ.Package != p.Name {
+				edges = append(edges, &ImportEdge{From: p.Path, To: to, By: by, Type: typ.String()})
+			}
+		})
+	}
+
+	return edges
+}
+
+// ImportCycles returns the cycles of imports between the proto files of the
+// given packages. Every cycle contains all the packages importing each
+// other, directly or not, and the references between them.
+//
+// The imports between the packages of the scanned Go code cannot have
+// cycles, as Go does not allow them, but the custom mappings, the types that
+// are existing protobuf messages, hooks and plugins can reference the proto
+// files generated for other packages.
+func ImportCycles(pkgs []*Package) []*ImportCycle {
+	edges := ImportGraph(pkgs)
+	imports := make(map[string][]string)
+	for _, e := range edges {
+		imports[e.From] = append(imports[e.From], e.To)
+	}
+
+	var paths []string
+	for _, p := range pkgs {
+		paths = append(paths, p.Path)
+	}
+	sort.Strings(paths)
+
+	var cycles []*ImportCycle
+	for _, scc := range stronglyConnected(paths, imports) {
+		if len(scc) < 2 {
+			continue
+		}
+
+		sort.Strings(scc)
+		in := make(map[string]bool)
+		for _, p := range scc {
+			in[p] = true
+		}
+
+		c := &ImportCycle{Packages: scc}
+		for _, e := range edges {
+			if in[e.From] && in[e.To] {
+				c.Edges = append(c.Edges, e)
+			}
+		}
+		cycles = append(cycles, c)
+	}
+
+	return cycles
+}
+
+// stronglyConnected returns the strongly connected components of the graph
+// with the given nodes and edges, using Tarjan's algorithm.
+func stronglyConnected(nodes []string, edges map[string][]string) [][]string {
+	var (
+		index   = make(map[string]int)
+		lowlink = make(map[string]int)
+		onStack = make(map[string]bool)
+		stack   []string
+		result  [][]string
+		visit   func(string)
+	)
+
+	visit = func(n string) {
+		index[n] = len(index)
+		lowlink[n] = index[n]
+		stack = append(stack, n)
+		onStack[n] = true
+
+		for _, m := range edges[n] {
+			if _, ok := index[m]; !ok {
+				visit(m)
+				if lowlink[m] < lowlink[n] {
+					lowlink[n] = lowlink[m]
+				}
+			} else if onStack[m] && index[m] < lowlink[n] {
+				lowlink[n] = index[m]
+			}
+		}
+
+		if lowlink[n] == index[n] {
+			var scc []string
+			for {
+				m := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[m] = false
+				scc = append(scc, m)
+				if m == n {
+					break
+				}
+			}
+			result = append(result, scc)
+		}
+	}
+
+	for _, n := range nodes {
+		if _, ok := index[n]; !ok {
+			visit(n)
+		}
+	}
+
+	return result
+}
+
+// forEachReference calls fn with every named type used by the messages and
+// RPCs of the given package, along with the field or RPC using it. The type
+// declarations of aliases, which are imported but not used in the proto
+// files, are only included if declarations is true.
+func forEachReference(p *Package, declarations bool, fn func(by string, typ *Named)) {
+	for _, msg := range p.Messages {
+		for _, f := range msg.Fields {
+			by := fmt.Sprintf("field %s.%s.%s", p.Name, msg.Name, f.Name)
+			namedTypes(f.Type, declarations, func(typ *Named) { fn(by, typ) })
+		}
+	}
+
+	for _, rpc := range p.RPCs {
+		by := fmt.Sprintf("rpc %s.%s.%s", p.Name, p.ServiceName(), rpc.Name)
+		namedTypes(rpc.Input, declarations, func(typ *Named) { fn(by, typ) })
+		namedTypes(rpc.Output, declarations, func(typ *Named) { fn(by, typ) })
+	}
+}
+
+// namedTypes calls fn with every named type in the given type.
+func namedTypes(typ Type, declarations bool, fn func(*Named)) {
+	switch t := typ.(type) {
+	case *Named:
+		fn(t)
+	case *Map:
+		namedTypes(t.Key, declarations, fn)
+		namedTypes(t.Value, declarations, fn)
+	case *Alias:
+		if declarations {
+			namedTypes(t.Type, declarations, fn)
+		}
+		namedTypes(t.Underlying, declarations, fn)
+	}
+}
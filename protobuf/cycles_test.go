@@ -0,0 +1,94 @@
+package protobuf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func cyclePackages() []*Package {
+	return []*Package{
+		{
+			Name:    "a",
+			Path:    "github.com/org/a",
+			Imports: []string{"github.com/gogo/protobuf/gogoproto/gogo.proto", "github.com/org/b/generated.proto"},
+			Options: Options{"go_package": NewStringValue("a")},
+			Messages: []*Message{
+				{Name: "Order", Fields: []*Field{
+					{Name: "customer", Pos: 1, Type: NewNamed("b", "Customer")},
+					{Name: "status", Pos: 2, Type: NewNamed("a", "Status")},
+				}},
+				{Name: "Invoice", Fields: []*Field{
+					{Name: "order", Pos: 1, Type: NewNamed("a", "Order")},
+				}},
+			},
+			Enums: []*Enum{{Name: "Status"}},
+		},
+		{
+			Name: "b",
+			Path: "github.com/org/b",
+			Imports: []string{
+				"github.com/gogo/protobuf/gogoproto/gogo.proto",
+				"google/protobuf/timestamp.proto",
+				"github.com/org/a/generated.proto",
+			},
+			Options: Options{"go_package": NewStringValue("b")},
+			Messages: []*Message{
+				{Name: "Customer", Fields: []*Field{
+					{Name: "since", Pos: 1, Type: NewNamed("google.protobuf", "Timestamp")},
+					{Name: "tags", Pos: 2, Type: NewMap(NewBasic("string"), NewNamed("b", "Tag"))},
+				}},
+				{Name: "Tag"},
+				{Name: "BService_LastOrderRequest", Fields: []*Field{
+					{Name: "arg1", Pos: 1, Type: NewAlias(NewNamed("a", "ID"), NewBasic("string"))},
+				}},
+			},
+			RPCs: []*RPC{
+				{Name: "LastOrder", Input: NewGeneratedNamed("b", "BService_LastOrderRequest"), Output: NewNamed("a", "Order")},
+			},
+		},
+		{
+			Name:    "c",
+			Path:    "github.com/org/c",
+			Imports: []string{"github.com/org/a/generated.proto"},
+			Messages: []*Message{
+				{Name: "Report", Fields: []*Field{
+					{Name: "orders", Pos: 1, Type: NewNamed("a", "Order")},
+					{Name: "invoice", Pos: 2, Type: NewNamed("a", "Invoice")},
+				}},
+			},
+		},
+	}
+}
+
+func TestImportGraph(t *testing.T) {
+	var edges []string
+	for _, e := range ImportGraph(cyclePackages()) {
+		edges = append(edges, e.String())
+	}
+
+	require.Equal(t, []string{
+		"github.com/org/a imports github.com/org/b: field a.Order.customer uses b.Customer",
+		"github.com/org/b imports github.com/org/a: field b.BService_LastOrderRequest.arg1 uses a.ID",
+		"github.com/org/b imports github.com/org/a: rpc b.BService.LastOrder uses a.Order",
+		"github.com/org/c imports github.com/org/a: field c.Report.orders uses a.Order",
+		"github.com/org/c imports github.com/org/a: field c.Report.invoice uses a.Invoice",
+	}, edges)
+}
+
+func TestImportCycles(t *testing.T) {
+	require := require.New(t)
+
+	cycles := ImportCycles(cyclePackages())
+	require.Len(cycles, 1)
+	require.Equal([]string{"github.com/org/a", "github.com/org/b"}, cycles[0].Packages)
+	require.Equal(`import cycle between packages github.com/org/a, github.com/org/b:
+  github.com/org/a imports github.com/org/b: field a.Order.customer uses b.Customer
+  github.com/org/b imports github.com/org/a: field b.BService_LastOrderRequest.arg1 uses a.ID
+  github.com/org/b imports github.com/org/a: rpc b.BService.LastOrder uses a.Order`, cycles[0].String())
+
+	pkgs := cyclePackages()
+	pkgs[1].RPCs = nil
+	pkgs[1].Messages = pkgs[1].Messages[:2]
+	require.Empty(ImportCycles(pkgs))
+}
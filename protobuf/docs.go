@@ -0,0 +1,39 @@
+package protobuf
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Description returns the documentation of a message, enum, field or RPC as
+// a single text, with a line per line of the documentation.
+func Description(docs []string) string {
+	return strings.Join(docs, "\n")
+}
+
+// WriteBlockComment writes the documentation of a message, enum, field or
+// RPC as a /** ... */ doc comment with the given indentation. Nothing is
+// written if there is no documentation.
+func WriteBlockComment(buf *bytes.Buffer, docs []string, indent string) {
+	if len(docs) == 0 {
+		return
+	}
+
+	buf.WriteString(indent + "/**\n")
+	for _, d := range docs {
+		line := indent + " * " + strings.Replace(d, "*/", "*\\/", -1)
+		buf.WriteString(strings.TrimRight(line, " "))
+		buf.WriteRune('\n')
+	}
+	buf.WriteString(indent + " */\n")
+}
+
+// WriteLineComment writes the documentation of a message, enum, field or RPC
+// as a comment with the given indentation, made of a line starting with the
+// given prefix, such as "//", per line of the documentation.
+func WriteLineComment(buf *bytes.Buffer, docs []string, indent, prefix string) {
+	for _, d := range docs {
+		buf.WriteString(strings.TrimRight(indent+prefix+" "+d, " "))
+		buf.WriteRune('\n')
+	}
+}
@@ -0,0 +1,36 @@
+package protobuf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.Equal(t, "", Description(nil))
+	require.Equal(t, "User is an user.\n\nIt can log in.", Description([]string{"User is an user.", "", "It can log in."}))
+}
+
+func TestWriteBlockComment(t *testing.T) {
+	var buf bytes.Buffer
+	WriteBlockComment(&buf, nil, "")
+	require.Equal(t, "", buf.String())
+
+	WriteBlockComment(&buf, []string{"User is an user.", "", "It ends comments with */."}, "  ")
+	require.Equal(t, `  /**
+   * User is an user.
+   *
+   * It ends comments with *\/.
+   */
+`, buf.String())
+}
+
+func TestWriteLineComment(t *testing.T) {
+	var buf bytes.Buffer
+	WriteLineComment(&buf, []string{"User is an user.", "", "It can log in."}, "  ", "///")
+	require.Equal(t, `  /// User is an user.
+  ///
+  /// It can log in.
+`, buf.String())
+}
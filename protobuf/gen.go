@@ -171,16 +171,30 @@ func writeDocs(buf *bytes.Buffer, docs []string, indent bool) {
 	}
 }
 
+func writeRPCOptions(buf *bytes.Buffer, options Options) {
+	if len(options) == 0 {
+		buf.WriteString(";\n")
+		return
+	}
+
+	buf.WriteString(" {\n")
+	for _, opt := range options.Sorted() {
+		buf.WriteString(fmt.Sprintf("\t\toption %s = %s;\n", opt.Name, opt.Value))
+	}
+	buf.WriteString("\t}\n")
+}
+
 func writeService(buf *bytes.Buffer, pkg *Package) {
 	buf.WriteString(fmt.Sprintf("service %s {\n", pkg.ServiceName()))
 	for _, rpc := range pkg.RPCs {
 		writeDocs(buf, rpc.Docs, true)
 		buf.WriteString(fmt.Sprintf(
-			"\trpc %s (%s) returns (%s);\n",
+			"\trpc %s (%s) returns (%s)",
 			rpc.Name,
 			rpc.Input,
 			rpc.Output,
 		))
+		writeRPCOptions(buf, rpc.Options)
 	}
 	buf.WriteString("}\n\n")
 }
@@ -0,0 +1,108 @@
+package protobuf
+
+import "gopkg.in/src-d/proteus.v1/scanner"
+
+// MessageHook is run for every message transformed from a struct, after its
+// fields have been transformed, with the package it belongs to and the
+// struct it was transformed from. It can modify the message and returns
+// whether the message must be kept in the package or dropped.
+type MessageHook func(pkg *Package, msg *Message, src *scanner.Struct) bool
+
+// FieldHook is run for every field transformed from a struct field, with
+// the package and message it belongs to and the struct field it was
+// transformed from. It can modify the field and returns whether the field
+// must be kept in the message or dropped, in which case its position is
+// reserved.
+type FieldHook func(pkg *Package, msg *Message, f *Field, src *scanner.Field) bool
+
+// EnumHook is run for every enum, after its values have been transformed,
+// with the package it belongs to and the Go enum it was transformed from. It
+// can modify the enum and returns whether the enum must be kept in the
+// package or dropped.
+type EnumHook func(pkg *Package, enum *Enum, src *scanner.Enum) bool
+
+// EnumValueHook is run for every enum value, with the package and enum it
+// belongs to and the Go constant it was transformed from. It can modify the
+// value and returns whether the value must be kept in the enum or dropped.
+type EnumValueHook func(pkg *Package, enum *Enum, v *EnumValue, src *scanner.EnumValue) bool
+
+// RPCHook is run for every RPC, with the package it belongs to and the Go
+// function or method it was transformed from. It can modify the RPC and
+// returns whether the RPC must be kept in the service or dropped.
+type RPCHook func(pkg *Package, rpc *RPC, src *scanner.Func) bool
+
+// Hooks are the functions run by the Transformer for every message, field,
+// enum, enum value and RPC it transforms, in the order they were added. Once
+// a hook drops an item, the rest of hooks are not run for it.
+//
+// The messages generated for the requests and responses of the RPCs have no
+// struct to be transformed from, so hooks are not run for them nor for
+// their fields.
+//
+// Fields renamed by the hooks keep the name of their Go field with the
+// gogoproto.customname option, unless the hook sets it. Messages and enums
+// cannot be renamed, as the Go code generated by protoc uses their Go types,
+// so renaming them is reported as an error by Transformer.Err.
+// Dropping a message or enum does not drop the fields using it, which must be
+// dropped by a FieldHook as well.
+type Hooks struct {
+	Message   []MessageHook
+	Field     []FieldHook
+	Enum      []EnumHook
+	EnumValue []EnumValueHook
+	RPC       []RPCHook
+}
+
+// Add adds the hooks in the given Hooks after the current ones.
+func (h *Hooks) Add(hooks Hooks) {
+	h.Message = append(h.Message, hooks.Message...)
+	h.Field = append(h.Field, hooks.Field...)
+	h.Enum = append(h.Enum, hooks.Enum...)
+	h.EnumValue = append(h.EnumValue, hooks.EnumValue...)
+	h.RPC = append(h.RPC, hooks.RPC...)
+}
+
+func (h *Hooks) runMessage(pkg *Package, msg *Message, src *scanner.Struct) bool {
+	for _, hook := range h.Message {
+		if !hook(pkg, msg, src) {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *Hooks) runField(pkg *Package, msg *Message, f *Field, src *scanner.Field) bool {
+	for _, hook := range h.Field {
+		if !hook(pkg, msg, f, src) {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *Hooks) runEnum(pkg *Package, enum *Enum, src *scanner.Enum) bool {
+	for _, hook := range h.Enum {
+		if !hook(pkg, enum, src) {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *Hooks) runEnumValue(pkg *Package, enum *Enum, v *EnumValue, src *scanner.EnumValue) bool {
+	for _, hook := range h.EnumValue {
+		if !hook(pkg, enum, v, src) {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *Hooks) runRPC(pkg *Package, rpc *RPC, src *scanner.Func) bool {
+	for _, hook := range h.RPC {
+		if !hook(pkg, rpc, src) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package protobuf
+
+import (
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+func (s *TransformerSuite) TestHooks() {
+	var sources []string
+	s.t.AddHooks(Hooks{
+		Message: []MessageHook{
+			func(pkg *Package, msg *Message, src *scanner.Struct) bool {
+				sources = append(sources, src.Name)
+				if msg.Name == "Saz" {
+					msg.Options["deprecated"] = NewLiteralValue("true")
+				}
+				return msg.Name != "Qux"
+			},
+		},
+		Field: []FieldHook{
+			func(pkg *Package, msg *Message, f *Field, src *scanner.Field) bool {
+				if msg.Name == "Bar" && src.Name == "Bar" {
+					f.Name = "id"
+				}
+				return !(msg.Name == "Saz" && src.Name == "Foo")
+			},
+		},
+		EnumValue: []EnumValueHook{
+			func(pkg *Package, enum *Enum, v *EnumValue, src *scanner.EnumValue) bool {
+				return src.Name != "CBaz"
+			},
+		},
+	})
+
+	pkg := s.t.Transform(s.fixtures()[0])
+	s.Equal([]string{"Bar", "Foo", "Jur", "Qux", "Saz"}, sources)
+
+	msgs := make(map[string]*Message)
+	for _, m := range pkg.Messages {
+		msgs[m.Name] = m
+	}
+	s.Len(msgs, 4)
+	s.NotContains(msgs, "Qux")
+
+	saz := msgs["Saz"]
+	s.NotNil(saz)
+	s.Equal(NewLiteralValue("true"), saz.Options["deprecated"])
+	s.Len(saz.Fields, 1)
+	s.Equal("point", saz.Fields[0].Name)
+	s.Equal([]uint{2}, saz.Reserved)
+
+	s.Len(pkg.Enums, 1)
+	var values []string
+	for _, v := range pkg.Enums[0].Values {
+		values = append(values, v.Name)
+	}
+	s.Equal([]string{"ABAZ", "BBAZ", "DBAZ"}, values)
+
+	id := msgs["Bar"].Fields[0]
+	s.Equal("id", id.Name)
+	s.Equal(NewStringValue("Bar"), id.Options["(gogoproto.customname)"], "renamed fields keep their Go name")
+	s.NotContains(msgs["Bar"].Fields[1].Options, "(gogoproto.customname)")
+	s.Nil(s.t.Err())
+}
+
+func (s *TransformerSuite) TestHooksStopAtDrop() {
+	var calls int
+	s.t.AddHooks(Hooks{
+		RPC: []RPCHook{
+			func(pkg *Package, rpc *RPC, src *scanner.Func) bool {
+				return src.Name != "Generated"
+			},
+		},
+	})
+	s.t.AddHooks(Hooks{
+		RPC: []RPCHook{
+			func(pkg *Package, rpc *RPC, src *scanner.Func) bool {
+				calls++
+				rpc.Options = Options{"deprecated": NewLiteralValue("true")}
+				return true
+			},
+		},
+	})
+
+	pkg := s.t.Transform(s.fixtures()[1])
+	s.Len(pkg.RPCs, 3)
+	s.Equal(3, calls)
+	for _, rpc := range pkg.RPCs {
+		s.NotEqual("Generated", rpc.Name)
+		s.Equal(NewLiteralValue("true"), rpc.Options["deprecated"])
+	}
+}
+
+func (s *TransformerSuite) TestHooksRenameTypes() {
+	s.t.AddHooks(Hooks{
+		Message: []MessageHook{
+			func(pkg *Package, msg *Message, src *scanner.Struct) bool {
+				if msg.Name == "Saz" {
+					msg.Name = "Sazz"
+				}
+				return true
+			},
+		},
+		Enum: []EnumHook{
+			func(pkg *Package, enum *Enum, src *scanner.Enum) bool {
+				enum.Name = "Kind"
+				return true
+			},
+		},
+	})
+
+	s.t.Transform(s.fixtures()[0])
+	s.Equal([]string{
+		`message "Saz" was renamed to "Sazz" by a hook, but messages and enums cannot be renamed, as the Go code generated by protoc uses their Go types`,
+		`enum "Baz" was renamed to "Kind" by a hook, but messages and enums cannot be renamed, as the Go code generated by protoc uses their Go types`,
+	}, s.t.errs)
+}
@@ -0,0 +1,193 @@
+package protobuf
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+const (
+	httpDirective = "http"
+	httpOption    = "(google.api.http)"
+	httpImport    = "google/api/annotations.proto"
+)
+
+// httpMethods are the HTTP methods supported by the google.api.http option,
+// with the name of their pattern in the HttpRule message.
+var httpMethods = map[string]string{
+	"GET":    "get",
+	"PUT":    "put",
+	"POST":   "post",
+	"DELETE": "delete",
+	"PATCH":  "patch",
+}
+
+// httpPathParam matches the variables of an HTTP path template, such as
+// {id}, {user.id} or {name=shelves/*}, capturing the field path.
+var httpPathParam = regexp.MustCompile(`\{([^}=]*)(=[^}]*)?\}`)
+
+// httpOptions sets the google.api.http option of the RPC from the
+// `//proteus:http METHOD PATH [body=FIELD]` directive of the function, so
+// it can be exposed as an HTTP/JSON API with gRPC transcoding. The body is
+// the whole request by default for methods other than GET and DELETE. The
+// path parameters and the body must be fields of the request message,
+// otherwise the directive is ignored, reported as an error and returned by
+// Transformer.Err.
+func (t *Transformer) httpOptions(pkg *Package, rpc *RPC, directives scanner.Directives) {
+	directive, ok := directives.Get(httpDirective)
+	if !ok {
+		return
+	}
+
+	opts, err := parseHTTPRule(directive)
+	if err == nil {
+		err = checkHTTPRule(pkg, rpc.Input, opts)
+	}
+
+	if err != nil {
+		t.addError("invalid http directive %q of func %q: %s", directive, rpc.Name, err)
+		return
+	}
+
+	if rpc.Options == nil {
+		rpc.Options = make(Options)
+	}
+
+	for name, value := range opts {
+		rpc.Options[fmt.Sprintf("%s.%s", httpOption, name)] = NewStringValue(value)
+	}
+	pkg.Import(&ProtoType{Import: httpImport})
+}
+
+// HTTPRule is the mapping of a RPC to an HTTP method and path, defined by
+// its google.api.http option.
+type HTTPRule struct {
+	// Method is the HTTP method in upper case, such as GET.
+	Method string
+	// Path is the path template, such as /v1/users/{id}.
+	Path string
+	// Body is the field of the request mapped to the body of the HTTP
+	// request, "*" for the whole request or empty if there is no body.
+	Body string
+}
+
+// HTTPRule returns the HTTP mapping of the RPC or nil if it has none.
+func (r *RPC) HTTPRule() *HTTPRule {
+	for method, pattern := range httpMethods {
+		path, ok := r.Options[fmt.Sprintf("%s.%s", httpOption, pattern)].(StringValue)
+		if !ok {
+			continue
+		}
+
+		rule := &HTTPRule{Method: method, Path: path.val}
+		if body, ok := r.Options[httpOption+".body"].(StringValue); ok {
+			rule.Body = body.val
+		}
+		return rule
+	}
+	return nil
+}
+
+// PathParams returns the field paths of the variables of the path template.
+func (r *HTTPRule) PathParams() []string {
+	var params []string
+	for _, m := range httpPathParam.FindAllStringSubmatch(r.Path, -1) {
+		params = append(params, m[1])
+	}
+	return params
+}
+
+// SimplePath returns the path template without the patterns of its
+// variables, such as /v1/{name} for /v1/{name=shelves/*}.
+func (r *HTTPRule) SimplePath() string {
+	return httpPathParam.ReplaceAllString(r.Path, "{$1}")
+}
+
+// parseHTTPRule parses the arguments of the http directive and returns the
+// fields of the HttpRule message with their values.
+func parseHTTPRule(directive string) (map[string]string, error) {
+	args := strings.Fields(directive)
+	if len(args) < 2 || len(args) > 3 {
+		return nil, fmt.Errorf("expecting METHOD PATH [body=FIELD]")
+	}
+
+	pattern, ok := httpMethods[strings.ToUpper(args[0])]
+	if !ok {
+		return nil, fmt.Errorf("unsupported method %q", args[0])
+	}
+
+	if !strings.HasPrefix(args[1], "/") {
+		return nil, fmt.Errorf("path %q must start with /", args[1])
+	}
+
+	rule := map[string]string{pattern: args[1]}
+	if pattern != "get" && pattern != "delete" {
+		rule["body"] = "*"
+	}
+
+	if len(args) == 3 {
+		if !strings.HasPrefix(args[2], "body=") {
+			return nil, fmt.Errorf("unexpected argument %q, expecting body=FIELD", args[2])
+		}
+		rule["body"] = strings.TrimPrefix(args[2], "body=")
+	}
+
+	return rule, nil
+}
+
+// checkHTTPRule checks that the path parameters and the body of the rule
+// are fields of the request message. Requests that are not messages of the
+// package cannot be checked.
+func checkHTTPRule(pkg *Package, input Type, rule map[string]string) error {
+	msg := pkg.Message(input)
+	if msg == nil {
+		return nil
+	}
+
+	for name, value := range rule {
+		if name == "body" {
+			if value != "*" && value != "" {
+				if err := checkFieldPath(pkg, msg, value); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		for _, m := range httpPathParam.FindAllStringSubmatch(value, -1) {
+			if err := checkFieldPath(pkg, msg, m[1]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkFieldPath checks that the dot-separated path of fields exists in the
+// given message.
+func checkFieldPath(pkg *Package, msg *Message, path string) error {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		f := msg.Field(name)
+		if f == nil {
+			return fmt.Errorf("message %s has no field %q", msg.Name, name)
+		}
+
+		if i == len(names)-1 {
+			break
+		}
+
+		if _, ok := f.Type.(*Named); !ok || f.Repeated {
+			return fmt.Errorf("field %q of message %s is not a message", name, msg.Name)
+		}
+
+		if msg = pkg.Message(f.Type); msg == nil {
+			break
+		}
+	}
+
+	return nil
+}
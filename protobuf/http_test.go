@@ -0,0 +1,140 @@
+package protobuf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+func TestParseHTTPRule(t *testing.T) {
+	cases := []struct {
+		directive string
+		rule      map[string]string
+	}{
+		{"GET /v1/users/{id}", map[string]string{"get": "/v1/users/{id}"}},
+		{"delete /v1/users/{id}", map[string]string{"delete": "/v1/users/{id}"}},
+		{"POST /v1/users", map[string]string{"post": "/v1/users", "body": "*"}},
+		{"PATCH /v1/users/{id} body=user", map[string]string{"patch": "/v1/users/{id}", "body": "user"}},
+		{"PUT /v1/users/{id} body=", map[string]string{"put": "/v1/users/{id}", "body": ""}},
+	}
+
+	for _, c := range cases {
+		rule, err := parseHTTPRule(c.directive)
+		require.Nil(t, err, c.directive)
+		require.Equal(t, c.rule, rule, c.directive)
+	}
+
+	invalid := []string{
+		"GET",
+		"FETCH /v1/users",
+		"GET v1/users",
+		"POST /v1/users user",
+		"POST /v1/users body=user extra",
+	}
+	for _, directive := range invalid {
+		_, err := parseHTTPRule(directive)
+		require.NotNil(t, err, directive)
+	}
+}
+
+func TestTransformHTTP(t *testing.T) {
+	report.TestMode()
+	defer report.EndTestMode()
+
+	http := func(directive string) scanner.Docs {
+		return scanner.Docs{Directives: scanner.Directives{"http": {directive}}}
+	}
+
+	tr := NewTransformer()
+	pkg := tr.Transform(&scanner.Package{
+		Path: "foo",
+		Name: "foo",
+		Structs: []*scanner.Struct{
+			{
+				Name: "User",
+				Fields: []*scanner.Field{
+					{Name: "ID", Type: scanner.NewBasic("string")},
+					{Name: "Address", Type: scanner.NewNamed("foo", "Address")},
+				},
+			},
+			{
+				Name: "Address",
+				Fields: []*scanner.Field{
+					{Name: "City", Type: scanner.NewBasic("string")},
+				},
+			},
+		},
+		Funcs: []*scanner.Func{
+			{
+				Docs:   http("GET /v1/users/{arg1}"),
+				Name:   "GetUser",
+				Input:  []scanner.Type{scanner.NewBasic("string")},
+				Output: []scanner.Type{scanner.NewNamed("foo", "User")},
+			},
+			{
+				Docs:   http("PATCH /v1/users/{id}/cities/{address.city} body=address"),
+				Name:   "UpdateAddress",
+				Input:  []scanner.Type{scanner.NewNamed("foo", "User")},
+				Output: []scanner.Type{scanner.NewNamed("foo", "User")},
+			},
+			{
+				Docs:   http("GET /v1/users/{name}"),
+				Name:   "FindUser",
+				Input:  []scanner.Type{scanner.NewBasic("string")},
+				Output: []scanner.Type{scanner.NewNamed("foo", "User")},
+			},
+			{
+				Docs:   http("GET /v1/users/{id.city}"),
+				Name:   "FindByID",
+				Input:  []scanner.Type{scanner.NewNamed("foo", "User")},
+				Output: []scanner.Type{scanner.NewNamed("foo", "User")},
+			},
+			{
+				Docs:   scanner.Docs{Directives: scanner.Directives{"option": {"deprecated=true"}}},
+				Name:   "DeleteUser",
+				Input:  []scanner.Type{scanner.NewBasic("string")},
+				Output: []scanner.Type{},
+			},
+		},
+	})
+
+	require.Len(t, pkg.RPCs, 5)
+	require.Equal(t, Options{
+		"(google.api.http).get": NewStringValue("/v1/users/{arg1}"),
+	}, pkg.RPCs[0].Options)
+	require.Equal(t, Options{
+		"(google.api.http).patch": NewStringValue("/v1/users/{id}/cities/{address.city}"),
+		"(google.api.http).body":  NewStringValue("address"),
+	}, pkg.RPCs[1].Options)
+	require.Nil(t, pkg.RPCs[2].Options, "unknown path parameter")
+	require.Nil(t, pkg.RPCs[3].Options, "path parameter through a scalar field")
+	require.Equal(t, Options{"deprecated": NewLiteralValue("true")}, pkg.RPCs[4].Options)
+
+	require.Contains(t, pkg.Imports, "google/api/annotations.proto")
+	require.Len(t, report.MessageStack(), 2, "invalid http directives are reported")
+	require.Error(t, tr.Err())
+	require.Contains(t, tr.Err().Error(), `invalid http directive "GET /v1/users/{name}" of func "FindUser"`)
+	require.Contains(t, tr.Err().Error(), `invalid http directive "GET /v1/users/{id.city}" of func "FindByID"`)
+}
+
+func TestRPCHTTPRule(t *testing.T) {
+	require.Nil(t, (&RPC{}).HTTPRule())
+
+	rpc := &RPC{Options: Options{
+		"(google.api.http).patch": NewStringValue("/v1/users/{id}/cities/{address.city=*}"),
+		"(google.api.http).body":  NewStringValue("address"),
+		"deprecated":              NewLiteralValue("true"),
+	}}
+
+	rule := rpc.HTTPRule()
+	require.Equal(t, &HTTPRule{
+		Method: "PATCH",
+		Path:   "/v1/users/{id}/cities/{address.city=*}",
+		Body:   "address",
+	}, rule)
+	require.Equal(t, []string{"id", "address.city"}, rule.PathParams())
+	require.Equal(t, "/v1/users/{id}/cities/{address.city}", rule.SimplePath())
+}
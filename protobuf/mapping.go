@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+
+	"gopkg.in/src-d/proteus.v1/scanner"
 )
 
 // ProtoType represents a protobuf type. It can optionally have a
@@ -83,8 +85,34 @@ func CastToBasicType(basic string) Decorators {
 // TypeMappings is a mapping between Go types and protobuf types.
 // The names of the Go types can have packages. For example: "time.Time" is a
 // valid name. "foo.bar/baz.Qux" is a valid type name as well.
+// Names can also be patterns, such as "*.ID", see scanner.MatchName.
 type TypeMappings map[string]*ProtoType
 
+// Find returns the mapping of the Go type with the given name. The mapping
+// with the same name is used if there is one, otherwise the longest pattern
+// matching the name is used, as it is the most specific one.
+func (t TypeMappings) Find(name string) *ProtoType {
+	if typ, ok := t[name]; ok {
+		return typ
+	}
+
+	var pattern string
+	for k := range t {
+		if !scanner.MatchName(k, name) {
+			continue
+		}
+
+		if len(k) > len(pattern) || (len(k) == len(pattern) && k < pattern) {
+			pattern = k
+		}
+	}
+
+	if pattern == "" {
+		return nil
+	}
+	return t[pattern]
+}
+
 var DefaultMappings = TypeMappings{
 	"float64": &ProtoType{Name: "double", Basic: true},
 	"float32": &ProtoType{Name: "float", Basic: true},
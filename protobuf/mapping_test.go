@@ -34,6 +34,38 @@ func TestDefaultMappingUpgradeBasicDecoratos(t *testing.T) {
 	}
 }
 
+func TestTypeMappingsFind(t *testing.T) {
+	mappings := TypeMappings{
+		"*.ID":                   &ProtoType{Name: "int64", Basic: true},
+		"github.com/foo/bar.*":   &ProtoType{Name: "string", Basic: true},
+		"github.com/foo/bar.ID":  &ProtoType{Name: "bytes", Basic: true},
+		"github.com/foo/baz.*ID": &ProtoType{Name: "uint64", Basic: true},
+	}
+
+	cases := []struct {
+		name     string
+		expected string
+	}{
+		{"github.com/foo/bar.ID", "bytes"},
+		{"github.com/foo/bar.Name", "string"},
+		{"github.com/foo/qux.ID", "int64"},
+		{"github.com/foo/baz.ID", "uint64"},
+		{"github.com/foo/baz.Name", ""},
+		{"ID", ""},
+	}
+
+	for _, c := range cases {
+		typ := mappings.Find(c.name)
+		if c.expected == "" {
+			assert.Nil(t, typ, c.name)
+		} else if assert.NotNil(t, typ, c.name) {
+			assert.Equal(t, c.expected, typ.Name, c.name)
+		}
+	}
+
+	assert.Nil(t, TypeMappings(nil).Find("github.com/foo/bar.ID"))
+}
+
 func TestToGoOutPath(t *testing.T) {
 	// Empty case
 	assert.Equal(t, "", TypeMappings{}.ToGoOutPath())
@@ -0,0 +1,195 @@
+package protobuf
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// MappingsFile contains the custom type mappings defined in a mappings file,
+// which is a YAML or JSON document such as:
+//
+//	mappings:
+//	  github.com/google/uuid.UUID:
+//	    proto: string
+//	    options:
+//	      (gogoproto.customtype): github.com/myorg/types.UUID
+//	  "*.ID":
+//	    proto: int64
+//	  github.com/myorg/money.Money:
+//	    proto: myorg.money.Money
+//	    import: myorg/money/money.proto
+//	    go_import: github.com/myorg/money
+//	packages:
+//	  github.com/myorg/legacy:
+//	    "*.ID":
+//	      proto: string
+//
+// The keys are the names of the Go types, or patterns of them (see
+// scanner.MatchName), and "packages" contains the mappings only used in the
+// package with the given path, which take precedence over the rest.
+//
+// Every mapping has the full name of the protobuf type, which is a scalar
+// type or a message, and optionally the proto file to import and the Go
+// package of the generated code of the message, a warning to report when
+// the mapping is used, and the options to set in the fields of the type
+// ("options"), in their messages ("message_options") and in their packages
+// ("package_options"), with the syntax of the option directive.
+type MappingsFile struct {
+	// Mappings are the mappings used in all the packages.
+	Mappings TypeMappings
+	// Packages are the mappings used only in a package, by package path.
+	Packages map[string]TypeMappings
+}
+
+// LoadMappingsFile reads and parses the mappings file at the given path.
+func LoadMappingsFile(file string) (*MappingsFile, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := ParseMappingsFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mappings file %s: %s", file, err)
+	}
+
+	return m, nil
+}
+
+// ParseMappingsFile parses the given YAML or JSON mappings file.
+func ParseMappingsFile(data []byte) (*MappingsFile, error) {
+	var doc struct {
+		Mappings map[string]*mappingEntry            `yaml:"mappings"`
+		Packages map[string]map[string]*mappingEntry `yaml:"packages"`
+	}
+
+	if err := yaml.UnmarshalStrict(data, &doc); err != nil {
+		return nil, err
+	}
+
+	mappings, err := toTypeMappings(doc.Mappings)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MappingsFile{
+		Mappings: mappings,
+		Packages: make(map[string]TypeMappings, len(doc.Packages)),
+	}
+
+	for path, entries := range doc.Packages {
+		if m.Packages[path], err = toTypeMappings(entries); err != nil {
+			return nil, fmt.Errorf("package %s: %s", path, err)
+		}
+	}
+
+	return m, nil
+}
+
+type mappingEntry struct {
+	Proto          string            `yaml:"proto"`
+	Import         string            `yaml:"import"`
+	GoImport       string            `yaml:"go_import"`
+	Warn           string            `yaml:"warn"`
+	Options        map[string]string `yaml:"options"`
+	MessageOptions map[string]string `yaml:"message_options"`
+	PackageOptions map[string]string `yaml:"package_options"`
+}
+
+func toTypeMappings(entries map[string]*mappingEntry) (TypeMappings, error) {
+	mappings := make(TypeMappings, len(entries))
+	for name, e := range entries {
+		if e == nil || e.Proto == "" {
+			return nil, fmt.Errorf("mapping of %s has no proto type", name)
+		}
+
+		typ, err := e.protoType()
+		if err != nil {
+			return nil, fmt.Errorf("mapping of %s: %s", name, err)
+		}
+		mappings[name] = typ
+	}
+
+	return mappings, nil
+}
+
+func (e *mappingEntry) protoType() (*ProtoType, error) {
+	typ := &ProtoType{
+		Name:     e.Proto,
+		Import:   e.Import,
+		GoImport: e.GoImport,
+		Warn:     e.Warn,
+	}
+
+	if idx := strings.LastIndex(e.Proto, "."); idx >= 0 {
+		typ.Package, typ.Name = e.Proto[:idx], e.Proto[idx+1:]
+	} else if _, ok := protoScalars[e.Proto]; ok || e.Proto == "bytes" {
+		typ.Basic = true
+	}
+
+	fieldOpts, err := parseOptionMap(e.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	msgOpts, err := parseOptionMap(e.MessageOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgOpts, err := parseOptionMap(e.PackageOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fieldOpts)+len(msgOpts)+len(pkgOpts) > 0 {
+		typ.Decorators = NewDecorators(func(p *Package, m *Message, f *Field) {
+			f.Options = setOptions(f.Options, fieldOpts)
+			m.Options = setOptions(m.Options, msgOpts)
+			p.Options = setOptions(p.Options, pkgOpts)
+		})
+	}
+
+	return typ, nil
+}
+
+// parseOptionMap parses the options with the given names and values, sorted
+// by name so the errors are deterministic.
+func parseOptionMap(options map[string]string) (Options, error) {
+	var names []string
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	opts := make(Options, len(options))
+	for _, name := range names {
+		name, value, err := ParseOption(name + "=" + options[name])
+		if err != nil {
+			return nil, err
+		}
+		opts[name] = value
+	}
+
+	return opts, nil
+}
+
+// setOptions sets the given options in opts, overriding the existing ones.
+func setOptions(opts, set Options) Options {
+	if len(set) == 0 {
+		return opts
+	}
+
+	if opts == nil {
+		opts = make(Options, len(set))
+	}
+
+	for name, value := range set {
+		opts[name] = value
+	}
+	return opts
+}
@@ -0,0 +1,134 @@
+package protobuf
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const yamlMappings = `
+mappings:
+  github.com/google/uuid.UUID:
+    proto: string
+    warn: "%s is a string"
+    options:
+      (gogoproto.customtype): github.com/myorg/types.UUID
+      (gogoproto.nullable): false
+    message_options:
+      (gogoproto.equal): true
+    package_options:
+      (gogoproto.marshaler_all): true
+  "*.ID":
+    proto: int64
+  github.com/myorg/money.Money:
+    proto: myorg.money.Money
+    import: myorg/money/money.proto
+    go_import: github.com/myorg/money
+packages:
+  github.com/myorg/legacy:
+    "*.ID":
+      proto: bytes
+`
+
+func TestParseMappingsFile(t *testing.T) {
+	require := require.New(t)
+
+	m, err := ParseMappingsFile([]byte(yamlMappings))
+	require.NoError(err)
+	require.Len(m.Mappings, 3)
+
+	uuid := m.Mappings["github.com/google/uuid.UUID"]
+	require.Equal("string", uuid.Name)
+	require.Equal("", uuid.Package)
+	require.True(uuid.Basic)
+	require.Equal("%s is a string", uuid.Warn)
+	require.Len(uuid.Decorators, 1)
+
+	pkg, msg, f := &Package{}, &Message{}, &Field{}
+	uuid.Decorate(pkg, msg, f)
+	require.Equal(Options{
+		"(gogoproto.customtype)": NewStringValue("github.com/myorg/types.UUID"),
+		"(gogoproto.nullable)":   NewLiteralValue("false"),
+	}, f.Options)
+	require.Equal(Options{"(gogoproto.equal)": NewLiteralValue("true")}, msg.Options)
+	require.Equal(Options{"(gogoproto.marshaler_all)": NewLiteralValue("true")}, pkg.Options)
+
+	id := m.Mappings["*.ID"]
+	require.Equal(&ProtoType{Name: "int64", Basic: true}, id)
+
+	money := m.Mappings["github.com/myorg/money.Money"]
+	require.Equal(&ProtoType{
+		Package:  "myorg.money",
+		Name:     "Money",
+		Import:   "myorg/money/money.proto",
+		GoImport: "github.com/myorg/money",
+	}, money)
+
+	require.Equal(map[string]TypeMappings{
+		"github.com/myorg/legacy": {
+			"*.ID": &ProtoType{Name: "bytes", Basic: true},
+		},
+	}, m.Packages)
+}
+
+func TestParseMappingsFileJSON(t *testing.T) {
+	require := require.New(t)
+
+	m, err := ParseMappingsFile([]byte(`{
+		"mappings": {
+			"github.com/google/uuid.UUID": {
+				"proto": "string",
+				"options": {"(gogoproto.customtype)": "github.com/myorg/types.UUID"}
+			}
+		}
+	}`))
+	require.NoError(err)
+	require.Len(m.Mappings, 1)
+	require.Equal("string", m.Mappings["github.com/google/uuid.UUID"].Name)
+	require.Len(m.Packages, 0)
+}
+
+func TestParseMappingsFileErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{"no proto type", "mappings:\n  foo.Bar: {}", "mapping of foo.Bar has no proto type"},
+		{"unknown field", "mappings:\n  foo.Bar:\n    type: string", "field type not found"},
+		{"invalid option", "mappings:\n  foo.Bar:\n    proto: string\n    options:\n      \" \": true", "mapping of foo.Bar: invalid option"},
+		{"package", "packages:\n  foo:\n    foo.Bar: {}", "package foo: mapping of foo.Bar has no proto type"},
+	}
+
+	for _, c := range cases {
+		_, err := ParseMappingsFile([]byte(c.data))
+		require.Error(t, err, c.name)
+		require.Contains(t, err.Error(), c.err, c.name)
+	}
+}
+
+func TestLoadMappingsFile(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "proteus-mappings")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "mappings.yml")
+	require.NoError(ioutil.WriteFile(file, []byte(yamlMappings), 0644))
+
+	m, err := LoadMappingsFile(file)
+	require.NoError(err)
+	require.Len(m.Mappings, 3)
+
+	_, err = LoadMappingsFile(filepath.Join(dir, "missing.yml"))
+	require.Error(err)
+
+	require.NoError(ioutil.WriteFile(file, []byte("mappings: ["), 0644))
+	_, err = LoadMappingsFile(file)
+	require.Error(err)
+	require.Contains(err.Error(), "invalid mappings file "+file)
+}
@@ -0,0 +1,90 @@
+package protobuf
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/src-d/proteus.v1/report"
+)
+
+const (
+	// optionDirective is the directive to set an option of a package,
+	// message, enum or enum value, e.g. `//proteus:option (gogoproto.equal)=true`.
+	optionDirective = "option"
+	// optionTag is the field tag option to set an option of a field, e.g.
+	// `proteus:"opt=(gogoproto.jsontag)='id,omitempty'"`.
+	optionTag = "opt"
+	// importDirective is the package directive to import a proto file, which
+	// is needed to use custom options defined in it.
+	importDirective = "import"
+)
+
+var (
+	numberRegex   = regexp.MustCompile(`^[-+]?(\d+(\.\d*)?|\.\d+)([eE][-+]?\d+)?$|^[-+]?0[xX][0-9a-fA-F]+$|^[-+]?(inf|nan)$`)
+	constantRegex = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+)
+
+// ParseOption parses an option in the `name=value` form. The value is a
+// LiteralValue if it is a boolean, a number or an enum constant in upper
+// case, such as SPEED, and a StringValue otherwise. String values can be
+// quoted with double or single quotes, which is needed if the string looks
+// like a literal value or, in struct tags, if it contains commas.
+func ParseOption(option string) (string, OptionValue, error) {
+	idx := strings.Index(option, "=")
+	if idx < 0 {
+		return "", nil, fmt.Errorf("invalid option %q, expecting name=value", option)
+	}
+
+	name := strings.TrimSpace(option[:idx])
+	if name == "" {
+		return "", nil, fmt.Errorf("invalid option %q, name is empty", option)
+	}
+
+	value, err := parseOptionValue(strings.TrimSpace(option[idx+1:]))
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid value of option %q: %s", name, err)
+	}
+
+	return name, value, nil
+}
+
+func parseOptionValue(v string) (OptionValue, error) {
+	if len(v) >= 2 {
+		switch {
+		case v[0] == '"' && v[len(v)-1] == '"':
+			s, err := strconv.Unquote(v)
+			if err != nil {
+				return nil, err
+			}
+			return NewStringValue(s), nil
+		case v[0] == '\'' && v[len(v)-1] == '\'':
+			return NewStringValue(v[1 : len(v)-1]), nil
+		}
+	}
+
+	if v == "true" || v == "false" || numberRegex.MatchString(v) || constantRegex.MatchString(v) {
+		return NewLiteralValue(v), nil
+	}
+
+	return NewStringValue(v), nil
+}
+
+// mergeOptions parses the given options and sets them in opts, overriding
+// the ones already set. Invalid options are reported and ignored.
+func mergeOptions(opts Options, options []string, where string) Options {
+	for _, o := range options {
+		name, value, err := ParseOption(o)
+		if err != nil {
+			report.Warn("%s in %s, ignoring it", err, where)
+			continue
+		}
+
+		if opts == nil {
+			opts = make(Options)
+		}
+		opts[name] = value
+	}
+	return opts
+}
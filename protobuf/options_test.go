@@ -0,0 +1,114 @@
+package protobuf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+func TestParseOption(t *testing.T) {
+	cases := []struct {
+		option string
+		name   string
+		value  OptionValue
+	}{
+		{"(gogoproto.equal)=true", "(gogoproto.equal)", NewLiteralValue("true")},
+		{"(validate.rules).string.min_len = 1", "(validate.rules).string.min_len", NewLiteralValue("1")},
+		{"(foo).ratio=-1.5e3", "(foo).ratio", NewLiteralValue("-1.5e3")},
+		{"(foo).mask=0xFF", "(foo).mask", NewLiteralValue("0xFF")},
+		{"optimize_for=SPEED", "optimize_for", NewLiteralValue("SPEED")},
+		{"java_package=com.example.foo", "java_package", NewStringValue("com.example.foo")},
+		{"(gogoproto.customname)=MyName", "(gogoproto.customname)", NewStringValue("MyName")},
+		{`(gogoproto.jsontag)="id,omitempty"`, "(gogoproto.jsontag)", NewStringValue("id,omitempty")},
+		{`(gogoproto.moretags)="db:\"id\""`, "(gogoproto.moretags)", NewStringValue(`db:"id"`)},
+		{"(foo).name='true'", "(foo).name", NewStringValue("true")},
+		{"(foo).empty=", "(foo).empty", NewStringValue("")},
+	}
+
+	for _, c := range cases {
+		name, value, err := ParseOption(c.option)
+		require.Nil(t, err, c.option)
+		require.Equal(t, c.name, name, c.option)
+		require.Equal(t, c.value, value, c.option)
+	}
+
+	for _, invalid := range []string{"(gogoproto.equal)", "=true", `(foo).bar="\q"`} {
+		_, _, err := ParseOption(invalid)
+		require.NotNil(t, err, invalid)
+	}
+}
+
+func TestTransformOptions(t *testing.T) {
+	report.TestMode()
+	defer report.EndTestMode()
+
+	docs := func(options ...string) scanner.Docs {
+		return scanner.Docs{Directives: scanner.Directives{"option": options}}
+	}
+
+	pkg := NewTransformer().Transform(&scanner.Package{
+		Path: "foo",
+		Name: "foo",
+		Directives: scanner.Directives{
+			"option": {"(gogoproto.sizer_all)=true", "(gogoproto.protosizer_all)=false", "java_multiple_files=true"},
+			"import": {`"validate/validate.proto"`},
+		},
+		Structs: []*scanner.Struct{
+			{
+				Docs: docs("(gogoproto.equal)=true", "(gogoproto.typedecl)=true", "invalid"),
+				Name: "Foo",
+				Fields: []*scanner.Field{
+					{
+						Name: "Name",
+						Type: scanner.NewBasic("string"),
+						Tags: []string{"opt=(validate.rules).string.min_len=1", "opt=(gogoproto.jsontag)='name,omitempty'"},
+					},
+					{
+						Name: "ID",
+						Type: scanner.NewBasic("int64"),
+						Tags: []string{"opt=(gogoproto.customname)=Identifier"},
+					},
+				},
+			},
+		},
+		Enums: []*scanner.Enum{
+			{
+				Docs: docs("allow_alias=true"),
+				Name: "Status",
+				Values: []*scanner.EnumValue{
+					{Docs: docs("deprecated=true"), Name: "Active"},
+				},
+			},
+		},
+	})
+
+	require.Equal(t, NewLiteralValue("true"), pkg.Options["(gogoproto.sizer_all)"])
+	require.Equal(t, NewLiteralValue("false"), pkg.Options["(gogoproto.protosizer_all)"])
+	require.Equal(t, NewLiteralValue("true"), pkg.Options["java_multiple_files"])
+	require.Equal(t, NewStringValue("foo"), pkg.Options["go_package"])
+	require.Contains(t, pkg.Imports, "validate/validate.proto")
+
+	msg := pkg.Messages[0]
+	require.Equal(t, NewLiteralValue("true"), msg.Options["(gogoproto.equal)"])
+	require.Equal(t, NewLiteralValue("true"), msg.Options["(gogoproto.typedecl)"])
+	require.Equal(t, NewLiteralValue("false"), msg.Options["(gogoproto.goproto_getters)"])
+	require.Len(t, msg.Options, 3)
+
+	require.Equal(t, Options{
+		"(validate.rules).string.min_len": NewLiteralValue("1"),
+		"(gogoproto.jsontag)":             NewStringValue("name,omitempty"),
+	}, msg.Fields[0].Options)
+	require.Equal(t, Options{
+		"(gogoproto.customname)": NewStringValue("Identifier"),
+	}, msg.Fields[1].Options)
+
+	enum := pkg.Enums[0]
+	require.Equal(t, NewLiteralValue("true"), enum.Options["allow_alias"])
+	require.Equal(t, NewLiteralValue("true"), enum.Values[0].Options["deprecated"])
+	require.Equal(t, NewStringValue("Active"), enum.Values[0].Options["(gogoproto.enumvalue_customname)"])
+
+	require.Len(t, report.MessageStack(), 1, "invalid option is reported")
+}
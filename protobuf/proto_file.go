@@ -0,0 +1,261 @@
+package protobuf
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseProtoDirective parses the arguments of the proto directive of a Go
+// type that is an existing protobuf message, such as
+// `common.Money import="common/money.proto"`, and returns the mapping to the
+// message. The arguments are the full name of the message and the proto
+// file defining it, which is required.
+func ParseProtoDirective(args string) (*ProtoType, error) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("invalid proto directive, expecting the full name of a message")
+	}
+
+	typ := &ProtoType{Name: fields[0]}
+	if idx := strings.LastIndex(typ.Name, "."); idx >= 0 {
+		typ.Package, typ.Name = typ.Name[:idx], typ.Name[idx+1:]
+	}
+
+	for _, f := range fields[1:] {
+		idx := strings.Index(f, "=")
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid argument %q of proto directive, expecting name=value", f)
+		}
+
+		value := f[idx+1:]
+		if strings.HasPrefix(value, `"`) {
+			v, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid argument %q of proto directive: %s", f, err)
+			}
+			value = v
+		}
+
+		if f[:idx] != "import" {
+			return nil, fmt.Errorf("unknown argument %q of proto directive, expecting import", f[:idx])
+		}
+		typ.Import = value
+	}
+
+	if typ.Import == "" {
+		return nil, fmt.Errorf("proto directive of message %s has no import", fields[0])
+	}
+
+	return typ, nil
+}
+
+// ProtoFile contains the messages and enums defined in a proto file.
+type ProtoFile struct {
+	// Package is the protobuf package of the file.
+	Package string
+	// Types contains the full names of the messages and enums of the file,
+	// including the nested ones, such as "foo.Bar.Baz".
+	Types map[string]bool
+}
+
+// LoadProtoFile reads and parses the proto file at the given path.
+func LoadProtoFile(file string) (*ProtoFile, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := ParseProtoFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proto file %s: %s", file, err)
+	}
+
+	return f, nil
+}
+
+// ParseProtoFile parses the given proto file. Only the package and the
+// declarations of messages and enums are parsed, the rest of the file is
+// just checked to be well-formed enough to find them.
+func ParseProtoFile(data []byte) (*ProtoFile, error) {
+	tokens, err := protoTokens(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	f := &ProtoFile{Types: make(map[string]bool)}
+	// scopes are the names of the messages and enums containing the current
+	// token, which are empty for the rest of blocks, such as services.
+	var scopes []string
+	for i := 0; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "package":
+			if len(scopes) == 0 && i+1 < len(tokens) {
+				f.Package = tokens[i+1]
+			}
+		case "message", "enum":
+			if i+2 < len(tokens) && isProtoIdent(tokens[i+1]) && tokens[i+2] == "{" {
+				scopes = append(scopes, tokens[i+1])
+				f.Types[f.fullName(scopes)] = true
+				i += 2
+			}
+		case "{":
+			scopes = append(scopes, "")
+		case "}":
+			if len(scopes) == 0 {
+				return nil, fmt.Errorf("unexpected }")
+			}
+			scopes = scopes[:len(scopes)-1]
+		}
+	}
+
+	if len(scopes) > 0 {
+		return nil, fmt.Errorf("unexpected end of file, expecting }")
+	}
+
+	return f, nil
+}
+
+func (f *ProtoFile) fullName(scopes []string) string {
+	var parts []string
+	if f.Package != "" {
+		parts = append(parts, f.Package)
+	}
+
+	for _, s := range scopes {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// protoTokens splits the given proto file into identifiers, numbers, string
+// literals and symbols, skipping the comments.
+func protoTokens(src string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.Index(src[i:], "\n")
+			if end < 0 {
+				return tokens, nil
+			}
+			i += end + 1
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 4
+		case c == '"' || c == '\'':
+			j := i + 1
+			for ; j < len(src) && src[j] != c; j++ {
+				if src[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, src[i:j+1])
+			i = j + 1
+		case isProtoIdentChar(c):
+			j := i
+			for j < len(src) && isProtoIdentChar(src[j]) {
+				j++
+			}
+			tokens = append(tokens, src[i:j])
+			i = j
+		default:
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func isProtoIdentChar(c byte) bool {
+	return c == '_' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func isProtoIdent(s string) bool {
+	return s != "" && !strings.Contains(s, ".") && (s[0] == '_' || unicode.IsLetter(rune(s[0])))
+}
+
+// ProtoFiles checks that the messages referenced by mappings exist, finding
+// and parsing the proto files they import in a list of proto paths, like
+// protoc does.
+type ProtoFiles struct {
+	paths []string
+	files map[string]*ProtoFile
+}
+
+// NewProtoFiles creates a new ProtoFiles finding the proto files in the given
+// folders, in order.
+func NewProtoFiles(paths ...string) *ProtoFiles {
+	return &ProtoFiles{
+		paths: paths,
+		files: make(map[string]*ProtoFile),
+	}
+}
+
+// wellKnownImports is the prefix of the imports of the well-known types,
+// which are provided by protoc.
+const wellKnownImports = "google/protobuf/"
+
+// Check checks that the message of the given mapping is defined in the proto
+// file it imports. Scalar types, types without import and well-known types
+// are not checked.
+func (p *ProtoFiles) Check(typ *ProtoType) error {
+	if typ.Basic || typ.Import == "" || strings.HasPrefix(typ.Import, wellKnownImports) {
+		return nil
+	}
+
+	f, err := p.load(typ.Import)
+	if err != nil {
+		return err
+	}
+
+	name := typ.Name
+	if typ.Package != "" {
+		name = typ.Package + "." + typ.Name
+	}
+
+	if !f.Types[name] {
+		return fmt.Errorf("message %s is not defined in %s", name, typ.Import)
+	}
+
+	return nil
+}
+
+// load finds and parses the proto file with the given import path.
+func (p *ProtoFiles) load(file string) (*ProtoFile, error) {
+	if f, ok := p.files[file]; ok {
+		return f, nil
+	}
+
+	for _, path := range p.paths {
+		name := filepath.Join(path, filepath.FromSlash(file))
+		if _, err := os.Stat(name); err != nil {
+			continue
+		}
+
+		f, err := LoadProtoFile(name)
+		if err != nil {
+			return nil, err
+		}
+
+		p.files[file] = f
+		return f, nil
+	}
+
+	return nil, fmt.Errorf("proto file %s is not found in the proto paths: %s", file, strings.Join(p.paths, ", "))
+}
@@ -0,0 +1,129 @@
+package protobuf
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProtoDirective(t *testing.T) {
+	require := require.New(t)
+
+	typ, err := ParseProtoDirective(`common.Money import="common/money.proto"`)
+	require.NoError(err)
+	require.Equal(&ProtoType{Package: "common", Name: "Money", Import: "common/money.proto"}, typ)
+
+	typ, err = ParseProtoDirective("Money import=money.proto")
+	require.NoError(err)
+	require.Equal(&ProtoType{Name: "Money", Import: "money.proto"}, typ)
+
+	cases := map[string]string{
+		"":                                 "expecting the full name of a message",
+		"common.Money":                     "has no import",
+		"common.Money common/money.proto":  "expecting name=value",
+		`common.Money file="money.proto"`:  `unknown argument "file"`,
+		`common.Money import="money.proto`: "invalid argument",
+	}
+	for args, msg := range cases {
+		_, err := ParseProtoDirective(args)
+		require.Error(err, args)
+		require.Contains(err.Error(), msg, args)
+	}
+}
+
+const moneyProto = `// Money and friends.
+syntax = "proto3";
+
+package myorg.common;
+
+import "google/protobuf/timestamp.proto";
+
+option go_package = "common";
+
+/* Money is an amount of money
+   in a currency. */
+message Money {
+	option (gogoproto.equal) = true;
+
+	// message Fake {
+	string currency = 1 [json_name = "message Fake {"];
+	int64 units = 2;
+
+	message Rate {
+		enum Kind {
+			FIXED = 0;
+		}
+		oneof value {
+			int64 fixed = 1;
+			string formula = 2;
+		}
+	}
+}
+
+enum Currency {
+	option allow_alias = true;
+	EUR = 0;
+}
+
+service Exchange {
+	rpc Convert(Money) returns (Money) {
+		option (google.api.http) = { post: "/convert" body: "*" };
+	}
+}
+`
+
+func TestParseProtoFile(t *testing.T) {
+	require := require.New(t)
+
+	f, err := ParseProtoFile([]byte(moneyProto))
+	require.NoError(err)
+	require.Equal("myorg.common", f.Package)
+	require.Equal(map[string]bool{
+		"myorg.common.Money":           true,
+		"myorg.common.Money.Rate":      true,
+		"myorg.common.Money.Rate.Kind": true,
+		"myorg.common.Currency":        true,
+	}, f.Types)
+
+	for _, invalid := range []string{
+		"message Foo {",
+		"message Foo {}}",
+		"message Foo { /* }",
+		`message Foo { string a = 1 [json_name = "a}; }`,
+	} {
+		_, err := ParseProtoFile([]byte(invalid))
+		require.Error(err, invalid)
+	}
+}
+
+func TestProtoFilesCheck(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "proteus")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(os.MkdirAll(filepath.Join(dir, "b", "common"), 0755))
+	file := filepath.Join(dir, "b", "common", "money.proto")
+	require.NoError(ioutil.WriteFile(file, []byte(moneyProto), 0644))
+
+	files := NewProtoFiles(filepath.Join(dir, "a"), filepath.Join(dir, "b"))
+	require.NoError(files.Check(&ProtoType{Package: "myorg.common", Name: "Money", Import: "common/money.proto"}))
+	require.NoError(files.Check(&ProtoType{Package: "myorg.common.Money", Name: "Rate", Import: "common/money.proto"}))
+	require.NoError(files.Check(&ProtoType{Name: "string", Basic: true}))
+	require.NoError(files.Check(&ProtoType{Package: "google.protobuf", Name: "Empty", Import: "google/protobuf/empty.proto"}))
+
+	err = files.Check(&ProtoType{Package: "myorg.common", Name: "Price", Import: "common/money.proto"})
+	require.Error(err)
+	require.Equal("message myorg.common.Price is not defined in common/money.proto", err.Error())
+
+	err = files.Check(&ProtoType{Package: "common", Name: "Money", Import: "common/money.proto"})
+	require.Error(err, "the package of the message must match")
+
+	err = files.Check(&ProtoType{Package: "myorg.common", Name: "Money", Import: "money.proto"})
+	require.Error(err)
+	require.Contains(err.Error(), "proto file money.proto is not found in the proto paths")
+}
@@ -1,10 +1,12 @@
 package protobuf // import "gopkg.in/src-d/proteus.v1/protobuf"
 
 import (
+	"bytes"
 	"fmt"
 	"path/filepath"
 	"sort"
 	"strings"
+	"unicode"
 
 	"gopkg.in/src-d/proteus.v1/scanner"
 )
@@ -52,6 +54,22 @@ func (p *Package) ServiceName() string {
 	return strings.ToUpper(string(last[0])) + last[1:] + "Service"
 }
 
+// Message returns the message of the package with the given type or nil if
+// it is not a message of the package.
+func (p *Package) Message(typ Type) *Message {
+	named, ok := typ.(*Named)
+	if !ok || named.Package != p.Name {
+		return nil
+	}
+
+	for _, msg := range p.Messages {
+		if msg.Name == named.Name {
+			return msg
+		}
+	}
+	return nil
+}
+
 // Message is the representation of a Protobuf message.
 type Message struct {
 	Docs     []string
@@ -68,6 +86,21 @@ func (m *Message) Reserve(pos uint) {
 	}
 }
 
+// Field returns the field of the message with the given name or nil if
+// there is none or the message is nil.
+func (m *Message) Field(name string) *Field {
+	if m == nil {
+		return nil
+	}
+
+	for _, f := range m.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
 func (m *Message) isReserved(pos uint) bool {
 	for _, r := range m.Reserved {
 		if r == pos {
@@ -87,6 +120,26 @@ type Field struct {
 	Options  Options
 }
 
+// JSONName returns the name of the field in the JSON mapping of proto3,
+// which is the name of the field in lower camel case.
+func (f *Field) JSONName() string {
+	var buf bytes.Buffer
+	var upper bool
+	for _, r := range f.Name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
 // Options are the set of options given to a field, message or enum value.
 type Options map[string]OptionValue
 
@@ -313,6 +366,8 @@ type Enum struct {
 	Name    string
 	Options Options
 	Values  []*EnumValue
+	// Src is the scanned Go enum.
+	Src *scanner.Enum
 }
 
 // EnumValue is a single value in an enumeration.
@@ -338,4 +393,6 @@ type RPC struct {
 	Input      Type
 	Output     Type
 	Options    Options
+	// Src is the scanned Go function or method of the RPC.
+	Src *scanner.Func
 }
@@ -108,3 +108,30 @@ func TestIsNullable(t *testing.T) {
 	typ = NewMap(notNullableType, notNullableType)
 	require.False(t, typ.IsNullable(), "map<notNullable>NotNullable is not nullable")
 }
+
+func TestFieldJSONName(t *testing.T) {
+	cases := map[string]string{
+		"id":             "id",
+		"first_name":     "firstName",
+		"user_id_number": "userIdNumber",
+		"arg1":           "arg1",
+	}
+
+	for name, expected := range cases {
+		require.Equal(t, expected, (&Field{Name: name}).JSONName(), name)
+	}
+}
+
+func TestPackageMessage(t *testing.T) {
+	user := &Message{Name: "User", Fields: []*Field{{Name: "id"}}}
+	pkg := &Package{Name: "foo", Messages: []*Message{user}}
+
+	require.Equal(t, user, pkg.Message(NewNamed("foo", "User")))
+	require.Nil(t, pkg.Message(NewNamed("bar", "User")))
+	require.Nil(t, pkg.Message(NewNamed("foo", "Group")))
+	require.Nil(t, pkg.Message(NewBasic("string")))
+
+	require.Equal(t, user.Fields[0], user.Field("id"))
+	require.Nil(t, user.Field("name"))
+	require.Nil(t, pkg.Message(NewBasic("string")).Field("id"))
+}
@@ -0,0 +1,125 @@
+// Package protobuftest provides the protobuf package used as fixture by the
+// tests of the generators of other formats from protobuf packages.
+package protobuftest // import "gopkg.in/src-d/proteus.v1/protobuf/protobuftest"
+
+import (
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+const (
+	// Name is the name of the package returned by Package.
+	Name = "example.foo"
+	// Path is the Go path of the package returned by Package.
+	Path = "github.com/example/foo"
+	// ExternalName is the name of the package of the external types.
+	ExternalName = "example.bar"
+	// ExternalPath is the Go path of the package of the external types.
+	ExternalPath = "github.com/example/bar"
+)
+
+// Named returns the message or enum of the package with the given name.
+func Named(name string) *protobuf.Named {
+	return protobuf.NewNamed(Name, name)
+}
+
+// Generated returns the generated message of the package with the given
+// name.
+func Generated(name string) *protobuf.Named {
+	return protobuf.NewGeneratedNamed(Name, name)
+}
+
+// External returns the message of the external package with the given name,
+// which is not nullable.
+func External(name string) *protobuf.Named {
+	typ := protobuf.NewNamed(ExternalName, name)
+	typ.SetSource(scanner.NewNamed(ExternalPath, name))
+	return typ
+}
+
+// NotNullable returns the given type, whose Go type is not nullable.
+func NotNullable(typ *protobuf.Named) *protobuf.Named {
+	typ.SetSource(scanner.NewNamed("", typ.Name))
+	return typ
+}
+
+// Package returns a package with messages, enums and RPCs using every kind
+// of type: scalars, repeated fields, maps, messages and enums of the package,
+// well-known types and messages of the external package. Every call returns
+// a new package, so tests can modify it.
+func Package() *protobuf.Package {
+	return &protobuf.Package{
+		Name: Name,
+		Path: Path,
+		Messages: []*protobuf.Message{
+			{
+				Docs: []string{"User is an user."},
+				Name: "User",
+				Fields: []*protobuf.Field{
+					{Name: "id", Pos: 1, Type: protobuf.NewBasic("uint64"), Docs: []string{"ID of the user."}},
+					{Name: "first_name", Pos: 2, Type: protobuf.NewBasic("string")},
+					{Name: "age", Pos: 3, Type: protobuf.NewBasic("uint32")},
+					{Name: "status", Pos: 4, Type: Named("Status")},
+					{Name: "address", Pos: 5, Type: Named("Address"), Docs: []string{"Address of the user."}},
+					{Name: "tags", Pos: 6, Type: protobuf.NewBasic("string"), Repeated: true},
+					{Name: "created_at", Pos: 7, Type: protobuf.NewNamed("google.protobuf", "Timestamp")},
+					{Name: "nickname", Pos: 8, Type: protobuf.NewNamed("google.protobuf", "StringValue")},
+					{Name: "score", Pos: 9, Type: protobuf.NewNamed("google.protobuf", "DoubleValue")},
+					{Name: "labels", Pos: 10, Type: protobuf.NewMap(protobuf.NewBasic("string"), protobuf.NewBasic("int32"))},
+					{Name: "group", Pos: 11, Type: External("Group")},
+					{Name: "avatar", Pos: 12, Type: protobuf.NewBasic("bytes")},
+				},
+			},
+			{
+				Name: "Address",
+				Fields: []*protobuf.Field{
+					{Name: "city", Pos: 1, Type: protobuf.NewBasic("string")},
+				},
+			},
+			{
+				Name: "GetUserRequest",
+				Fields: []*protobuf.Field{
+					{Name: "arg1", Pos: 1, Type: protobuf.NewBasic("uint64")},
+					{Name: "arg2", Pos: 2, Type: Named("Status")},
+					{Name: "arg3", Pos: 3, Type: Named("Address")},
+				},
+			},
+		},
+		Enums: []*protobuf.Enum{
+			{
+				Docs: []string{"Status of an user."},
+				Name: "Status",
+				Values: []*protobuf.EnumValue{
+					{Name: "ACTIVE", Value: 0, Docs: []string{"ACTIVE users can log in."}},
+					{Name: "BANNED", Value: 1},
+				},
+			},
+		},
+		RPCs: []*protobuf.RPC{
+			{
+				Docs:     []string{"GetUser returns an user."},
+				Name:     "GetUser",
+				HasError: true,
+				Input:    Generated("GetUserRequest"),
+				Output:   Named("User"),
+				Options: protobuf.Options{
+					"(google.api.http).get": protobuf.NewStringValue("/v1/users/{arg1}"),
+				},
+			},
+			{
+				Name:   "UpdateAddress",
+				Input:  Named("User"),
+				Output: Named("User"),
+				Options: protobuf.Options{
+					"(google.api.http).patch": protobuf.NewStringValue("/v1/users/{id}/cities/{address.city=*}"),
+					"(google.api.http).body":  protobuf.NewStringValue("address"),
+				},
+			},
+			{
+				Name:   "CreateUser",
+				Input:  Named("User"),
+				Output: Named("User"),
+			},
+		},
+	}
+}
@@ -0,0 +1,135 @@
+package protobuf
+
+import (
+	"sort"
+	"strings"
+
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+// typeOption is the field tag option to override the protobuf scalar type
+// of a field, e.g. `proteus:"type=sint64"`.
+const typeOption = "type"
+
+// scalarKind is the kind of number a Go or protobuf scalar type holds, along
+// with its size in bits.
+type scalarKind struct {
+	class string
+	bits  int
+}
+
+// goScalarKinds contains the kind of the Go basic types whose protobuf type
+// can be overridden.
+var goScalarKinds = map[string]scalarKind{
+	"int":     {"int", 64},
+	"int8":    {"int", 8},
+	"int16":   {"int", 16},
+	"int32":   {"int", 32},
+	"rune":    {"int", 32},
+	"int64":   {"int", 64},
+	"uint":    {"uint", 64},
+	"uint8":   {"uint", 8},
+	"byte":    {"uint", 8},
+	"uint16":  {"uint", 16},
+	"uint32":  {"uint", 32},
+	"uint64":  {"uint", 64},
+	"uintptr": {"uint", 64},
+	"float32": {"float", 32},
+	"float64": {"float", 64},
+	"bool":    {"bool", 0},
+	"string":  {"string", 0},
+}
+
+// protoScalars contains the kind of each protobuf scalar type and the Go
+// type gogo/protobuf generates for it.
+var protoScalars = map[string]struct {
+	scalarKind
+	goType string
+}{
+	"int32":    {scalarKind{"int", 32}, "int32"},
+	"sint32":   {scalarKind{"int", 32}, "int32"},
+	"sfixed32": {scalarKind{"int", 32}, "int32"},
+	"int64":    {scalarKind{"int", 64}, "int64"},
+	"sint64":   {scalarKind{"int", 64}, "int64"},
+	"sfixed64": {scalarKind{"int", 64}, "int64"},
+	"uint32":   {scalarKind{"uint", 32}, "uint32"},
+	"fixed32":  {scalarKind{"uint", 32}, "uint32"},
+	"uint64":   {scalarKind{"uint", 64}, "uint64"},
+	"fixed64":  {scalarKind{"uint", 64}, "uint64"},
+	"float":    {scalarKind{"float", 32}, "float32"},
+	"double":   {scalarKind{"float", 64}, "float64"},
+	"bool":     {scalarKind{"bool", 0}, "bool"},
+	"string":   {scalarKind{"string", 0}, "string"},
+}
+
+// compatibleScalars returns the protobuf scalar types that can hold all the
+// values of the given Go basic type, sorted by name.
+func compatibleScalars(goType string) []string {
+	kind, ok := goScalarKinds[goType]
+	if !ok {
+		return nil
+	}
+
+	var scalars []string
+	for name, s := range protoScalars {
+		if s.class == kind.class && s.bits >= kind.bits {
+			scalars = append(scalars, name)
+		}
+	}
+	sort.Strings(scalars)
+	return scalars
+}
+
+// scalarOverride returns the protobuf scalar type given in the type option
+// of the field tag, if any, decorating the field with a casttype to keep its
+// Go type when needed. Otherwise, it returns nil and the default mapping
+// must be used. If the type cannot be overridden or the given type is not
+// compatible with the Go type of the field, an error is reported and
+// returned by Transformer.Err.
+func (t *Transformer) scalarOverride(pkg *Package, field *scanner.Field, f *Field) Type {
+	scalar, ok := field.Tag(typeOption)
+	if !ok {
+		return nil
+	}
+
+	var basic, goType string
+	switch ty := field.Type.(type) {
+	case *scanner.Basic:
+		basic, goType = ty.Name, ty.Name
+	case *scanner.Alias:
+		if b, ok := ty.Underlying.(*scanner.Basic); ok && !ty.IsRepeated() {
+			basic, goType = b.Name, castType(pkg, ty.Type)
+		}
+	}
+
+	compatible := compatibleScalars(basic)
+	if len(compatible) == 0 {
+		t.addError("field %q has type %s, whose protobuf type cannot be overridden with type %q", field.Name, field.Type, scalar)
+		return nil
+	}
+
+	if !containsString(compatible, scalar) {
+		t.addError("type %q of field %q is not compatible with %s, expecting one of: %s", scalar, field.Name, basic, strings.Join(compatible, ", "))
+		return nil
+	}
+
+	if goType != protoScalars[scalar].goType {
+		if f.Options == nil {
+			f.Options = make(Options)
+		}
+		f.Options["(gogoproto.casttype)"] = NewStringValue(goType)
+	}
+
+	b := NewBasic(scalar)
+	b.SetSource(field.Type)
+	return b
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package protobuf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+func TestCompatibleScalars(t *testing.T) {
+	require.Equal(t, []string{"int32", "int64", "sfixed32", "sfixed64", "sint32", "sint64"}, compatibleScalars("int16"))
+	require.Equal(t, []string{"int64", "sfixed64", "sint64"}, compatibleScalars("int"))
+	require.Equal(t, []string{"fixed32", "fixed64", "uint32", "uint64"}, compatibleScalars("uint32"))
+	require.Equal(t, []string{"double"}, compatibleScalars("float64"))
+	require.Equal(t, []string{"double", "float"}, compatibleScalars("float32"))
+	require.Nil(t, compatibleScalars("complex64"))
+}
+
+func TestScalarOverride(t *testing.T) {
+	cases := []struct {
+		name     string
+		typ      scanner.Type
+		scalar   string
+		expected string
+		castType string
+	}{
+		{"Int64", scanner.NewBasic("int64"), "sint64", "sint64", ""},
+		{"Int", scanner.NewBasic("int"), "sfixed64", "sfixed64", "int"},
+		{"Int16", scanner.NewBasic("int16"), "sint32", "sint32", "int16"},
+		{"Uint32", scanner.NewBasic("uint32"), "fixed32", "fixed32", ""},
+		{"Uint64", scanner.NewBasic("uint64"), "fixed64", "fixed64", ""},
+		{"Float32", scanner.NewBasic("float32"), "double", "double", "float32"},
+		{
+			"Alias",
+			scanner.NewAlias(scanner.NewNamed("foo", "Hash"), scanner.NewBasic("uint64")),
+			"fixed64", "fixed64", "Hash",
+		},
+		{
+			"ExternalAlias",
+			scanner.NewAlias(scanner.NewNamed("bar", "ID"), scanner.NewBasic("int32")),
+			"sint32", "sint32", "bar.ID",
+		},
+		{"Narrower", scanner.NewBasic("int64"), "sint32", "", ""},
+		{"Signedness", scanner.NewBasic("int64"), "fixed64", "", ""},
+		{"Unknown", scanner.NewBasic("int64"), "varint", "", ""},
+		{"Unsupported", scanner.NewNamed("foo", "Bar"), "int32", "", ""},
+	}
+
+	report.TestMode()
+	defer report.EndTestMode()
+
+	tr := NewTransformer()
+	for _, c := range cases {
+		f := &Field{Name: c.name}
+		typ := tr.scalarOverride(&Package{Path: "foo"}, &scanner.Field{
+			Name: c.name,
+			Type: c.typ,
+			Tags: []string{"type=" + c.scalar},
+		}, f)
+
+		if c.expected == "" {
+			require.Nil(t, typ, c.name)
+			continue
+		}
+
+		require.Equal(t, NewBasic(c.expected).String(), typ.String(), c.name)
+		require.Equal(t, c.typ, typ.Source(), c.name)
+		if c.castType == "" {
+			require.NotContains(t, f.Options, "(gogoproto.casttype)", c.name)
+		} else {
+			require.Equal(t, NewStringValue(c.castType), f.Options["(gogoproto.casttype)"], c.name)
+		}
+	}
+
+	require.Len(t, report.MessageStack(), 4, "invalid overrides are reported")
+	require.Len(t, tr.errs, 4, "invalid overrides are errors")
+}
+
+func TestTransformFieldScalarOverride(t *testing.T) {
+	report.TestMode()
+	defer report.EndTestMode()
+
+	f := NewTransformer().transformField(&Package{}, &Message{}, &scanner.Field{
+		Name: "Balance",
+		Type: scanner.NewBasic("int64"),
+		Tags: []string{"type=sint64"},
+	}, 1)
+
+	require.Equal(t, "sint64", f.Type.String())
+	require.Empty(t, f.Options)
+
+	tr := NewTransformer()
+	f = tr.transformField(&Package{}, &Message{}, &scanner.Field{
+		Name: "Balance",
+		Type: scanner.NewBasic("int64"),
+		Tags: []string{"type=sint32"},
+	}, 1)
+
+	require.Equal(t, "int64", f.Type.String(), "falls back to the default mapping")
+	require.EqualError(t, tr.Err(), `type "sint32" of field "Balance" is not compatible with int64, expecting one of: int64, sfixed64, sint64`)
+}
@@ -0,0 +1,163 @@
+package protobuf
+
+import (
+	"fmt"
+
+	"gopkg.in/src-d/proteus.v1/scanner"
+	"gopkg.in/src-d/proteus.v1/stdtypes"
+)
+
+// StdlibMappings is the built-in pack of mappings for commonly used types of
+// the Go standard library. net.IP and encoding/json.RawMessage are mapped to
+// `bytes` directly. The rest of the types cannot be used as gogo/protobuf
+// custom types, so only their wrappers in the stdtypes package are mapped,
+// which must be used as the type of the fields instead. The wrappers of the
+// null types of database/sql are mapped to the well-known wrapper types and
+// the rest of them to `bytes`. The wire representation of each one of them
+// is documented in the stdtypes package. The alternative representations of
+// time.Time and time.Duration of the stdtypes package are mapped as well.
+// These mappings are used by the Transformer unless they are disabled with
+// DisableStdlibMappings.
+var StdlibMappings = newStdlibMappings()
+
+func newStdlibMappings() TypeMappings {
+	mappings := make(TypeMappings)
+	for name, wrapper := range stdtypes.Types {
+		if wrapper == "" {
+			mappings[name] = &ProtoType{
+				Name:       "bytes",
+				Basic:      true,
+				Decorators: CastToBasicType(name),
+			}
+			continue
+		}
+
+		key := stdtypes.WrapperName(name, wrapper)
+		wrapper = fmt.Sprintf("%s.%s", stdtypes.Path, wrapper)
+		typ := &ProtoType{
+			Name:       "bytes",
+			Basic:      true,
+			Decorators: CustomType(wrapper),
+		}
+
+		if wkt, ok := stdlibWellKnownTypes[name]; ok {
+			typ = &ProtoType{
+				Name:       wkt,
+				Package:    "google.protobuf",
+				Import:     wellKnownTypeImport(wkt),
+				GoImport:   "github.com/gogo/protobuf/types",
+				Decorators: CustomType(wrapper),
+			}
+		}
+
+		mappings[key] = typ
+	}
+
+	for name, scalar := range stdtypes.TimeTypes {
+		name = fmt.Sprintf("%s.%s", stdtypes.Path, name)
+		mappings[name] = timeScalar(scalar, name)
+	}
+	return mappings
+}
+
+// stdlibWellKnownTypes contains the well-known type used to represent the
+// standard library types that are not mapped to bytes.
+var stdlibWellKnownTypes = map[string]string{
+	"database/sql.NullString":      "StringValue",
+	"database/sql.NullInt64":       "Int64Value",
+	"database/sql.NullInt32":       "Int32Value",
+	"database/sql.NullInt16":       "Int32Value",
+	"database/sql.NullByte":        "UInt32Value",
+	"database/sql.NullFloat64":     "DoubleValue",
+	"database/sql.NullBool":        "BoolValue",
+	"database/sql.NullTime":        "Timestamp",
+	"database/sql.Null[string]":    "StringValue",
+	"database/sql.Null[int64]":     "Int64Value",
+	"database/sql.Null[int32]":     "Int32Value",
+	"database/sql.Null[int16]":     "Int32Value",
+	"database/sql.Null[uint8]":     "UInt32Value",
+	"database/sql.Null[float64]":   "DoubleValue",
+	"database/sql.Null[bool]":      "BoolValue",
+	"database/sql.Null[time.Time]": "Timestamp",
+}
+
+// checkNullType reports an error if the Go type of the given field is one of
+// the null types of database/sql, which cannot be used as custom types, or
+// one of their wrappers in the stdtypes package that is not a pointer, as
+// only the presence of the field tells whether the value is NULL. It returns
+// whether the field can be transformed.
+func (t *Transformer) checkNullType(field *scanner.Field) bool {
+	named, ok := field.Type.(*scanner.Named)
+	if !t.stdlib || !ok || named.IsRepeated() {
+		return true
+	}
+
+	name := named.InstanceName()
+	for sqlType, wrapper := range stdtypes.Types {
+		if _, ok := stdlibWellKnownTypes[sqlType]; !ok {
+			continue
+		}
+
+		switch name {
+		case sqlType:
+			t.addError(
+				"field %q is a %s, which cannot be used as a protobuf custom type, use *%s.%s instead",
+				field.Name, sqlType, stdtypes.Path, wrapper,
+			)
+			return false
+		case stdtypes.WrapperName(sqlType, wrapper):
+			if !named.IsNullable() {
+				t.addError(
+					"field %q must be a *%s.%s, a nil pointer is a NULL value and any other value is sent as valid",
+					field.Name, stdtypes.Path, wrapper,
+				)
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// wrapperScalars contains the scalar type wrapped by every well-known
+// wrapper type.
+var wrapperScalars = map[string]string{
+	"DoubleValue": "double",
+	"FloatValue":  "float",
+	"Int64Value":  "int64",
+	"UInt64Value": "uint64",
+	"Int32Value":  "int32",
+	"UInt32Value": "uint32",
+	"BoolValue":   "bool",
+	"StringValue": "string",
+	"BytesValue":  "bytes",
+}
+
+// WrapperScalar returns the scalar type wrapped by the well-known wrapper
+// type of the google.protobuf package with the given name, such as string
+// for StringValue, and whether the name is a wrapper type.
+func WrapperScalar(name string) (string, bool) {
+	scalar, ok := wrapperScalars[name]
+	return scalar, ok
+}
+
+func wellKnownTypeImport(name string) string {
+	if name == "Timestamp" {
+		return "google/protobuf/timestamp.proto"
+	}
+	return "google/protobuf/wrappers.proto"
+}
+
+// CustomType returns the decorators to set the given Go type as the
+// gogoproto.customtype of a field.
+func CustomType(typ string) Decorators {
+	return NewDecorators(
+		func(p *Package, m *Message, f *Field) {
+			if f.Options == nil {
+				f.Options = make(Options)
+			}
+
+			f.Options["(gogoproto.customtype)"] = NewStringValue(typ)
+		},
+	)
+}
@@ -0,0 +1,113 @@
+package protobuf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/scanner"
+	"gopkg.in/src-d/proteus.v1/stdtypes"
+)
+
+func TestStdlibMappings(t *testing.T) {
+	cases := []struct {
+		name    string
+		option  string
+		goType  string
+		wrapper string
+	}{
+		{"net/url.URL", "(gogoproto.customtype)", "gopkg.in/src-d/proteus.v1/stdtypes.URL", "gopkg.in/src-d/proteus.v1/stdtypes.URL"},
+		{"math/big.Int", "(gogoproto.customtype)", "gopkg.in/src-d/proteus.v1/stdtypes.BigInt", "gopkg.in/src-d/proteus.v1/stdtypes.BigInt"},
+		{"math/big.Rat", "(gogoproto.customtype)", "gopkg.in/src-d/proteus.v1/stdtypes.BigRat", "gopkg.in/src-d/proteus.v1/stdtypes.BigRat"},
+		{"net/netip.Addr", "(gogoproto.customtype)", "gopkg.in/src-d/proteus.v1/stdtypes.Addr", "gopkg.in/src-d/proteus.v1/stdtypes.Addr"},
+		{"time.Location", "(gogoproto.customtype)", "gopkg.in/src-d/proteus.v1/stdtypes.Location", "gopkg.in/src-d/proteus.v1/stdtypes.Location"},
+		{"regexp.Regexp", "(gogoproto.customtype)", "gopkg.in/src-d/proteus.v1/stdtypes.Regexp", "gopkg.in/src-d/proteus.v1/stdtypes.Regexp"},
+		{"net.IP", "(gogoproto.casttype)", "net.IP", ""},
+		{"encoding/json.RawMessage", "(gogoproto.casttype)", "encoding/json.RawMessage", ""},
+	}
+
+	for _, c := range cases {
+		name := c.name
+		if c.wrapper != "" {
+			_, ok := StdlibMappings[c.name]
+			assert.False(t, ok, "%s cannot be a custom type, so only its wrapper is mapped", c.name)
+			name = c.wrapper
+		}
+
+		typ, ok := StdlibMappings[name]
+		assert.True(t, ok, "mapping for %s", name)
+		assert.Equal(t, "bytes", typ.Name, "proto type of %s", name)
+		assert.True(t, typ.Basic, "%s is basic", name)
+
+		f := new(Field)
+		typ.Decorators.Run(&Package{}, &Message{}, f)
+		assert.Equal(t, NewStringValue(c.goType), f.Options[c.option], "option of %s", name)
+	}
+}
+
+func TestStdlibMappingsNullTypes(t *testing.T) {
+	cases := []struct {
+		name    string
+		wkt     string
+		imp     string
+		wrapper string
+	}{
+		{"database/sql.NullString", "StringValue", "google/protobuf/wrappers.proto", "NullString"},
+		{"database/sql.NullInt16", "Int32Value", "google/protobuf/wrappers.proto", "NullInt16"},
+		{"database/sql.NullByte", "UInt32Value", "google/protobuf/wrappers.proto", "NullByte"},
+		{"database/sql.NullTime", "Timestamp", "google/protobuf/timestamp.proto", "NullTime"},
+		{"database/sql.Null[float64]", "DoubleValue", "google/protobuf/wrappers.proto", "NullOfFloat64"},
+		{"database/sql.Null[time.Time]", "Timestamp", "google/protobuf/timestamp.proto", "NullOfTime"},
+	}
+
+	for _, c := range cases {
+		_, ok := StdlibMappings[c.name]
+		assert.False(t, ok, "only the wrapper of %s is mapped", c.name)
+
+		typ, ok := StdlibMappings[stdtypes.WrapperName(c.name, c.wrapper)]
+		assert.True(t, ok, "mapping for the wrapper of %s", c.name)
+		assert.False(t, typ.Basic, "%s is not basic", c.name)
+		assert.Equal(t, "google.protobuf", typ.Package, "package of %s", c.name)
+		assert.Equal(t, c.wkt, typ.Name, "proto type of %s", c.name)
+		assert.Equal(t, c.imp, typ.Import, "import of %s", c.name)
+
+		f := new(Field)
+		typ.Decorators.Run(&Package{}, &Message{}, f)
+		assert.Equal(t, NewStringValue("gopkg.in/src-d/proteus.v1/stdtypes."+c.wrapper), f.Options["(gogoproto.customtype)"], "customtype of %s", c.name)
+	}
+
+	_, ok := StdlibMappings["gopkg.in/src-d/proteus.v1/stdtypes.NullOfFloat64"]
+	assert.False(t, ok, "the aliases of the instantiations of Null are not scanned, so they are not mapped")
+	assert.NotNil(t, StdlibMappings["gopkg.in/src-d/proteus.v1/stdtypes.Null[float64]"])
+}
+
+func TestCheckNullType(t *testing.T) {
+	report.TestMode()
+	defer report.EndTestMode()
+
+	wrapper := scanner.NewNamed(stdtypes.Path, "NullString")
+	wrapper.SetNullable(true)
+	sqlType := scanner.NewNamed("database/sql", "NullInt64")
+
+	tr := NewTransformer()
+	assert.True(t, tr.checkNullType(&scanner.Field{Name: "A", Type: wrapper}))
+	assert.True(t, tr.checkNullType(&scanner.Field{Name: "B", Type: scanner.NewNamed("net", "IP")}))
+	assert.Nil(t, tr.Err())
+
+	assert.False(t, tr.checkNullType(&scanner.Field{Name: "C", Type: sqlType}))
+	assert.False(t, tr.checkNullType(&scanner.Field{Name: "D", Type: scanner.NewNamed(stdtypes.Path, "NullInt64")}))
+	assert.Len(t, tr.errs, 2)
+
+	tr.DisableStdlibMappings()
+	assert.True(t, tr.checkNullType(&scanner.Field{Name: "C", Type: sqlType}), "the null types are not checked without the stdlib mappings")
+}
+
+func TestWrapperScalar(t *testing.T) {
+	scalar, ok := WrapperScalar("UInt32Value")
+	assert.True(t, ok)
+	assert.Equal(t, "uint32", scalar)
+
+	_, ok = WrapperScalar("Timestamp")
+	assert.False(t, ok)
+}
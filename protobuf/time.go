@@ -0,0 +1,152 @@
+package protobuf
+
+import (
+	"fmt"
+
+	"gopkg.in/src-d/proteus.v1/scanner"
+	"gopkg.in/src-d/proteus.v1/stdtypes"
+)
+
+// TimeFormat is the representation used in protobuf for time.Time and
+// time.Duration.
+type TimeFormat string
+
+const (
+	// TimeWellKnown represents times as google.protobuf.Timestamp and
+	// durations as google.protobuf.Duration. This is the default.
+	TimeWellKnown TimeFormat = "wkt"
+	// TimeSeconds represents times as the int64 number of seconds since the
+	// Unix epoch and durations as the int64 number of seconds.
+	TimeSeconds TimeFormat = "seconds"
+	// TimeMillis represents times as the int64 number of milliseconds since
+	// the Unix epoch and durations as the int64 number of milliseconds.
+	TimeMillis TimeFormat = "millis"
+	// TimeNanos represents times as the int64 number of nanoseconds since the
+	// Unix epoch and durations as the int64 number of nanoseconds.
+	TimeNanos TimeFormat = "nanos"
+	// TimeString represents times as RFC 3339 strings and durations as
+	// strings in the format of time.Duration.String.
+	TimeString TimeFormat = "string"
+)
+
+const (
+	// timeDirective is the package directive and field tag option to set the
+	// format of time.Time.
+	timeDirective = "time"
+	// durationDirective is the package directive and field tag option to set
+	// the format of time.Duration.
+	durationDirective = "duration"
+)
+
+// ParseTimeFormat returns the TimeFormat with the given name or an error if
+// there is no such format.
+func ParseTimeFormat(name string) (TimeFormat, error) {
+	switch f := TimeFormat(name); f {
+	case TimeWellKnown, TimeSeconds, TimeMillis, TimeNanos, TimeString:
+		return f, nil
+	}
+	return "", fmt.Errorf("invalid time format %q, expecting one of: wkt, seconds, millis, nanos, string", name)
+}
+
+// timeFormats holds the formats of time.Time and time.Duration.
+type timeFormats struct {
+	time     TimeFormat
+	duration TimeFormat
+}
+
+// withTimeDirectives returns the given formats overridden by the given
+// directives or field tags. Invalid formats are reported as errors, which
+// are returned by Err, and the given ones are kept.
+func (t *Transformer) withTimeDirectives(f timeFormats, get func(string) (string, bool), where string) timeFormats {
+	if name, ok := get(timeDirective); ok {
+		f.time = t.parseTimeFormat(name, f.time, where)
+	}
+
+	if name, ok := get(durationDirective); ok {
+		f.duration = t.parseTimeFormat(name, f.duration, where)
+	}
+
+	return f
+}
+
+func (t *Transformer) parseTimeFormat(name string, def TimeFormat, where string) TimeFormat {
+	f, err := ParseTimeFormat(name)
+	if err != nil {
+		t.addError("%s in %s", err, where)
+		return def
+	}
+	return f
+}
+
+// timeWrappers contains the stdtypes type that represents time.Time and
+// time.Duration in each one of the formats that are not well-known types.
+var timeWrappers = map[string]map[TimeFormat]string{
+	"time.Time": {
+		TimeSeconds: "UnixSeconds",
+		TimeMillis:  "UnixMillis",
+		TimeNanos:   "UnixNanos",
+		TimeString:  "RFC3339",
+	},
+	"time.Duration": {
+		TimeSeconds: "DurationSeconds",
+		TimeMillis:  "DurationMillis",
+		TimeString:  "DurationString",
+	},
+}
+
+// timeMapping returns the mapping of the given field if it is a time.Duration
+// represented in nanoseconds, or nil otherwise. The rest of formats that are
+// not the well-known type need the Go type of the field to be their stdtypes
+// type, which is mapped on its own, so an error is reported for the
+// time.Time and time.Duration fields using them and they are represented as
+// the well-known type.
+func (t *Transformer) timeMapping(field *scanner.Field) *ProtoType {
+	named, ok := field.Type.(*scanner.Named)
+	if !ok {
+		return nil
+	}
+
+	name := named.String()
+	formats := t.withTimeDirectives(t.timeFormats, field.Tag, fmt.Sprintf("field %q", field.Name))
+	format := formats.time
+	if name == "time.Duration" {
+		format = formats.duration
+	} else if name != "time.Time" {
+		return nil
+	}
+
+	if format == TimeWellKnown {
+		return nil
+	}
+
+	// time.Duration already has the Go representation of nanoseconds.
+	if name == "time.Duration" && format == TimeNanos {
+		return timeScalar("int64", name)
+	}
+
+	t.addError(
+		"field %q is represented as %s, so its type must be %s.%s instead of %s",
+		field.Name, format, stdtypes.Path, timeWrappers[name][format], name,
+	)
+	return nil
+}
+
+// timeScalar returns the mapping of a time representation to the given
+// protobuf scalar with a casttype to the given Go type.
+func timeScalar(scalar, goType string) *ProtoType {
+	return &ProtoType{
+		Name:  scalar,
+		Basic: true,
+		Decorators: NewDecorators(
+			func(p *Package, m *Message, f *Field) {
+				if f.Options == nil {
+					f.Options = make(Options)
+				}
+
+				f.Options["(gogoproto.casttype)"] = NewStringValue(goType)
+				// scalars are never nullable in proto3
+				delete(f.Options, "(gogoproto.nullable)")
+			},
+		),
+	}
+}
@@ -0,0 +1,115 @@
+package protobuf
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+func TestParseTimeFormat(t *testing.T) {
+	for _, name := range []string{"wkt", "seconds", "millis", "nanos", "string"} {
+		f, err := ParseTimeFormat(name)
+		require.Nil(t, err, name)
+		require.Equal(t, TimeFormat(name), f)
+	}
+
+	_, err := ParseTimeFormat("days")
+	require.NotNil(t, err)
+}
+
+func TestTransformTimeFormats(t *testing.T) {
+	report.TestMode()
+	defer report.EndTestMode()
+
+	const stdtypes = "gopkg.in/src-d/proteus.v1/stdtypes"
+	cases := []struct {
+		name     string
+		typ      scanner.Type
+		tags     []string
+		expected Type
+		castType string
+		format   TimeFormat
+		wrapper  string
+	}{
+		{"Default", scanner.NewNamed("time", "Time"), nil, NewNamed("google.protobuf", "Timestamp"), "", TimeMillis, "UnixMillis"},
+		{"Seconds", scanner.NewNamed("time", "Time"), []string{"time=seconds"}, NewNamed("google.protobuf", "Timestamp"), "", TimeSeconds, "UnixSeconds"},
+		{"String", scanner.NewNamed("time", "Time"), []string{"time=string"}, NewNamed("google.protobuf", "Timestamp"), "", TimeString, "RFC3339"},
+		{"Timestamp", scanner.NewNamed("time", "Time"), []string{"time=wkt"}, NewNamed("google.protobuf", "Timestamp"), "", "", ""},
+		{"DefaultDuration", scanner.NewNamed("time", "Duration"), nil, NewNamed("google.protobuf", "Duration"), "", TimeString, "DurationString"},
+		{"DurationNanos", scanner.NewNamed("time", "Duration"), []string{"duration=nanos"}, NewBasic("int64"), "time.Duration", "", ""},
+		{"DurationMillis", scanner.NewNamed("time", "Duration"), []string{"duration=millis"}, NewNamed("google.protobuf", "Duration"), "", TimeMillis, "DurationMillis"},
+		{"DurationWithTimeTag", scanner.NewNamed("time", "Duration"), []string{"time=wkt"}, NewNamed("google.protobuf", "Duration"), "", TimeString, "DurationString"},
+		{"Wrapper", scanner.NewNamed(stdtypes, "UnixMillis"), nil, NewBasic("int64"), stdtypes + ".UnixMillis", "", ""},
+		{"WrapperWithTag", scanner.NewNamed(stdtypes, "RFC3339"), []string{"time=wkt"}, NewBasic("string"), stdtypes + ".RFC3339", "", ""},
+	}
+
+	fields := make([]*scanner.Field, len(cases))
+	var errs []string
+	for i, c := range cases {
+		fields[i] = &scanner.Field{Name: c.name, Type: c.typ, Tags: c.tags}
+		if c.wrapper != "" {
+			errs = append(errs, fmt.Sprintf(
+				"field %q is represented as %s, so its type must be %s.%s instead of %s",
+				c.name, c.format, stdtypes, c.wrapper, c.typ,
+			))
+		}
+	}
+
+	tr := NewTransformer()
+	tr.SetTimeFormat(TimeSeconds)
+	pkg := tr.Transform(&scanner.Package{
+		Path: "foo",
+		Name: "foo",
+		Directives: scanner.Directives{
+			"time":     {"millis"},
+			"duration": {"string"},
+		},
+		Structs: []*scanner.Struct{{Name: "Foo", Fields: fields}},
+	})
+
+	require.Len(t, pkg.Messages[0].Fields, len(cases))
+	for i, c := range cases {
+		f := pkg.Messages[0].Fields[i]
+		require.Equal(t, c.expected.String(), f.Type.String(), c.name)
+		if c.castType == "" {
+			require.NotContains(t, f.Options, "(gogoproto.casttype)", c.name)
+		} else {
+			require.Equal(t, NewStringValue(c.castType), f.Options["(gogoproto.casttype)"], c.name)
+			require.NotContains(t, f.Options, "(gogoproto.nullable)", c.name)
+		}
+	}
+
+	require.Equal(t, errs, tr.errs, "time.Time and time.Duration cannot be cast to the stdtypes types")
+	require.Equal(t, TimeSeconds, tr.timeFormats.time, "package directives are reset")
+	require.Equal(t, TimeWellKnown, tr.timeFormats.duration, "package directives are reset")
+}
+
+func TestTransformInvalidTimeFormats(t *testing.T) {
+	report.TestMode()
+	defer report.EndTestMode()
+
+	tr := NewTransformer()
+	pkg := tr.Transform(&scanner.Package{
+		Path: "foo",
+		Name: "foo",
+		Directives: scanner.Directives{
+			"time": {"days"},
+		},
+		Structs: []*scanner.Struct{{Name: "Foo", Fields: []*scanner.Field{
+			{Name: "Created", Type: scanner.NewNamed("time", "Time")},
+			{Name: "Timeout", Type: scanner.NewNamed("time", "Duration"), Tags: []string{"duration=hours"}},
+		}}},
+	})
+
+	fields := pkg.Messages[0].Fields
+	require.Equal(t, "google.protobuf.Timestamp", fields[0].Type.String(), "the default format is kept")
+	require.Equal(t, "google.protobuf.Duration", fields[1].Type.String(), "the default format is kept")
+	require.Equal(t, []string{
+		`invalid time format "days", expecting one of: wkt, seconds, millis, nanos, string in package "foo"`,
+		`invalid time format "hours", expecting one of: wkt, seconds, millis, nanos, string in field "Timeout"`,
+	}, tr.errs)
+}
@@ -25,15 +25,51 @@ type Transformer struct {
 	mappings  TypeMappings
 	structSet TypeSet
 	enumSet   TypeSet
+	stdlib    bool
+	// pkgMappings are the custom mappings of every package, by path, and
+	// currentMappings the ones of the package being transformed.
+	pkgMappings     map[string]TypeMappings
+	currentMappings TypeMappings
+	// timeFormats are the formats of time.Time and time.Duration for the
+	// package being transformed.
+	timeFormats timeFormats
+	validation  Validation
+	hooks       Hooks
+	// errs are the invalid directives, the fields whose Go types cannot be
+	// represented and the messages and enums renamed by the hooks found in
+	// the transformed packages.
+	errs []string
 }
 
 // NewTransformer creates a new transformer instance.
 func NewTransformer() *Transformer {
 	return &Transformer{
 		mappings: make(TypeMappings),
+		stdlib:   true,
+		timeFormats: timeFormats{
+			time:     TimeWellKnown,
+			duration: TimeWellKnown,
+		},
 	}
 }
 
+// Err returns an error with all the invalid directives and the fields whose
+// Go types cannot be represented found in the transformed packages, or nil
+// if there are none.
+func (t *Transformer) Err() error {
+	if len(t.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s", strings.Join(t.errs, "\n"))
+}
+
+// addError reports the given error and records it, so it is returned by Err.
+func (t *Transformer) addError(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	report.Error("%s", msg)
+	t.errs = append(t.errs, msg)
+}
+
 // SetMappings will set the custom mappings of the transformer. If nil is
 // provided, the change will be ignored.
 func (t *Transformer) SetMappings(m TypeMappings) {
@@ -43,6 +79,55 @@ func (t *Transformer) SetMappings(m TypeMappings) {
 	t.mappings = m
 }
 
+// SetPackageMappings sets the custom mappings of the package with the given
+// path, which are only used in that package and take precedence over the
+// rest of mappings. If nil is provided, the package will use the custom
+// mappings of the transformer.
+func (t *Transformer) SetPackageMappings(path string, m TypeMappings) {
+	if t.pkgMappings == nil {
+		t.pkgMappings = make(map[string]TypeMappings)
+	}
+	t.pkgMappings[path] = m
+}
+
+// DisableStdlibMappings prevents the transformer from using the built-in
+// StdlibMappings.
+func (t *Transformer) DisableStdlibMappings() {
+	t.stdlib = false
+}
+
+// SetTimeFormat sets the default format of time.Time. It can be overridden
+// for a package with the `//proteus:time FORMAT` directive in the package
+// documentation and for a field with the `time=FORMAT` option of the proteus
+// struct tag. The time.Time fields with a format other than TimeWellKnown
+// are reported as errors by Err, as their type must be the stdtypes type of
+// the format instead.
+func (t *Transformer) SetTimeFormat(f TimeFormat) {
+	t.timeFormats.time = f
+}
+
+// SetDurationFormat sets the default format of time.Duration. It can be
+// overridden for a package with the `//proteus:duration FORMAT` directive in
+// the package documentation and for a field with the `duration=FORMAT` option
+// of the proteus struct tag. The time.Duration fields with a format other
+// than TimeWellKnown and TimeNanos are reported as errors by Err, as their
+// type must be the stdtypes type of the format instead.
+func (t *Transformer) SetDurationFormat(f TimeFormat) {
+	t.timeFormats.duration = f
+}
+
+// SetValidation sets the kind of field constraints to generate from the
+// validate struct tags. By default, no constraints are generated.
+func (t *Transformer) SetValidation(v Validation) {
+	t.validation = v
+}
+
+// AddHooks adds the given hooks to the ones run by the transformer for
+// every message, field, enum, enum value and RPC it transforms.
+func (t *Transformer) AddHooks(h Hooks) {
+	t.hooks.Add(h)
+}
+
 // SetStructSet sets the passed TypeSet as a known list of structs.
 func (t *Transformer) SetStructSet(ts TypeSet) {
 	t.structSet = ts
@@ -72,20 +157,38 @@ func (t *Transformer) Transform(p *scanner.Package) *Package {
 		Options: t.defaultOptionsForPackage(p),
 	}
 
+	pkg.Options = mergeOptions(pkg.Options, p.Directives[optionDirective], fmt.Sprintf("package %q", p.Path))
+	for _, i := range p.Directives[importDirective] {
+		pkg.Import(&ProtoType{Import: strings.Trim(i, `"`)})
+	}
+
+	defaultFormats := t.timeFormats
+	t.timeFormats = t.withTimeDirectives(defaultFormats, p.Directives.Get, fmt.Sprintf("package %q", p.Path))
+	defer func() { t.timeFormats = defaultFormats }()
+
+	t.currentMappings = t.pkgMappings[p.Path]
+	defer func() { t.currentMappings = nil }()
+
 	for _, s := range p.Structs {
 		msg := t.transformStruct(pkg, s)
-		pkg.Messages = append(pkg.Messages, msg)
+		if t.hooks.runMessage(pkg, msg, s) {
+			t.checkRename("message", s.Name, msg.Name)
+			pkg.Messages = append(pkg.Messages, msg)
+		}
 	}
 
 	for _, e := range p.Enums {
-		enum := t.transformEnum(e)
-		pkg.Enums = append(pkg.Enums, enum)
+		enum := t.transformEnum(pkg, e)
+		if t.hooks.runEnum(pkg, enum, e) {
+			t.checkRename("enum", e.Name, enum.Name)
+			pkg.Enums = append(pkg.Enums, enum)
+		}
 	}
 
 	names := buildNameSet(p)
 	for _, f := range p.Funcs {
 		rpc := t.transformFunc(pkg, f, names)
-		if rpc != nil {
+		if rpc != nil && t.hooks.runRPC(pkg, rpc, f) {
 			pkg.RPCs = append(pkg.RPCs, rpc)
 		}
 	}
@@ -93,6 +196,18 @@ func (t *Transformer) Transform(p *scanner.Package) *Package {
 	return pkg
 }
 
+// checkRename reports an error, which is returned by Err, if a hook renamed
+// the message or enum with the given name. They cannot be renamed, as the Go
+// code generated by protoc uses their Go types.
+func (t *Transformer) checkRename(kind, name, newName string) {
+	if name != newName {
+		t.addError(
+			"%s %q was renamed to %q by a hook, but messages and enums cannot be renamed, as the Go code generated by protoc uses their Go types",
+			kind, name, newName,
+		)
+	}
+}
+
 func (t *Transformer) transformFunc(pkg *Package, f *scanner.Func, names nameSet) *RPC {
 	var (
 		name         = f.Name
@@ -120,11 +235,14 @@ func (t *Transformer) transformFunc(pkg *Package, f *scanner.Func, names nameSet
 		IsVariadic: f.IsVariadic,
 		Input:      t.transformInputTypes(pkg, f.Input, names, name),
 		Output:     t.transformOutputTypes(pkg, output, names, name),
+		Src:        f,
 	}
 	if rpc.Input == nil || rpc.Output == nil {
 		return nil
 	}
 
+	t.httpOptions(pkg, rpc, f.Directives)
+	rpc.Options = mergeOptions(rpc.Options, f.Directives[optionDirective], fmt.Sprintf("func %q", f.Name))
 	return rpc
 }
 
@@ -174,22 +292,30 @@ func capitalize(s string) string {
 	return strings.ToUpper(s[0:1]) + s[1:len(s)]
 }
 
-func (t *Transformer) transformEnum(e *scanner.Enum) *Enum {
+func (t *Transformer) transformEnum(pkg *Package, e *scanner.Enum) *Enum {
 	enum := &Enum{
 		Docs:    e.Doc,
 		Name:    e.Name,
 		Options: t.defaultOptionsForScannedEnum(e),
+		Src:     e,
 	}
 
+	enum.Options = mergeOptions(enum.Options, e.Directives[optionDirective], fmt.Sprintf("enum %q", e.Name))
+
 	for i, v := range e.Values {
-		enum.Values = append(enum.Values, &EnumValue{
-			Docs:  v.Doc,
-			Name:  toUpperSnakeCase(v.Name),
-			Value: uint(i),
-			Options: Options{
-				"(gogoproto.enumvalue_customname)": NewStringValue(v.Name),
-			},
-		})
+		opts := Options{
+			"(gogoproto.enumvalue_customname)": NewStringValue(v.Name),
+		}
+
+		value := &EnumValue{
+			Docs:    v.Doc,
+			Name:    toUpperSnakeCase(v.Name),
+			Value:   uint(i),
+			Options: mergeOptions(opts, v.Directives[optionDirective], fmt.Sprintf("enum value %q", v.Name)),
+		}
+		if t.hooks.runEnumValue(pkg, enum, value, v) {
+			enum.Values = append(enum.Values, value)
+		}
 	}
 	return enum
 }
@@ -213,14 +339,20 @@ func (t *Transformer) transformStruct(pkg *Package, s *scanner.Struct) *Message
 		Name:    s.Name,
 		Options: t.defaultOptionsForScannedMessage(s),
 	}
+	msg.Options = mergeOptions(msg.Options, s.Directives[optionDirective], fmt.Sprintf("struct %q", s.Name))
 
 	for i, f := range s.Fields {
 		field := t.transformField(pkg, msg, f, i+1)
 		if field == nil {
 			msg.Reserve(uint(i) + 1)
 			report.Warn("field %q of struct %q has an invalid type, ignoring field but reserving its position", f.Name, s.Name)
-		} else {
+		} else if name := field.Name; t.hooks.runField(pkg, msg, field, f) {
+			if field.Name != name {
+				keepGoName(field, f.Name)
+			}
 			msg.Fields = append(msg.Fields, field)
+		} else {
+			msg.Reserve(uint(i) + 1)
 		}
 	}
 
@@ -254,12 +386,19 @@ func (t *Transformer) transformField(pkg *Package, msg *Message, field *scanner.
 		Repeated: repeated,
 	}
 
-	// []byte is the only repeated type that maps to
-	// a non-repeated type in protobuf, so we handle
-	// it a bit differently.
-	if isByteSlice(field.Type) {
-		typ = NewBasic("bytes")
-		f.Repeated = false
+	if !t.checkNullType(field) {
+		return nil
+	}
+
+	// []byte, [N]byte and type declarations of them are the only
+	// repeated types that map to a non-repeated type in protobuf,
+	// so we handle them a bit differently.
+	if bytes := t.bytesType(pkg, field, f); bytes != nil {
+		typ = bytes
+	} else if scalar := t.scalarOverride(pkg, field, f); scalar != nil {
+		typ = scalar
+	} else if protoType := t.timeMapping(field); protoType != nil {
+		typ = t.transformMappedType(pkg, protoType, field.Type, msg, f)
 	} else {
 		typ = t.transformType(pkg, field.Type, msg, f)
 		if typ == nil {
@@ -268,10 +407,26 @@ func (t *Transformer) transformField(pkg *Package, msg *Message, field *scanner.
 	}
 
 	f.Type = typ
+	t.validationOptions(pkg, field, f)
+	f.Options = mergeOptions(f.Options, field.TagValues(optionTag), fmt.Sprintf("field %q", field.Name))
 
 	return f
 }
 
+// keepGoName sets the gogoproto.customname option of a field renamed by a
+// hook to the name of its Go field, unless the hook set it, so the Go code
+// generated by protoc still uses the Go field.
+func keepGoName(f *Field, goName string) {
+	if _, ok := f.Options["(gogoproto.customname)"]; ok {
+		return
+	}
+
+	if f.Options == nil {
+		f.Options = make(Options)
+	}
+	f.Options["(gogoproto.customname)"] = NewStringValue(goName)
+}
+
 func (t *Transformer) defaultOptionsForStructField(field *scanner.Field) Options {
 	opts := make(Options)
 	if generator.CamelCase(toLowerSnakeCase(field.Name)) != field.Name {
@@ -308,13 +463,12 @@ func (t *Transformer) transformType(pkg *Package, typ scanner.Type, msg *Message
 
 	switch ty := typ.(type) {
 	case *scanner.Named:
-		protoType := t.findMapping(ty.String())
+		protoType := t.findMapping(ty.InstanceName())
+		if protoType == nil && len(ty.TypeArgs) > 0 {
+			protoType = t.findMapping(ty.String())
+		}
 		if protoType != nil {
-			pkg.Import(protoType)
-			protoType.Decorate(pkg, msg, field)
-			n := protoType.Type()
-			n.SetSource(ty)
-			return n
+			return t.transformMappedType(pkg, protoType, ty, msg, field)
 		}
 
 		pkg.ImportFromPath(ty.Path)
@@ -324,11 +478,7 @@ func (t *Transformer) transformType(pkg *Package, typ scanner.Type, msg *Message
 	case *scanner.Basic:
 		protoType := t.findMapping(ty.Name)
 		if protoType != nil {
-			pkg.Import(protoType)
-			protoType.Decorate(pkg, msg, field)
-			b := protoType.Type()
-			b.SetSource(ty)
-			return b
+			return t.transformMappedType(pkg, protoType, ty, msg, field)
 		}
 
 		report.Warn("basic type %q is not defined in the mappings, ignoring", ty.Name)
@@ -351,7 +501,7 @@ func (t *Transformer) transformType(pkg *Package, typ scanner.Type, msg *Message
 
 		// Repeated types cannot use casttype :(
 		if !ty.IsRepeated() {
-			field.Options["(gogoproto.casttype)"] = NewStringValue(castType(pkg, n.Type))
+			field.Options["(gogoproto.casttype)"] = NewStringValue(castType(pkg, ty.Type))
 		}
 		return n
 	}
@@ -359,23 +509,39 @@ func (t *Transformer) transformType(pkg *Package, typ scanner.Type, msg *Message
 	return nil
 }
 
-func castType(pkg *Package, typ Type) string {
-	switch t := typ.Source().(type) {
+func (t *Transformer) transformMappedType(pkg *Package, protoType *ProtoType, typ scanner.Type, msg *Message, field *Field) Type {
+	pkg.Import(protoType)
+	protoType.Decorate(pkg, msg, field)
+	n := protoType.Type()
+	n.SetSource(typ)
+	return n
+}
+
+func castType(pkg *Package, typ scanner.Type) string {
+	switch t := typ.(type) {
 	case *scanner.Named:
 		if pkg.Path == t.Path {
 			return t.Name
 		}
 		return t.TypeString()
 	}
-	return typ.Source().TypeString()
+	return typ.TypeString()
 }
 
 func (t *Transformer) findMapping(name string) *ProtoType {
-	typ := t.mappings[name]
+	typ := t.currentMappings.Find(name)
+	if typ == nil {
+		typ = t.mappings.Find(name)
+	}
+
 	if typ == nil {
 		typ = DefaultMappings[name]
 	}
 
+	if typ == nil && t.stdlib {
+		typ = StdlibMappings[name]
+	}
+
 	if typ != nil && typ.Warn != "" {
 		report.Warn(typ.Warn, name)
 	}
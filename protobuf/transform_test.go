@@ -144,6 +144,43 @@ func (s *TransformerSuite) TestFindMapping() {
 	}
 }
 
+func (s *TransformerSuite) TestPackageMappings() {
+	s.t.SetMappings(TypeMappings{
+		"*.ID": &ProtoType{Name: "int64", Basic: true},
+	})
+	s.t.SetPackageMappings("gopkg.in/src-d/proteus.v1/fixtures", TypeMappings{
+		"*.ID": &ProtoType{Name: "string", Basic: true},
+	})
+
+	s.Equal("int64", s.t.findMapping("github.com/foo/bar.ID").Name)
+
+	pkg := s.t.Transform(&scanner.Package{
+		Path: "gopkg.in/src-d/proteus.v1/fixtures",
+		Structs: []*scanner.Struct{
+			{
+				Name: "Foo",
+				Fields: []*scanner.Field{
+					{Name: "ID", Type: scanner.NewNamed("github.com/foo/bar", "ID")},
+				},
+			},
+		},
+	})
+	s.Equal("string", pkg.Messages[0].Fields[0].Type.(*Basic).Name)
+
+	s.Equal("int64", s.t.findMapping("github.com/foo/bar.ID").Name, "package mappings are only used in their package")
+}
+
+func (s *TransformerSuite) TestFindMappingStdlib() {
+	s.Nil(s.t.findMapping("net/url.URL"), "only the wrapper of url.URL is mapped")
+	t := s.t.findMapping("gopkg.in/src-d/proteus.v1/stdtypes.URL")
+	s.NotNil(t)
+	s.Equal("bytes", t.Name)
+
+	s.t.DisableStdlibMappings()
+	s.Nil(s.t.findMapping("gopkg.in/src-d/proteus.v1/stdtypes.URL"))
+	s.NotNil(s.t.findMapping("time.Time"))
+}
+
 func (s *TransformerSuite) TestFindMappingWithWarn() {
 	s.t.SetMappings(TypeMappings{
 		"url.URL": &ProtoType{Name: "string", Basic: true},
@@ -478,6 +515,7 @@ func (s *TransformerSuite) TestTransformFuncMultiple() {
 
 	s.NotNil(rpc)
 	s.Equal(fn.Name, rpc.Name)
+	s.Equal(fn, rpc.Src)
 	s.assertType(NewGeneratedNamed("baz", "DoFooRequest"), rpc.Input, "rpc input")
 	s.assertType(NewGeneratedNamed("baz", "DoFooResponse"), rpc.Output, "rpc output")
 
@@ -669,7 +707,7 @@ func (s *TransformerSuite) TestTransformFuncRepeatedSingle() {
 }
 
 func (s *TransformerSuite) TestTransformEnum() {
-	enum := s.t.transformEnum(&scanner.Enum{
+	src := &scanner.Enum{
 		Docs: mkDocs("foo bar baz"),
 		Name: "Foo",
 		Values: []*scanner.EnumValue{
@@ -677,9 +715,11 @@ func (s *TransformerSuite) TestTransformEnum() {
 			mkEnumVal("baaar bar", "Bar"),
 			mkEnumVal("barbaz bar", "BarBaz"),
 		},
-	})
+	}
+	enum := s.t.transformEnum(&Package{}, src)
 
 	s.Equal("Foo", enum.Name)
+	s.Equal(src, enum.Src)
 	s.Equal("foo bar baz", strings.Join(enum.Docs, "\n"))
 	s.Equal(3, len(enum.Values), "should have same number of values")
 	s.assertEnumVal(enum.Values[0], "FOO", 0, "fooo bar")
@@ -690,7 +730,7 @@ func (s *TransformerSuite) TestTransformEnum() {
 }
 
 func (s *TransformerSuite) TestTransformEnumIsStringer() {
-	enum := s.t.transformEnum(&scanner.Enum{
+	enum := s.t.transformEnum(&Package{}, &scanner.Enum{
 		Name: "Foo",
 		Values: []*scanner.EnumValue{
 			mkEnumVal("fooo bar", "Foo"),
@@ -0,0 +1,284 @@
+package protobuf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+// Validation is the kind of field constraints generated from the rules in
+// the `validate` struct tags used by go-playground/validator.
+type Validation string
+
+const (
+	// NoValidation does not generate any field constraint. This is the
+	// default.
+	NoValidation Validation = ""
+	// ValidationPGV generates protoc-gen-validate constraints, that is,
+	// `(validate.rules)` options.
+	ValidationPGV Validation = "pgv"
+	// ValidationProtovalidate generates protovalidate constraints, that is,
+	// `(buf.validate.field)` options.
+	ValidationProtovalidate Validation = "protovalidate"
+)
+
+// validateTag is the struct tag with the validation rules.
+const validateTag = "validate"
+
+// ParseValidation returns the Validation with the given name or an error if
+// there is no such kind of validation.
+func ParseValidation(name string) (Validation, error) {
+	switch v := Validation(name); v {
+	case NoValidation, ValidationPGV, ValidationProtovalidate:
+		return v, nil
+	}
+	return "", fmt.Errorf("invalid validation %q, expecting one of: pgv, protovalidate", name)
+}
+
+func (v Validation) option() string {
+	if v == ValidationProtovalidate {
+		return "(buf.validate.field)"
+	}
+	return "(validate.rules)"
+}
+
+func (v Validation) importFile() string {
+	if v == ValidationProtovalidate {
+		return "buf/validate/validate.proto"
+	}
+	return "validate/validate.proto"
+}
+
+// validationOptions sets the constraints of the field translated from the
+// rules of its validate struct tag. Rules that cannot be translated are
+// reported and ignored. Rules after `dive` are applied to the elements of
+// repeated fields and to the values of maps.
+func (t *Transformer) validationOptions(pkg *Package, field *scanner.Field, f *Field) {
+	tag, ok := field.StructTag.Lookup(validateTag)
+	if !ok || tag == "" || tag == "-" || t.validation == NoValidation {
+		return
+	}
+
+	prefix, group := t.validation.option(), validationGroup(t, f.Type, f.Repeated)
+	for _, rule := range strings.Split(tag, ",") {
+		name, param := rule, ""
+		if idx := strings.Index(rule, "="); idx >= 0 {
+			name, param = rule[:idx], rule[idx+1:]
+		}
+
+		if name == "dive" {
+			prefix, group = diveInto(t, prefix, group, f.Type)
+			if group == "" {
+				report.Warn("validation rule %q of field %q can only be used with repeated fields and maps, ignoring the rest of rules", name, field.Name)
+				return
+			}
+			continue
+		}
+
+		rules, err := translateRule(t.validation, group, name, param)
+		if err != nil {
+			report.Warn("validation rule %q of field %q cannot be translated, ignoring it: %s", rule, field.Name, err)
+			continue
+		}
+
+		if f.Options == nil {
+			f.Options = make(Options)
+		}
+
+		for path, value := range rules {
+			f.Options[fmt.Sprintf("%s.%s", prefix, path)] = value
+		}
+
+		if len(rules) > 0 {
+			pkg.Import(&ProtoType{Import: t.validation.importFile()})
+		}
+	}
+}
+
+// validationGroup returns the group of constraints of a field with the
+// given type, which is the name of the scalar type for scalars.
+func validationGroup(t *Transformer, typ Type, repeated bool) string {
+	if repeated {
+		return "repeated"
+	}
+
+	switch ty := typ.(type) {
+	case *Basic:
+		return ty.Name
+	case *Alias:
+		return validationGroup(t, ty.Underlying, false)
+	case *Map:
+		return "map"
+	case *Named:
+		if ty.Package == "google.protobuf" {
+			switch ty.Name {
+			case "Timestamp":
+				return "timestamp"
+			case "Duration":
+				return "duration"
+			}
+		}
+
+		if n, ok := ty.Source().(*scanner.Named); ok && t.IsEnum(n.Path, n.Name) {
+			return "enum"
+		}
+		return "message"
+	}
+	return ""
+}
+
+// diveInto returns the option prefix and the group of the elements of a
+// repeated field or the values of a map.
+func diveInto(t *Transformer, prefix, group string, typ Type) (string, string) {
+	switch group {
+	case "repeated":
+		return prefix + ".repeated.items", validationGroup(t, typ, false)
+	case "map":
+		return prefix + ".map.values", validationGroup(t, typ.(*Map).Value, false)
+	}
+	return "", ""
+}
+
+var numericGroups = map[string]bool{
+	"int32": true, "int64": true, "uint32": true, "uint64": true,
+	"sint32": true, "sint64": true, "fixed32": true, "fixed64": true,
+	"sfixed32": true, "sfixed64": true, "float": true, "double": true,
+}
+
+var stringFormats = map[string]string{
+	"email":    "email",
+	"url":      "uri",
+	"uri":      "uri",
+	"uuid":     "uuid",
+	"hostname": "hostname",
+	"ip":       "ip",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+}
+
+// translateRule translates a go-playground/validator rule with the given
+// parameter for a field of the given group of constraints. It returns the
+// constraints indexed by their path from the constraints option of the field.
+func translateRule(v Validation, group, rule, param string) (map[string]OptionValue, error) {
+	var (
+		isNumeric = numericGroups[group]
+		isLength  = group == "string" || group == "bytes"
+		sizeRule  = map[string]string{"repeated": "items", "map": "pairs"}[group]
+	)
+
+	switch rule {
+	case "required":
+		if v == ValidationProtovalidate {
+			return rules("required", NewLiteralValue("true")), nil
+		}
+
+		switch {
+		case isLength:
+			return rules(group+".min_len", NewLiteralValue("1")), nil
+		case sizeRule != "":
+			return rules(fmt.Sprintf("%s.min_%s", group, sizeRule), NewLiteralValue("1")), nil
+		case group == "message" || group == "timestamp" || group == "duration":
+			return rules("message.required", NewLiteralValue("true")), nil
+		}
+	case "omitempty":
+		if v == ValidationProtovalidate {
+			return rules("ignore", NewLiteralValue("IGNORE_IF_ZERO_VALUE")), nil
+		}
+
+		switch {
+		case isLength || isNumeric || sizeRule != "":
+			return rules(group+".ignore_empty", NewLiteralValue("true")), nil
+		case group == "message":
+			// nil messages are never validated by PGV
+			return nil, nil
+		}
+	case "min", "max", "len", "eq", "gt", "gte", "lt", "lte":
+		return translateBound(group, rule, param, isNumeric, isLength, sizeRule)
+	default:
+		format, ok := stringFormats[rule]
+		if !ok {
+			return nil, fmt.Errorf("unsupported rule")
+		}
+
+		if group == "string" {
+			return rules("string."+format, NewLiteralValue("true")), nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported rule for %s fields", group)
+}
+
+func translateBound(group, rule, param string, isNumeric, isLength bool, sizeRule string) (map[string]OptionValue, error) {
+	if isNumeric {
+		if _, err := strconv.ParseFloat(param, 64); err != nil {
+			return nil, fmt.Errorf("invalid number %q", param)
+		}
+
+		name := map[string]string{"min": "gte", "max": "lte", "len": "const", "eq": "const"}[rule]
+		if name == "" {
+			name = rule
+		}
+		return rules(group+"."+name, NewLiteralValue(param)), nil
+	}
+
+	if group == "string" && rule == "eq" {
+		return rules("string.const", NewStringValue(param)), nil
+	}
+
+	if group == "bool" && rule == "eq" {
+		if _, err := strconv.ParseBool(param); err != nil {
+			return nil, fmt.Errorf("invalid bool %q", param)
+		}
+		return rules("bool.const", NewLiteralValue(param)), nil
+	}
+
+	if !isLength && sizeRule == "" {
+		return nil, fmt.Errorf("unsupported rule for %s fields", group)
+	}
+
+	n, err := strconv.ParseUint(param, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid length %q", param)
+	}
+
+	min, max := group+".min_len", group+".max_len"
+	if sizeRule != "" {
+		min, max = fmt.Sprintf("%s.min_%s", group, sizeRule), fmt.Sprintf("%s.max_%s", group, sizeRule)
+	}
+
+	switch rule {
+	case "min", "gte":
+		return rules(min, uintValue(n)), nil
+	case "max", "lte":
+		return rules(max, uintValue(n)), nil
+	case "gt":
+		return rules(min, uintValue(n+1)), nil
+	case "lt":
+		if n == 0 {
+			return nil, fmt.Errorf("length cannot be lower than 0")
+		}
+		return rules(max, uintValue(n-1)), nil
+	case "len":
+		if isLength {
+			return rules(group+".len", uintValue(n)), nil
+		}
+		r := rules(min, uintValue(n))
+		r[max] = uintValue(n)
+		return r, nil
+	}
+
+	return nil, fmt.Errorf("unsupported rule for %s fields", group)
+}
+
+// rules returns the constraints with a single constraint with the given path
+// and value.
+func rules(path string, value OptionValue) map[string]OptionValue {
+	return map[string]OptionValue{path: value}
+}
+
+func uintValue(n uint64) OptionValue {
+	return NewLiteralValue(strconv.FormatUint(n, 10))
+}
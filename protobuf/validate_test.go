@@ -0,0 +1,149 @@
+package protobuf
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+func TestParseValidation(t *testing.T) {
+	for _, name := range []string{"", "pgv", "protovalidate"} {
+		v, err := ParseValidation(name)
+		require.Nil(t, err, name)
+		require.Equal(t, Validation(name), v)
+	}
+
+	_, err := ParseValidation("jsonschema")
+	require.NotNil(t, err)
+}
+
+func TestValidationOptions(t *testing.T) {
+	cases := []struct {
+		name       string
+		validation Validation
+		typ        scanner.Type
+		tag        reflect.StructTag
+		expected   Options
+		warnings   int
+	}{
+		{
+			"String", ValidationPGV, scanner.NewBasic("string"),
+			`validate:"required,min=1,max=64,email"`,
+			Options{
+				"(validate.rules).string.min_len": NewLiteralValue("1"),
+				"(validate.rules).string.max_len": NewLiteralValue("64"),
+				"(validate.rules).string.email":   NewLiteralValue("true"),
+			}, 0,
+		},
+		{
+			"StringProtovalidate", ValidationProtovalidate, scanner.NewBasic("string"),
+			`validate:"required,max=64,url"`,
+			Options{
+				"(buf.validate.field).required":       NewLiteralValue("true"),
+				"(buf.validate.field).string.max_len": NewLiteralValue("64"),
+				"(buf.validate.field).string.uri":     NewLiteralValue("true"),
+			}, 0,
+		},
+		{
+			"StringBounds", ValidationPGV, scanner.NewBasic("string"),
+			`validate:"gt=2,lt=10,eq=foo"`,
+			Options{
+				"(validate.rules).string.min_len": NewLiteralValue("3"),
+				"(validate.rules).string.max_len": NewLiteralValue("9"),
+				"(validate.rules).string.const":   NewStringValue("foo"),
+			}, 0,
+		},
+		{
+			"Number", ValidationPGV, scanner.NewBasic("int64"),
+			`validate:"omitempty,min=1,lt=100"`,
+			Options{
+				"(validate.rules).int64.ignore_empty": NewLiteralValue("true"),
+				"(validate.rules).int64.gte":          NewLiteralValue("1"),
+				"(validate.rules).int64.lt":           NewLiteralValue("100"),
+			}, 0,
+		},
+		{
+			"NumberProtovalidate", ValidationProtovalidate, scanner.NewBasic("float64"),
+			`validate:"omitempty,gte=0.5,len=2"`,
+			Options{
+				"(buf.validate.field).ignore":       NewLiteralValue("IGNORE_IF_ZERO_VALUE"),
+				"(buf.validate.field).double.gte":   NewLiteralValue("0.5"),
+				"(buf.validate.field).double.const": NewLiteralValue("2"),
+			}, 0,
+		},
+		{
+			"Repeated", ValidationPGV, repeated(scanner.NewBasic("string")),
+			`validate:"required,len=3,dive,uuid"`,
+			Options{
+				"(validate.rules).repeated.min_items":         NewLiteralValue("3"),
+				"(validate.rules).repeated.max_items":         NewLiteralValue("3"),
+				"(validate.rules).repeated.items.string.uuid": NewLiteralValue("true"),
+			}, 0,
+		},
+		{
+			"Map", ValidationPGV, scanner.NewMap(scanner.NewBasic("string"), scanner.NewBasic("int32")),
+			`validate:"max=5,dive,gt=0"`,
+			Options{
+				"(validate.rules).map.max_pairs":       NewLiteralValue("5"),
+				"(validate.rules).map.values.int32.gt": NewLiteralValue("0"),
+			}, 0,
+		},
+		{
+			"Message", ValidationPGV, nullable(scanner.NewNamed("foo", "Bar")),
+			`validate:"required"`,
+			Options{"(validate.rules).message.required": NewLiteralValue("true")}, 0,
+		},
+		{
+			"Timestamp", ValidationPGV, scanner.NewNamed("time", "Time"),
+			`validate:"required"`,
+			Options{
+				"(validate.rules).message.required": NewLiteralValue("true"),
+				"(gogoproto.stdtime)":               NewLiteralValue("true"),
+				"(gogoproto.nullable)":              NewLiteralValue("false"),
+			}, 0,
+		},
+		{
+			"Unsupported", ValidationPGV, scanner.NewBasic("int64"),
+			`validate:"required,email,oneof=1 2,max=a,min=2"`,
+			Options{"(validate.rules).int64.gte": NewLiteralValue("2")}, 4,
+		},
+		{
+			"DiveNotRepeated", ValidationPGV, scanner.NewBasic("string"),
+			`validate:"min=1,dive,max=2"`,
+			Options{"(validate.rules).string.min_len": NewLiteralValue("1")}, 1,
+		},
+		{
+			"Disabled", NoValidation, scanner.NewBasic("string"),
+			`validate:"required"`, Options{}, 0,
+		},
+		{
+			"Ignored", ValidationPGV, scanner.NewBasic("string"),
+			`validate:"-"`, Options{}, 0,
+		},
+	}
+
+	for _, c := range cases {
+		report.TestMode()
+
+		tr := NewTransformer()
+		tr.SetValidation(c.validation)
+		pkg := &Package{Path: "foo"}
+		f := tr.transformField(pkg, &Message{}, &scanner.Field{
+			Name:      "Field",
+			Type:      c.typ,
+			StructTag: c.tag,
+		}, 1)
+
+		require.Equal(t, c.expected, f.Options, c.name)
+		require.Len(t, report.MessageStack(), c.warnings, c.name)
+		if len(c.expected) > 0 {
+			require.Contains(t, pkg.Imports, c.validation.importFile(), c.name)
+		}
+
+		report.EndTestMode()
+	}
+}
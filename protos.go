@@ -0,0 +1,124 @@
+package proteus
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/resolver"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+// protoMappings returns the mappings of the types of the given packages that
+// are existing protobuf messages, declared with the `//proteus:proto`
+// directive, by full name.
+func protoMappings(pkgs []*scanner.Package) (protobuf.TypeMappings, error) {
+	mappings := make(protobuf.TypeMappings)
+	for _, p := range pkgs {
+		for name, args := range p.Protos {
+			typ, err := protobuf.ParseProtoDirective(args)
+			if err != nil {
+				return nil, fmt.Errorf("type %s.%s: %s", p.Path, name, err)
+			}
+			mappings[fmt.Sprintf("%s.%s", p.Path, name)] = typ
+		}
+	}
+	return mappings, nil
+}
+
+// referenceProtos removes the types that are existing protobuf messages from
+// the given packages, so they are not generated, and adds them to the custom
+// types of the resolver, so the fields using them are kept. It fails if the
+// messages of these types, or of the custom mappings, are not defined in the
+// proto files they import.
+func referenceProtos(options Options, r *resolver.Resolver, pkgs []*scanner.Package) error {
+	mappings, err := protoMappings(pkgs)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range pkgs {
+		removeTypes(p, p.Protos)
+	}
+
+	for name := range mappings {
+		r.AddCustomType(name)
+	}
+
+	all := []protobuf.TypeMappings{mappings, options.Mappings}
+	for _, m := range options.PackageMappings {
+		all = append(all, m)
+	}
+
+	var problems []string
+	files := protobuf.NewProtoFiles(protoPaths(options)...)
+	for _, m := range all {
+		for _, name := range sortedNames(m) {
+			if err := files.Check(m[name]); err != nil {
+				problems = append(problems, fmt.Sprintf("type %s references a missing protobuf message: %s", name, err))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "\n"))
+	}
+
+	return nil
+}
+
+// removeTypes removes the structs, enums and type declarations with the given
+// names from the package.
+func removeTypes(p *scanner.Package, names map[string]string) {
+	if len(names) == 0 {
+		return
+	}
+
+	var structs []*scanner.Struct
+	for _, s := range p.Structs {
+		if _, ok := names[s.Name]; !ok {
+			structs = append(structs, s)
+		}
+	}
+
+	var enums []*scanner.Enum
+	for _, e := range p.Enums {
+		if _, ok := names[e.Name]; !ok {
+			enums = append(enums, e)
+		}
+	}
+
+	for name := range names {
+		delete(p.Aliases, fmt.Sprintf("%s.%s", p.Path, name))
+	}
+
+	p.Structs, p.Enums = structs, enums
+}
+
+// protoPaths returns the folders where the imported proto files are found,
+// which are the proto paths of the options, the base path, the src folders
+// of the GOPATH and the current folder.
+func protoPaths(options Options) []string {
+	paths := append([]string(nil), options.ProtoPaths...)
+	if options.BasePath != "" {
+		paths = append(paths, options.BasePath)
+	}
+
+	for _, path := range filepath.SplitList(os.Getenv("GOPATH")) {
+		paths = append(paths, filepath.Join(path, "src"))
+	}
+
+	return append(paths, ".")
+}
+
+func sortedNames(m protobuf.TypeMappings) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
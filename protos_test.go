@@ -0,0 +1,123 @@
+package proteus
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/report"
+)
+
+const protosPkg = fixturesPkg + "/protos"
+
+const protosFixture = `package protos
+
+// Money is an amount of money.
+//proteus:proto myorg.common.Money import="common/money.proto"
+type Money struct {
+	Currency string
+	Units    int64
+}
+
+//proteus:generate
+type Order struct {
+	Price  Money
+	Prices []*Money
+}
+`
+
+const moneyProto = `syntax = "proto3";
+package myorg.common;
+
+message Money {
+	string currency = 1;
+	int64 units = 2;
+}
+`
+
+func writeProtosFixture(t *testing.T, directive string) (string, func()) {
+	pkg := filepath.Join(os.Getenv("GOPATH"), "src", protosPkg)
+	require.NoError(t, os.MkdirAll(pkg, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pkg, "protos.go"), []byte(directive), 0644))
+
+	protos, err := ioutil.TempDir("", "proteus")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(protos, "common"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(protos, "common", "money.proto"), []byte(moneyProto), 0644))
+
+	return protos, func() {
+		require.NoError(t, os.RemoveAll(pkg))
+		require.NoError(t, os.RemoveAll(protos))
+	}
+}
+
+func TestReferenceProtos(t *testing.T) {
+	require := require.New(t)
+	report.TestMode()
+	defer report.EndTestMode()
+
+	protos, cleanup := writeProtosFixture(t, protosFixture)
+	defer cleanup()
+
+	options := Options{Packages: []string{protosPkg}, ProtoPaths: []string{protos}}
+	pkgs, err := scanPackages(options)
+	require.NoError(err)
+	require.Len(pkgs[0].Structs, 1, "types referencing messages are not generated")
+	require.NotNil(findField(pkgs[0], "Order", "Price"))
+	require.NotNil(findField(pkgs[0], "Order", "Prices"))
+
+	result, err := transformPackages(options, pkgs)
+	require.NoError(err)
+	pkg := result[0]
+	require.Contains(pkg.Imports, "common/money.proto")
+	require.Len(pkg.Messages, 1)
+	for _, f := range pkg.Messages[0].Fields {
+		require.Equal(protobuf.NewNamed("myorg.common", "Money").String(), f.Type.String())
+	}
+
+	options.ProtoPaths = nil
+	_, err = scanPackages(options)
+	require.Error(err)
+	require.Contains(err.Error(), "type "+protosPkg+".Money references a missing protobuf message: proto file common/money.proto is not found")
+}
+
+func TestReferenceProtosMissingMessage(t *testing.T) {
+	require := require.New(t)
+	report.TestMode()
+	defer report.EndTestMode()
+
+	protos, cleanup := writeProtosFixture(t, protosFixture)
+	defer cleanup()
+
+	options := Options{
+		Packages:   []string{protosPkg},
+		ProtoPaths: []string{protos},
+		Mappings: protobuf.TypeMappings{
+			"github.com/myorg/money.Price": &protobuf.ProtoType{Package: "myorg.common", Name: "Price", Import: "common/money.proto"},
+		},
+	}
+
+	_, err := scanPackages(options)
+	require.Error(err)
+	require.Equal("type github.com/myorg/money.Price references a missing protobuf message: message myorg.common.Price is not defined in common/money.proto", err.Error())
+}
+
+func TestReferenceProtosInvalidDirective(t *testing.T) {
+	report.TestMode()
+	defer report.EndTestMode()
+
+	protos, cleanup := writeProtosFixture(t, `package protos
+
+//proteus:proto myorg.common.Money
+type Money struct{}
+`)
+	defer cleanup()
+
+	_, err := scanPackages(Options{Packages: []string{protosPkg}, ProtoPaths: []string{protos}})
+	require.Error(t, err)
+	require.Equal(t, "type "+protosPkg+".Money: proto directive of message myorg.common.Money has no import", err.Error())
+}
@@ -49,6 +49,12 @@ func Info(format string, args ...interface{}) {
 	report(color.GreenString, "INFO", format, args...)
 }
 
+// Notice prints a formatted message to stdout, even if the output is
+// silent, as it is information the user asked for.
+func Notice(format string, args ...interface{}) {
+	report(color.CyanString, "NOTICE", format, args...)
+}
+
 func report(color colorFunc, lvl string, format string, args ...interface{}) {
 	fmt.Sprintf("%s: %s", color(lvl), fmt.Sprintf(format, args...))
 
@@ -56,7 +62,7 @@ func report(color colorFunc, lvl string, format string, args ...interface{}) {
 		msgStack = append(msgStack, fmt.Sprintf("%s: %s", lvl, fmt.Sprintf(format, args...)))
 	}
 
-	if !silent || lvl == "ERROR" {
+	if !silent || lvl == "ERROR" || lvl == "NOTICE" {
 		fmt.Println(fmt.Sprintf("%s: %s", color(lvl), fmt.Sprintf(format, args...)))
 	}
 }
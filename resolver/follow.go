@@ -0,0 +1,323 @@
+package resolver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+// DefaultFollowDepth is the maximum depth of the packages loaded by Follow
+// if no depth is given.
+const DefaultFollowDepth = 3
+
+// Followed is a package loaded by Follow because it has types needed by the
+// generated types.
+type Followed struct {
+	// Path is the path of the package.
+	Path string
+	// Depth is the number of packages followed to reach this one, which is 1
+	// for the packages referenced by the given packages.
+	Depth int
+	// Types are the full names of the structs and enums of the package that
+	// are needed, which are the only ones kept in the package.
+	Types []string
+	// By are the full names of the structs and funcs referencing the types
+	// of the package.
+	By []string
+}
+
+// follower keeps track of the packages loaded by Follow and the types
+// needed in them.
+type follower struct {
+	r        *Resolver
+	prefixes []string
+	depth    int
+
+	pkgs     map[string]*scanner.Package
+	levels   map[string]int
+	followed map[string]*Followed
+	// needed are the types already visited, by full name.
+	needed map[string]bool
+	// pending are the types of the packages to load.
+	pending map[string][]pendingType
+	// failed are the packages that could not be loaded.
+	failed map[string]bool
+}
+
+type pendingType struct {
+	typ  scanner.Type
+	from string
+}
+
+// Follow loads the packages that are not in the given packages but contain
+// types used by their generated structs and funcs, and the packages used by
+// those types in turn, as long as their paths start with one of the given
+// prefixes and they are at most depth packages away from the given ones. If
+// depth is 0, DefaultFollowDepth is used.
+//
+// Only the structs and enums of the loaded packages reachable from the
+// generated types are kept, and they are marked for generation, while their
+// funcs are removed. The loaded packages, which must be resolved along with
+// the given ones, are returned after them, along with the report of what was
+// loaded and why.
+//
+// Follow uses the custom types of the resolver, so the custom types must be
+// added before calling it.
+func (r *Resolver) Follow(pkgs []*scanner.Package, prefixes []string, depth int) ([]*scanner.Package, []*Followed) {
+	if depth <= 0 {
+		depth = DefaultFollowDepth
+	}
+
+	f := &follower{
+		r:        r,
+		prefixes: prefixes,
+		depth:    depth,
+		pkgs:     make(map[string]*scanner.Package),
+		levels:   make(map[string]int),
+		followed: make(map[string]*Followed),
+		needed:   make(map[string]bool),
+		pending:  make(map[string][]pendingType),
+		failed:   make(map[string]bool),
+	}
+
+	for _, p := range pkgs {
+		f.pkgs[p.Path] = p
+	}
+
+	for _, p := range pkgs {
+		for _, s := range p.Structs {
+			if s.Generate {
+				f.visitStruct(p, s)
+			}
+		}
+
+		for _, fn := range p.Funcs {
+			f.visitFunc(p, fn)
+		}
+	}
+
+	var loaded []*scanner.Package
+	for len(f.pending) > 0 {
+		pending := f.pending
+		f.pending = make(map[string][]pendingType)
+
+		for _, path := range sortedKeys(pending) {
+			p := f.load(path, pending[path])
+			if p != nil {
+				loaded = append(loaded, p)
+			}
+
+			for _, t := range pending[path] {
+				f.visit(t.typ, t.from)
+			}
+		}
+	}
+
+	return append(pkgs, f.prune(loaded)...), f.report()
+}
+
+func (f *follower) visitStruct(p *scanner.Package, s *scanner.Struct) {
+	from := fmt.Sprintf("%s.%s", p.Path, s.Name)
+	f.needed[from] = true
+	for _, field := range s.Fields {
+		f.visit(field.Type, from)
+	}
+}
+
+func (f *follower) visitFunc(p *scanner.Package, fn *scanner.Func) {
+	from := fmt.Sprintf("%s.%s", p.Path, fn.Name)
+	for _, t := range append(fn.Input, fn.Output...) {
+		f.visit(t, from)
+	}
+}
+
+// visit visits a type used by the struct or func with the given name,
+// visiting the types it uses in turn or adding its package to the pending
+// ones if it is not loaded.
+func (f *follower) visit(typ scanner.Type, from string) {
+	switch t := typ.(type) {
+	case *scanner.Map:
+		f.visit(t.Key, from)
+		f.visit(t.Value, from)
+	case *scanner.Alias:
+		f.visit(t.Type, from)
+		f.visit(t.Underlying, from)
+	case *scanner.Named:
+		f.visitNamed(t, from)
+	}
+}
+
+func (f *follower) visitNamed(t *scanner.Named, from string) {
+	if t.Path == "" || f.r.isCustomType(t) {
+		return
+	}
+
+	p, ok := f.pkgs[t.Path]
+	if !ok {
+		f.addPending(t, from)
+		return
+	}
+
+	name := t.String()
+	if followed, ok := f.followed[t.Path]; ok && fromPath(from) != t.Path {
+		followed.By = appendUnique(followed.By, from)
+	}
+
+	if f.needed[name] {
+		return
+	}
+	f.needed[name] = true
+
+	for _, e := range p.Enums {
+		if e.Name == t.Name {
+			return
+		}
+	}
+
+	if alias, ok := p.Aliases[name]; ok {
+		f.visit(alias, from)
+		return
+	}
+
+	for _, s := range p.Structs {
+		if s.Name == t.Name {
+			f.visitStruct(p, s)
+			return
+		}
+	}
+}
+
+// addPending adds the package of the type to the packages to load, if it
+// can be followed.
+func (f *follower) addPending(t *scanner.Named, from string) {
+	if f.failed[t.Path] || !f.hasPrefix(t.Path) {
+		return
+	}
+
+	level := f.levels[fromPath(from)] + 1
+	if level > f.depth {
+		report.Warn("type %s used by %s is not followed, it is more than %d packages away", t, from, f.depth)
+		return
+	}
+
+	if _, ok := f.pending[t.Path]; !ok {
+		f.levels[t.Path] = level
+	}
+	f.pending[t.Path] = append(f.pending[t.Path], pendingType{t, from})
+}
+
+func (f *follower) hasPrefix(path string) bool {
+	for _, prefix := range f.prefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// load scans the package with the given path.
+func (f *follower) load(path string, types []pendingType) *scanner.Package {
+	pkgs, err := scanPackages(path)
+	if err != nil {
+		report.Warn("package %s used by %s cannot be followed: %s", path, types[0].from, err)
+		f.failed[path] = true
+		return nil
+	}
+
+	p := pkgs[0]
+	f.pkgs[path] = p
+	f.followed[path] = &Followed{Path: path, Depth: f.levels[path]}
+	return p
+}
+
+func scanPackages(paths ...string) ([]*scanner.Package, error) {
+	s, err := scanner.New(paths...)
+	if err != nil {
+		return nil, err
+	}
+	return s.Scan()
+}
+
+// prune removes the types not needed and the funcs from the loaded packages
+// and marks their structs for generation.
+func (f *follower) prune(pkgs []*scanner.Package) []*scanner.Package {
+	for _, p := range pkgs {
+		var structs []*scanner.Struct
+		for _, s := range p.Structs {
+			if f.needed[fmt.Sprintf("%s.%s", p.Path, s.Name)] {
+				s.Generate = true
+				structs = append(structs, s)
+			}
+		}
+
+		var enums []*scanner.Enum
+		for _, e := range p.Enums {
+			if f.needed[fmt.Sprintf("%s.%s", p.Path, e.Name)] {
+				enums = append(enums, e)
+			}
+		}
+
+		p.Structs, p.Enums, p.Funcs = structs, enums, nil
+
+		followed := f.followed[p.Path]
+		for _, s := range structs {
+			followed.Types = append(followed.Types, fmt.Sprintf("%s.%s", p.Path, s.Name))
+		}
+		for _, e := range enums {
+			followed.Types = append(followed.Types, fmt.Sprintf("%s.%s", p.Path, e.Name))
+		}
+		sort.Strings(followed.Types)
+	}
+
+	return pkgs
+}
+
+func (f *follower) report() []*Followed {
+	var result []*Followed
+	for _, followed := range f.followed {
+		sort.Strings(followed.By)
+		result = append(result, followed)
+	}
+
+	sort.Sort(byDepth(result))
+	return result
+}
+
+// byDepth sorts the followed packages by depth and path.
+type byDepth []*Followed
+
+func (s byDepth) Len() int      { return len(s) }
+func (s byDepth) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byDepth) Less(i, j int) bool {
+	if s[i].Depth != s[j].Depth {
+		return s[i].Depth < s[j].Depth
+	}
+	return s[i].Path < s[j].Path
+}
+
+// fromPath returns the package path of the full name of a struct or func.
+func fromPath(name string) string {
+	return name[:strings.LastIndex(name, ".")]
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, item := range list {
+		if item == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+func sortedKeys(m map[string][]pendingType) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,148 @@
+package resolver
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+var followFixtures = map[string]string{
+	"a/a.go": `package a
+
+import "gopkg.in/src-d/proteus.v1/fixtures/follow/b"
+
+//proteus:generate
+type A struct {
+	B  b.B
+	ID b.ID
+}
+`,
+	"b/b.go": `package b
+
+import "gopkg.in/src-d/proteus.v1/fixtures/follow/c"
+
+type ID string
+
+type B struct {
+	Kind Kind
+	C    *c.C
+}
+
+type Unused struct {
+	C c.Other
+}
+
+//proteus:generate
+type Kind int
+
+const (
+	KindA Kind = iota
+	KindB
+)
+
+//proteus:generate
+type Unneeded int
+
+const (
+	UnneededA Unneeded = iota
+)
+
+//proteus:generate
+func NotGenerated(b B) B {
+	return b
+}
+`,
+	"c/c.go": `package c
+
+type C struct {
+	Name string
+}
+
+type Other struct {
+	Name string
+}
+`,
+}
+
+func writeFollowFixtures(t *testing.T) func() {
+	root := filepath.Join(os.Getenv("GOPATH"), "src", projectPath("fixtures/follow"))
+	for file, content := range followFixtures {
+		path := filepath.Join(root, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+
+	return func() {
+		require.NoError(t, os.RemoveAll(root))
+	}
+}
+
+func scanFollowFixture(t *testing.T) []*scanner.Package {
+	sc, err := scanner.New(projectPath("fixtures/follow/a"))
+	require.NoError(t, err)
+	pkgs, err := sc.Scan()
+	require.NoError(t, err)
+	return pkgs
+}
+
+func TestFollow(t *testing.T) {
+	require := require.New(t)
+	defer writeFollowFixtures(t)()
+
+	pkgs, followed := New().Follow(scanFollowFixture(t), []string{projectPath("fixtures/follow")}, 0)
+	require.Len(pkgs, 3)
+
+	a, b, c := projectPath("fixtures/follow/a"), projectPath("fixtures/follow/b"), projectPath("fixtures/follow/c")
+	require.Equal([]*Followed{
+		{Path: b, Depth: 1, Types: []string{b + ".B", b + ".Kind"}, By: []string{a + ".A"}},
+		{Path: c, Depth: 2, Types: []string{c + ".C"}, By: []string{b + ".B"}},
+	}, followed)
+
+	require.Equal(b, pkgs[1].Path)
+	require.Len(pkgs[1].Structs, 1)
+	require.Equal("B", pkgs[1].Structs[0].Name)
+	require.True(pkgs[1].Structs[0].Generate)
+	require.Len(pkgs[1].Enums, 1)
+	require.Equal("Kind", pkgs[1].Enums[0].Name)
+	require.Len(pkgs[1].Funcs, 0)
+
+	New().Resolve(pkgs)
+	require.Len(pkgs[0].Structs[0].Fields, 2, "fields of followed types are kept")
+	require.Len(pkgs[1].Structs, 1)
+	require.Len(pkgs[2].Structs, 1)
+	require.Equal("C", pkgs[2].Structs[0].Name)
+}
+
+func TestFollowDepth(t *testing.T) {
+	require := require.New(t)
+	defer writeFollowFixtures(t)()
+
+	report.TestMode()
+	defer report.EndTestMode()
+
+	pkgs, followed := New().Follow(scanFollowFixture(t), []string{projectPath("fixtures/follow")}, 1)
+	require.Len(pkgs, 2)
+	require.Len(followed, 1)
+	require.Equal(projectPath("fixtures/follow/b"), followed[0].Path)
+	require.Contains(report.MessageStack(), "WARN: type "+projectPath("fixtures/follow/c")+".C used by "+projectPath("fixtures/follow/b")+".B is not followed, it is more than 1 packages away")
+}
+
+func TestFollowPrefixes(t *testing.T) {
+	require := require.New(t)
+	defer writeFollowFixtures(t)()
+
+	pkgs, followed := New().Follow(scanFollowFixture(t), []string{projectPath("fixtures/follow/c")}, 0)
+	require.Len(pkgs, 1)
+	require.Len(followed, 0)
+
+	r := New()
+	r.AddCustomType(projectPath("fixtures/follow/b") + ".*")
+	pkgs, followed = r.Follow(scanFollowFixture(t), []string{projectPath("fixtures/follow")}, 0)
+	require.Len(pkgs, 1, "custom types are not followed")
+	require.Len(followed, 0)
+}
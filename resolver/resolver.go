@@ -2,9 +2,11 @@ package resolver // import "gopkg.in/src-d/proteus.v1/resolver"
 
 import (
 	"fmt"
+	"strings"
 
 	"gopkg.in/src-d/proteus.v1/report"
 	"gopkg.in/src-d/proteus.v1/scanner"
+	"gopkg.in/src-d/proteus.v1/stdtypes"
 )
 
 // Resolver has the responsibility of checking the types of all the packages
@@ -16,19 +18,111 @@ import (
 // type `int`.
 type Resolver struct {
 	customTypes map[string]struct{}
+	// customPatterns are the custom types given as patterns, such as "*.ID".
+	customPatterns []string
+	// customPackages are the packages whose types are all custom types.
+	customPackages map[string]struct{}
+	trace          *Trace
+	// from is the full name of the struct field or func being resolved, and
+	// reason why the last type that could not be resolved was removed.
+	from, reason string
+}
+
+// Trace contains the decisions made by the resolver, which explain why the
+// structs, struct fields and funcs are kept or removed. The names are full
+// names, such as "github.com/org/pkg.Type.Field" for a field or
+// "github.com/org/pkg.Type.Method" for a method.
+type Trace struct {
+	// MarkedBy contains the name of the first struct field or func using
+	// every struct marked for generation because it is used, instead of with
+	// the //proteus:generate comment, by name of the struct.
+	MarkedBy map[string]string
+	// Removed contains the reason why every struct, struct field and func
+	// was removed, by name.
+	Removed map[string]string
 }
 
 // New creates a new Resolver with the default custom types registered.
-// These are time.Time and time.Duration. Those types will be considered correct
+// These are time.Time, time.Duration, error and the standard library types
+// with a built-in mapping, listed in stdtypes.Types, or their wrappers in the
+// stdtypes package if they need one. Those types will be considered correct
 // even though their packages are not in any of the packages given.
 func New() *Resolver {
-	return &Resolver{
+	r := &Resolver{
 		customTypes: map[string]struct{}{
 			"time.Time":     {},
 			"time.Duration": {},
 			"error":         {},
 		},
+		customPackages: make(map[string]struct{}),
+		trace: &Trace{
+			MarkedBy: make(map[string]string),
+			Removed:  make(map[string]string),
+		},
 	}
+
+	for _, name := range stdlibTypes() {
+		r.customTypes[name] = struct{}{}
+	}
+
+	return r
+}
+
+// DisableStdlibTypes removes the standard library types with a built-in
+// mapping from the custom types, so fields using them will be ignored unless
+// their packages are scanned.
+func (r *Resolver) DisableStdlibTypes() {
+	for _, name := range stdlibTypes() {
+		delete(r.customTypes, name)
+	}
+}
+
+// AddCustomType adds a custom type, which will be considered correct even
+// though its package is not in any of the packages given, because it has a
+// mapping. The name is the full name of the type, such as
+// "github.com/google/uuid.UUID", or a pattern, such as "*.ID" (see
+// scanner.MatchName).
+func (r *Resolver) AddCustomType(name string) {
+	if strings.ContainsAny(name, "*?[") {
+		r.customPatterns = append(r.customPatterns, name)
+		return
+	}
+	r.customTypes[name] = struct{}{}
+}
+
+// AddCustomPackage makes all the types of the package with the given path
+// custom types, as if they were added with AddCustomType.
+func (r *Resolver) AddCustomPackage(path string) {
+	r.customPackages[path] = struct{}{}
+}
+
+// Trace returns the decisions made by the resolver in all the calls to
+// Resolve.
+func (r *Resolver) Trace() *Trace {
+	return r.trace
+}
+
+// stdlibTypes returns the names of the standard library types with a built-in
+// mapping, which are the wrappers of the stdtypes package for the types that
+// need one, and the null types of database/sql.
+func stdlibTypes() []string {
+	var names []string
+	for name, wrapper := range stdtypes.Types {
+		// the null types of database/sql are kept, so the transformer can
+		// report that their wrappers must be used instead.
+		if wrapper == "" || strings.HasPrefix(name, "database/sql.") {
+			names = append(names, name)
+		}
+
+		if wrapper != "" {
+			names = append(names, stdtypes.WrapperName(name, wrapper))
+		}
+	}
+
+	for name := range stdtypes.TimeTypes {
+		names = append(names, fmt.Sprintf("%s.%s", stdtypes.Path, name))
+	}
+	return names
 }
 
 // Resolve checks the types of all the packages passed in a global manner.
@@ -42,21 +136,45 @@ func (r *Resolver) Resolve(pkgs []*scanner.Package) {
 	}
 }
 
+// IsCustomType reports whether the given type is a custom type, which is
+// considered correct even though its package is not scanned.
+func (r *Resolver) IsCustomType(n *scanner.Named) bool {
+	return r.isCustomType(n)
+}
+
 func (r *Resolver) isCustomType(n *scanner.Named) bool {
-	_, ok := r.customTypes[n.String()]
-	return ok
+	if _, ok := r.customTypes[n.String()]; ok {
+		return true
+	}
+
+	if _, ok := r.customTypes[n.InstanceName()]; ok {
+		return true
+	}
+
+	if _, ok := r.customPackages[n.Path]; ok && n.Path != "" {
+		return true
+	}
+
+	for _, pattern := range r.customPatterns {
+		if scanner.MatchName(pattern, n.String()) {
+			return true
+		}
+	}
+	return false
 }
 
 func (r *Resolver) resolvePackage(p *scanner.Package, info *packagesInfo) {
 	for _, s := range p.Structs {
-		r.resolveStruct(s, info)
+		r.resolveStruct(p, s, info)
 	}
 
 	var funcs = make([]*scanner.Func, 0, len(p.Funcs))
 	for _, f := range p.Funcs {
+		r.from = FuncName(p, f)
 		if r.resolveFunc(f, info) {
 			funcs = append(funcs, f)
 		} else {
+			r.trace.Removed[r.from] = fmt.Sprintf("it has an unresolvable type: %s", r.reason)
 			report.Warn("func %s had an unresolvable type and it will not be generated", f.Name)
 		}
 	}
@@ -98,18 +216,23 @@ func (r *Resolver) removeUnmarkedStructs(p *scanner.Package, info *packagesInfo)
 		name := fmt.Sprintf("%s.%s", p.Path, s.Name)
 		if info.isStructMarked(name) {
 			structs = append(structs, s)
+		} else {
+			r.trace.Removed[name] = "it is not marked with //proteus:generate and no struct or func of the scanned packages uses it"
 		}
 	}
 	p.Structs = structs
 }
 
-func (r *Resolver) resolveStruct(s *scanner.Struct, info *packagesInfo) {
+func (r *Resolver) resolveStruct(p *scanner.Package, s *scanner.Struct, info *packagesInfo) {
 	var result = make([]*scanner.Field, 0, len(s.Fields))
 
 	for _, f := range s.Fields {
+		r.from = fmt.Sprintf("%s.%s.%s", p.Path, s.Name, f.Name)
 		if typ := r.resolveType(f.Type, info); typ != nil {
 			f.Type = typ
 			result = append(result, f)
+		} else {
+			r.trace.Removed[r.from] = r.reason
 		}
 	}
 
@@ -124,6 +247,7 @@ func (r *Resolver) resolveType(typ scanner.Type, info *packagesInfo) (result sca
 		}
 
 		if !info.hasPackage(t.Path) {
+			r.reason = fmt.Sprintf("type %s is not in the scanned packages and it is not a custom type", t)
 			report.Warn("type %q of package %s will be ignored because it was not present on the scan path.", t.Name, t.Path)
 			return nil
 		}
@@ -131,6 +255,7 @@ func (r *Resolver) resolveType(typ scanner.Type, info *packagesInfo) (result sca
 		alias := info.aliasOf(t)
 		if alias != nil {
 			if alias.IsRepeated() && t.IsRepeated() {
+				r.reason = fmt.Sprintf("type %s is a declaration of the repeated type %s and it is used repeated too, which is not supported", t, alias)
 				report.Warn(
 					"type %q of package %s is an alias for %s that is marked as repeated while the type is being used repeated too. Alias for repeated fields that are repeated are not currently supported, this field will be ignored.",
 					t.Name,
@@ -143,6 +268,9 @@ func (r *Resolver) resolveType(typ scanner.Type, info *packagesInfo) (result sca
 		}
 
 		if info.isStruct(t.String()) {
+			if !info.isStructMarked(t.String()) {
+				r.trace.MarkedBy[t.String()] = r.from
+			}
 			info.markStruct(t.String())
 		}
 
@@ -158,6 +286,15 @@ func (r *Resolver) resolveType(typ scanner.Type, info *packagesInfo) (result sca
 	return
 }
 
+// FuncName returns the full name of the given func of the package, which is
+// "PATH.Func" for functions and "PATH.Type.Method" for methods.
+func FuncName(p *scanner.Package, f *scanner.Func) string {
+	if n, ok := f.Receiver.(*scanner.Named); ok {
+		return fmt.Sprintf("%s.%s.%s", p.Path, n.Name, f.Name)
+	}
+	return fmt.Sprintf("%s.%s", p.Path, f.Name)
+}
+
 // getPackagesInfo retrieves some information about a list of packages like the
 // aliases in all of them combined and the paths of all the packages.
 // Note that enums are removed from the aliases as we do not want to
@@ -95,15 +95,53 @@ func (s *ResolverSuite) TestIsCustomType() {
 	}{
 		{"foo.bar/baz/bar", "Baz", false},
 		{"net/url", "URL", false},
+		{"math/big", "Int", false},
+		{"net", "IP", true},
+		{"gopkg.in/src-d/proteus.v1/stdtypes", "URL", true},
+		{"gopkg.in/src-d/proteus.v1/stdtypes", "IP", false},
 		{"time", "Time", true},
 		{"time", "Duration", true},
 	}
 
 	for _, c := range cases {
-		s.Equal(c.result, s.r.isCustomType(&scanner.Named{nil, c.path, c.name}), "%s.%s", c.path, c.name)
+		s.Equal(c.result, s.r.isCustomType(&scanner.Named{Path: c.path, Name: c.name}), "%s.%s", c.path, c.name)
 	}
 }
 
+func (s *ResolverSuite) TestAddCustomType() {
+	r := New()
+	uuid := scanner.NewNamed("github.com/google/uuid", "UUID").(*scanner.Named)
+	id := scanner.NewNamed("github.com/foo/bar", "ID").(*scanner.Named)
+	s.False(r.isCustomType(uuid))
+	s.False(r.isCustomType(id))
+
+	r.AddCustomType("github.com/google/uuid.UUID")
+	r.AddCustomType("*.ID")
+	s.True(r.isCustomType(uuid))
+	s.True(r.isCustomType(id))
+	s.False(r.isCustomType(scanner.NewNamed("github.com/foo/bar", "UserID").(*scanner.Named)))
+}
+
+func (s *ResolverSuite) TestAddCustomPackage() {
+	r := New()
+	uuid := scanner.NewNamed("github.com/google/uuid", "UUID").(*scanner.Named)
+	s.False(r.isCustomType(uuid))
+
+	r.AddCustomPackage("github.com/google/uuid")
+	s.True(r.isCustomType(uuid))
+	s.True(r.isCustomType(scanner.NewNamed("github.com/google/uuid", "NullUUID").(*scanner.Named)))
+	s.False(r.isCustomType(scanner.NewNamed("github.com/google/uuid/v2", "UUID").(*scanner.Named)))
+}
+
+func (s *ResolverSuite) TestDisableStdlibTypes() {
+	r := New()
+	r.DisableStdlibTypes()
+
+	s.False(r.isCustomType(&scanner.Named{Path: "net", Name: "IP"}), "net.IP")
+	s.False(r.isCustomType(&scanner.Named{Path: "gopkg.in/src-d/proteus.v1/stdtypes", Name: "URL"}), "stdtypes.URL")
+	s.True(r.isCustomType(&scanner.Named{Path: "time", Name: "Time"}), "time.Time")
+}
+
 func (s *ResolverSuite) TestNotInScanPathWarning() {
 	report.TestMode()
 
@@ -193,7 +231,7 @@ func (s *ResolverSuite) TestResolve() {
 	s.Equal(4, len(pkgs[1].Funcs), "num of funcs in subpkg")
 
 	s.Equal(&scanner.Func{
-		Docs: mkDocs("Generated ..."),
+		Docs: mkGeneratedDocs("Generated ..."),
 		Name: "Generated",
 		Input: []scanner.Type{
 			scanner.NewBasic("string"),
@@ -205,7 +243,7 @@ func (s *ResolverSuite) TestResolve() {
 	}, findFuncByName("Generated", pkgs[1].Funcs))
 
 	s.Equal(&scanner.Func{
-		Docs: mkDocs("GeneratedMethod ..."),
+		Docs: mkGeneratedDocs("GeneratedMethod ..."),
 		Name: "GeneratedMethod",
 		Input: []scanner.Type{
 			scanner.NewBasic("int32"),
@@ -217,7 +255,7 @@ func (s *ResolverSuite) TestResolve() {
 	}, findFuncByName("GeneratedMethod", pkgs[1].Funcs))
 
 	s.Equal(&scanner.Func{
-		Docs: mkDocs("GeneratedMethodOnPointer ..."),
+		Docs: mkGeneratedDocs("GeneratedMethodOnPointer ..."),
 		Name: "GeneratedMethodOnPointer",
 		Input: []scanner.Type{
 			scanner.NewBasic("bool"),
@@ -229,7 +267,7 @@ func (s *ResolverSuite) TestResolve() {
 	}, findFuncByName("GeneratedMethodOnPointer", pkgs[1].Funcs))
 
 	s.Equal(&scanner.Func{
-		Docs:  mkDocs("Name ..."),
+		Docs:  mkGeneratedDocs("Name ..."),
 		Name:  "Name",
 		Input: []scanner.Type{},
 		Output: []scanner.Type{
@@ -289,3 +327,49 @@ func nullable(t scanner.Type) scanner.Type {
 func mkDocs(docs ...string) scanner.Docs {
 	return scanner.Docs{Doc: docs}
 }
+
+func mkGeneratedDocs(docs ...string) scanner.Docs {
+	d := mkDocs(docs...)
+	d.Directives = scanner.Directives{"generate": {""}}
+	return d
+}
+
+func TestTrace(t *testing.T) {
+	require := require.New(t)
+	report.TestMode()
+	defer report.EndTestMode()
+
+	field := func(name, path, typ string) *scanner.Field {
+		return &scanner.Field{Name: name, Type: scanner.NewNamed(path, typ)}
+	}
+
+	pkg := &scanner.Package{
+		Path:    "foo",
+		Aliases: map[string]scanner.Type{},
+		Structs: []*scanner.Struct{
+			{Name: "Gen", Generate: true, Fields: []*scanner.Field{
+				field("Used", "foo", "Used"),
+				field("Ext", "bar", "Ext"),
+				field("Other", "foo", "Gen"),
+			}},
+			{Name: "Used", Fields: []*scanner.Field{field("Gen", "foo", "Gen")}},
+			{Name: "Unused"},
+		},
+		Funcs: []*scanner.Func{
+			{Name: "Ext", Input: []scanner.Type{scanner.NewNamed("bar", "Ext")}},
+			{Name: "Do", Receiver: scanner.NewNamed("foo", "Gen"), Input: []scanner.Type{scanner.NewNamed("foo", "Used")}},
+		},
+	}
+
+	r := New()
+	r.Resolve([]*scanner.Package{pkg})
+
+	require.Equal(map[string]string{"foo.Used": "foo.Gen.Used"}, r.Trace().MarkedBy)
+
+	notScanned := "type bar.Ext is not in the scanned packages and it is not a custom type"
+	require.Equal(map[string]string{
+		"foo.Gen.Ext": notScanned,
+		"foo.Ext":     "it has an unresolvable type: " + notScanned,
+		"foo.Unused":  "it is not marked with //proteus:generate and no struct or func of the scanned packages uses it",
+	}, r.Trace().Removed)
+}
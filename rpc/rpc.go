@@ -42,16 +42,29 @@ import (
 // implement its receiver by yourself in the server implementation type and the
 // constructor.
 //
+// If validation is enabled, the request is validated before calling the
+// method with a function named `validateRequest` that receives the request and
+// returns an error. By default, it validates the request with
+// go-playground/validator and returns an InvalidArgument error with the paths
+// of the invalid fields, but it can be defined in the package to customize it.
+//
 // A single file per package will be generated containing all the RPC methods.
 // The file will be written to the package path and it will be named
 // "server.proteus.go"
 type Generator struct {
 	importer *parseutil.Importer
+	validate bool
 }
 
 // NewGenerator creates a new Generator.
 func NewGenerator() *Generator {
-	return &Generator{parseutil.NewImporter()}
+	return &Generator{importer: parseutil.NewImporter()}
+}
+
+// EnableValidation makes the generated methods validate the requests before
+// calling the methods.
+func (g *Generator) EnableValidation() {
+	g.validate = true
 }
 
 // Generate creates a new file in the package at the given path and implements
@@ -98,7 +111,15 @@ func (g *Generator) Generate(proto *protobuf.Package, path string) error {
 		decls = append(decls, g.declMethod(ctx, rpc))
 	}
 
-	return g.writeFile(g.buildFile(ctx, decls), path)
+	var extra string
+	if g.validate && !ctx.isNameDefined(validateRequestName) {
+		for _, i := range validateRequestImports {
+			ctx.addImport(i)
+		}
+		extra = validateRequestFunc
+	}
+
+	return g.writeFile(g.buildFile(ctx, decls), path, extra)
 }
 
 func (g *Generator) declImplType(implName string) ast.Decl {
@@ -310,11 +331,16 @@ func (g *Generator) genMethodBodyForNotGeneratedOutput(ctx *context, rpc *protob
 
 func (g *Generator) declMethod(ctx *context, rpc *protobuf.RPC) ast.Decl {
 	typ := g.genMethodType(ctx, rpc)
+	body := g.genMethodBody(ctx, rpc, typ)
+	if g.validate {
+		body.List = append([]ast.Stmt{g.genValidateRequest()}, body.List...)
+	}
+
 	return &ast.FuncDecl{
 		Recv: fields(field("s", ptr(ast.NewIdent(ctx.implName)))),
 		Name: ast.NewIdent(rpc.Name),
 		Type: typ,
-		Body: g.genMethodBody(ctx, rpc, typ),
+		Body: body,
 	}
 }
 
@@ -338,7 +364,8 @@ func (g *Generator) buildFile(ctx *context, decls []ast.Decl) *ast.File {
 	return f
 }
 
-func (g *Generator) writeFile(file *ast.File, path string) error {
+// writeFile writes the file and the extra source code after it.
+func (g *Generator) writeFile(file *ast.File, path string, extra string) error {
 	fileName := filepath.Join(goSrc, path, "server.proteus.go")
 	f, err := os.Create(fileName)
 	if err != nil {
@@ -346,7 +373,12 @@ func (g *Generator) writeFile(file *ast.File, path string) error {
 	}
 	defer f.Close()
 
-	return printer.Fprint(f, token.NewFileSet(), file)
+	if err := printer.Fprint(f, token.NewFileSet(), file); err != nil {
+		return err
+	}
+
+	_, err = f.WriteString(extra)
+	return err
 }
 
 func typeName(t protobuf.Type) string {
@@ -0,0 +1,88 @@
+package rpc
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// validateRequestName is the name of the function used to validate the
+// requests. If it is already defined in the package, the default one is not
+// generated, so it can be customized.
+const validateRequestName = "validateRequest"
+
+// validateRequestImports are the imports needed by validateRequestFunc.
+var validateRequestImports = []string{
+	"fmt",
+	"strings",
+	"github.com/go-playground/validator/v10",
+	"google.golang.org/genproto/googleapis/rpc/errdetails",
+	"google.golang.org/grpc/codes",
+	"google.golang.org/grpc/status",
+}
+
+// validateRequestFunc is the default implementation of validateRequest,
+// which runs go-playground/validator on the request, that is, the same rules
+// translated to field constraints in the proto file.
+const validateRequestFunc = `
+var requestValidator = validator.New()
+
+// validateRequest validates the request with the rules of the validate struct
+// tags of its fields. If it is not valid, an InvalidArgument error is returned
+// with the path of every invalid field in its BadRequest details.
+func validateRequest(req interface{}) error {
+	err := requestValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	errs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var (
+		fields     []string
+		violations []*errdetails.BadRequest_FieldViolation
+	)
+	for _, e := range errs {
+		path := e.Namespace()
+		path = path[strings.Index(path, ".")+1:]
+		fields = append(fields, path)
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       path,
+			Description: fmt.Sprintf("failed on the %q rule", e.Tag()),
+		})
+	}
+
+	st := status.New(codes.InvalidArgument, "invalid fields: "+strings.Join(fields, ", "))
+	if detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+`
+
+// genValidateRequest returns the statement that validates the request and
+// returns if it is not valid.
+func (g *Generator) genValidateRequest() ast.Stmt {
+	return &ast.IfStmt{
+		Init: &ast.AssignStmt{
+			Tok: token.ASSIGN,
+			Lhs: []ast.Expr{ast.NewIdent("err")},
+			Rhs: []ast.Expr{
+				&ast.CallExpr{
+					Fun:  ast.NewIdent(validateRequestName),
+					Args: []ast.Expr{ast.NewIdent("in")},
+				},
+			},
+		},
+		Cond: &ast.BinaryExpr{
+			X:  ast.NewIdent("err"),
+			Op: token.NEQ,
+			Y:  ast.NewIdent("nil"),
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{new(ast.ReturnStmt)},
+		},
+	}
+}
@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/resolver"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+const expectedValidatedFunc = `func (s *FooServer) DoFoo(ctx context.Context, in *FooRequest) (result *FooResponse, err error) {
+	if err = validateRequest(in); err != nil {
+		return
+	}
+	result = new(FooResponse)
+	result.Result1, err = DoFoo(in.Arg1)
+	return
+}`
+
+func (s *RPCSuite) TestDeclMethodValidation() {
+	s.g.EnableValidation()
+
+	ctx := &context{
+		implName: "FooServer",
+		proto: &protobuf.Package{
+			Messages: []*protobuf.Message{
+				&protobuf.Message{
+					Name: "FooRequest",
+					Fields: []*protobuf.Field{
+						&protobuf.Field{Name: "Arg1", Pos: 1, Type: protobuf.NewBasic("int64")},
+					},
+				},
+				&protobuf.Message{
+					Name: "FooResponse",
+					Fields: []*protobuf.Field{
+						&protobuf.Field{Name: "Result1", Pos: 1, Type: protobuf.NewBasic("int64")},
+					},
+				},
+			},
+		},
+		pkg: s.fakePkg(),
+	}
+
+	output, err := render(s.g.declMethod(ctx, &protobuf.RPC{
+		Name:     "DoFoo",
+		Method:   "DoFoo",
+		HasError: true,
+		Input:    nullable(protobuf.NewGeneratedNamed("", "FooRequest")),
+		Output:   nullable(protobuf.NewGeneratedNamed("", "FooResponse")),
+	}))
+	s.Nil(err)
+	s.Equal(expectedValidatedFunc, output)
+}
+
+func (s *RPCSuite) TestGenerateValidation() {
+	pkg := "gopkg.in/src-d/proteus.v1/fixtures/subpkg"
+	scanner, err := scanner.New(pkg)
+	s.Nil(err)
+
+	pkgs, err := scanner.Scan()
+	s.Nil(err)
+
+	r := resolver.New()
+	r.Resolve(pkgs)
+
+	s.g.EnableValidation()
+	t := protobuf.NewTransformer()
+	s.Nil(s.g.Generate(t.Transform(pkgs[0]), pkg))
+
+	path := projectPath("fixtures/subpkg/server.proteus.go")
+	data, err := ioutil.ReadFile(path)
+	s.Nil(err)
+	s.Nil(os.Remove(path))
+
+	content := string(data)
+	s.True(strings.HasSuffix(content, validateRequestFunc))
+	s.Contains(content, `"github.com/go-playground/validator/v10"`)
+	s.Contains(content, `"google.golang.org/grpc/codes"`)
+	s.Contains(content, "\tif err = validateRequest(in); err != nil {\n\t\treturn\n\t}\n")
+}
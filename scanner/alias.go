@@ -0,0 +1,12 @@
+//go:build go1.22
+// +build go1.22
+
+package scanner
+
+import "go/types"
+
+// unalias returns the type a type alias, such as stdtypes.NullOfString,
+// stands for, or the given type if it is not an alias.
+func unalias(typ types.Type) types.Type {
+	return types.Unalias(typ)
+}
@@ -0,0 +1,12 @@
+//go:build !go1.22
+// +build !go1.22
+
+package scanner
+
+import "go/types"
+
+// unalias returns the given type, as type aliases are only represented in
+// go/types since Go 1.22.
+func unalias(typ types.Type) types.Type {
+	return typ
+}
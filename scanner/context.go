@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"sort"
 	"strings"
 
 	"gopkg.in/src-d/go-parse-utils.v1"
@@ -30,6 +31,8 @@ type context struct {
 	enumValues map[string][]string
 	// enums with string method
 	enumWithString []string
+	// pkgDirectives contains the directives in the package documentation.
+	pkgDirectives Directives
 }
 
 func newContext(path string) (*context, error) {
@@ -45,9 +48,26 @@ func newContext(path string) (*context, error) {
 		consts:         findObjectsOfType(pkg, ast.Con),
 		enumValues:     make(map[string][]string),
 		enumWithString: []string{},
+		pkgDirectives:  findPkgDirectives(pkg),
 	}, nil
 }
 
+// findPkgDirectives collects the directives of the package documentation of
+// all the files in the package, sorted by file name.
+func findPkgDirectives(pkg *ast.Package) Directives {
+	var names []string
+	for name := range pkg.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var docs []*ast.CommentGroup
+	for _, name := range names {
+		docs = append(docs, pkg.Files[name].Doc)
+	}
+	return findDirectives(docs...)
+}
+
 func findPkgTypesAndFuncs(pkg *ast.Package) (map[string]*ast.TypeSpec, map[string]*ast.FuncDecl) {
 	f := ast.MergePackageFiles(pkg, 0)
 
@@ -121,6 +141,25 @@ func (ctx *context) trySetDocs(name string, obj Documentable) {
 	}
 }
 
+// findProtos returns the arguments of the proto directives of the exported
+// types, by type name.
+func (ctx *context) findProtos() map[string]string {
+	var protos map[string]string
+	for name, typ := range ctx.types {
+		if !ast.IsExported(name) {
+			continue
+		}
+
+		if args, ok := findDirectives(typ.Doc).Get(protoDirective); ok {
+			if protos == nil {
+				protos = make(map[string]string)
+			}
+			protos[name] = args
+		}
+	}
+	return protos
+}
+
 const genComment = `//proteus:generate`
 
 func (ctx *context) shouldGenerateType(name string) bool {
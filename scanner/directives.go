@@ -0,0 +1,59 @@
+package scanner
+
+import (
+	"go/ast"
+	"strings"
+)
+
+const directivePrefix = "//proteus:"
+
+// protoDirective is the directive of the types that are existing protobuf
+// messages, e.g. `//proteus:proto common.Money import="common/money.proto"`.
+const protoDirective = "proto"
+
+// Directives holds the arguments of the `//proteus:NAME ARGS` comments found
+// in the documentation of an entity, indexed by NAME. A directive can appear
+// more than once, so all its arguments are kept in order of appearance.
+type Directives map[string][]string
+
+// Get returns the arguments of the last directive with the given name and
+// whether it was found or not.
+func (d Directives) Get(name string) (string, bool) {
+	args, ok := d[name]
+	if !ok || len(args) == 0 {
+		return "", false
+	}
+	return args[len(args)-1], true
+}
+
+// findDirectives collects all the directives in the given comment groups.
+func findDirectives(groups ...*ast.CommentGroup) Directives {
+	var directives Directives
+	for _, g := range groups {
+		if g == nil {
+			continue
+		}
+
+		for _, c := range g.List {
+			if !isDirective(c.Text) {
+				continue
+			}
+
+			text := strings.TrimPrefix(c.Text, directivePrefix)
+			name, args := text, ""
+			if idx := strings.IndexAny(text, " \t"); idx >= 0 {
+				name, args = text[:idx], strings.TrimSpace(text[idx+1:])
+			}
+
+			if directives == nil {
+				directives = make(Directives)
+			}
+			directives[name] = append(directives[name], args)
+		}
+	}
+	return directives
+}
+
+func isDirective(comment string) bool {
+	return strings.HasPrefix(comment, directivePrefix)
+}
@@ -0,0 +1,124 @@
+package scanner
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDirectives(t *testing.T) {
+	require := require.New(t)
+
+	d := findDirectives(
+		nil,
+		&ast.CommentGroup{List: []*ast.Comment{
+			{Text: "// Package foo does things."},
+			{Text: "//proteus:generate"},
+			{Text: "//proteus:time   millis "},
+		}},
+		&ast.CommentGroup{List: []*ast.Comment{
+			{Text: "// proteus:time nanos"},
+			{Text: "//proteus:time string"},
+		}},
+	)
+
+	require.Equal(Directives{
+		"generate": {""},
+		"time":     {"millis", "string"},
+	}, d)
+
+	v, ok := d.Get("time")
+	require.True(ok)
+	require.Equal("string", v)
+
+	_, ok = d.Get("duration")
+	require.False(ok)
+
+	_, ok = Directives(nil).Get("time")
+	require.False(ok)
+}
+
+const directivesFile = `//proteus:duration seconds
+//proteus:time %s
+package foo
+`
+
+func TestFindPkgDirectives(t *testing.T) {
+	require := require.New(t)
+
+	fs := token.NewFileSet()
+	pkg := &ast.Package{Name: "foo", Files: make(map[string]*ast.File)}
+	for _, f := range []struct{ name, time string }{
+		{"b.go", "string"},
+		{"a.go", "millis"},
+	} {
+		file, err := parser.ParseFile(fs, f.name, fmt.Sprintf(directivesFile, f.time), parser.ParseComments)
+		require.Nil(err)
+		pkg.Files[f.name] = file
+	}
+
+	d := findPkgDirectives(pkg)
+	require.Equal([]string{"millis", "string"}, d["time"])
+	require.Equal([]string{"seconds", "seconds"}, d["duration"])
+}
+
+func TestDocsWithoutDirectives(t *testing.T) {
+	var docs Docs
+	docs.SetDocs(&ast.CommentGroup{List: []*ast.Comment{
+		{Text: "// Foo is a thing."},
+		{Text: "//proteus:generate"},
+		{Text: "//proteus:time millis"},
+	}})
+
+	require.Equal(t, []string{"Foo is a thing."}, docs.Doc)
+	require.Equal(t, Directives{"generate": {""}, "time": {"millis"}}, docs.Directives)
+}
+
+func TestFindProtoTagsQuoted(t *testing.T) {
+	require.Equal(t,
+		[]string{"opt=(gogoproto.jsontag)='id,omitempty'", "type=sint64", "-"},
+		findProtoTags(`json:"id" proteus:"opt=(gogoproto.jsontag)='id,omitempty', type=sint64,-"`),
+	)
+}
+
+func TestFieldTag(t *testing.T) {
+	require := require.New(t)
+
+	f := &Field{Tags: []string{"time=millis", "-", "time=string"}}
+	v, ok := f.Tag("time")
+	require.True(ok)
+	require.Equal("string", v)
+
+	_, ok = f.Tag("duration")
+	require.False(ok)
+
+	_, ok = (&Field{}).Tag("time")
+	require.False(ok)
+}
+
+func TestFindProtos(t *testing.T) {
+	doc := func(lines ...string) *ast.CommentGroup {
+		g := &ast.CommentGroup{}
+		for _, l := range lines {
+			g.List = append(g.List, &ast.Comment{Text: l})
+		}
+		return g
+	}
+
+	ctx := &context{types: map[string]*ast.TypeSpec{
+		"Money":    {Doc: doc("// Money is an amount.", `//proteus:proto common.Money import="common/money.proto"`)},
+		"Currency": {Doc: doc("//proteus:generate")},
+		"amount":   {Doc: doc("//proteus:proto common.Amount")},
+		"Rate":     {},
+	}}
+
+	require.Equal(t, map[string]string{
+		"Money": `common.Money import="common/money.proto"`,
+	}, ctx.findProtos())
+
+	require.Nil(t, (&context{}).findProtos())
+}
@@ -0,0 +1,43 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ModuleRoot returns the path of the root folder of the module of the given
+// package, relative to the GOPATH like the paths of the packages, which is
+// the closest folder with a go.mod file or, if there is none, the root of
+// its repository, the closest folder with a .git folder. It returns an empty
+// string if the package is in neither of them.
+func ModuleRoot(pkg string) string {
+	src := filepath.Join(goPath, "src")
+	var repo string
+	for dir := filepath.Join(src, pkg); dir != src && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if exists(filepath.Join(dir, "go.mod")) {
+			return relativeToSrc(src, dir)
+		}
+
+		if repo == "" && exists(filepath.Join(dir, ".git")) {
+			repo = dir
+		}
+	}
+
+	if repo == "" {
+		return ""
+	}
+	return relativeToSrc(src, repo)
+}
+
+func relativeToSrc(src, dir string) string {
+	rel, err := filepath.Rel(src, dir)
+	if err != nil {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
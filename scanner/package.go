@@ -3,6 +3,8 @@ package scanner
 import (
 	"fmt"
 	"go/ast"
+	"path"
+	"reflect"
 	"strings"
 )
 
@@ -17,6 +19,12 @@ type Package struct {
 	Enums    []*Enum
 	Funcs    []*Func
 	Aliases  map[string]Type
+	// Directives contains the directives found in the package documentation.
+	Directives Directives
+	// Protos contains the arguments of the `//proteus:proto` directives of
+	// the types of the package, by type name. These types are existing
+	// protobuf messages, so they are referenced instead of generated.
+	Protos map[string]string
 }
 
 // collectEnums finds the enum values collected during the scan and generates
@@ -32,7 +40,12 @@ func (p *Package) collectEnums(ctx *context) {
 
 			hasStringMethod := containsString(ctx.enumWithString, k)
 
-			p.Enums = append(p.Enums, newEnum(ctx, name, vals, hasStringMethod))
+			enum := newEnum(ctx, name, vals, hasStringMethod)
+			if basic, ok := p.Aliases[k].(*Basic); ok {
+				enum.Underlying = basic.Name
+			}
+
+			p.Enums = append(p.Enums, enum)
 			delete(p.Aliases, k)
 		}
 	}
@@ -59,6 +72,11 @@ type Type interface {
 	IsRepeated() bool
 	SetNullable(bool)
 	IsNullable() bool
+	// SetArrayLen sets the length of the type if it is a fixed-size array.
+	SetArrayLen(int64)
+	// ArrayLen returns the length of the type if it is a fixed-size array, or
+	// 0 otherwise.
+	ArrayLen() int64
 	// TypeString returns a string representing the final type.
 	// Though this might seem that this should be just String, for Alias types
 	// both representations are different: a string representation of the final
@@ -72,6 +90,9 @@ type Type interface {
 type BaseType struct {
 	Repeated bool
 	Nullable bool
+	// Len is the length of the fixed-size array if the type is repeated
+	// because it is an array.
+	Len int64
 }
 
 func newBaseType() *BaseType {
@@ -93,6 +114,12 @@ func (t *BaseType) IsNullable() bool { return t.Nullable }
 // SetNullable sets the type as pointer.
 func (t *BaseType) SetNullable(v bool) { t.Nullable = v }
 
+// ArrayLen returns the length of the array or 0 if it is not an array.
+func (t *BaseType) ArrayLen() int64 { return t.Len }
+
+// SetArrayLen sets the length of the array.
+func (t *BaseType) SetArrayLen(v int64) { t.Len = v }
+
 // TypeString returns a string representation for the type casting
 func (t *BaseType) TypeString() string { panic("not implemented") }
 
@@ -139,6 +166,10 @@ type Named struct {
 	*BaseType
 	Path string
 	Name string
+	// TypeArgs are the type arguments of an instantiation of a generic type,
+	// qualified with their package path, such as "time.Time" in
+	// Null[time.Time].
+	TypeArgs []string
 }
 
 // String returns a string representation for the type
@@ -149,6 +180,17 @@ func (n Named) String() string {
 	return fmt.Sprintf("%s.%s", n.Path, n.Name)
 }
 
+// InstanceName returns the full name of the type including its type
+// arguments, such as "database/sql.Null[time.Time]", which is used to find
+// the mappings of the instantiations of generic types. It is the same as
+// String for the rest of types.
+func (n Named) InstanceName() string {
+	if len(n.TypeArgs) == 0 {
+		return n.String()
+	}
+	return fmt.Sprintf("%s[%s]", n.String(), strings.Join(n.TypeArgs, ","))
+}
+
 // TypeString returns a string representation for the type casting
 func (n Named) TypeString() string {
 	return n.String()
@@ -162,10 +204,47 @@ func (n Named) UnqualifiedName() string {
 // NewNamed creates a new named type given its package path and name.
 func NewNamed(path, name string) Type {
 	return &Named{
-		newBaseType(),
-		path,
-		name,
+		BaseType: newBaseType(),
+		Path:     path,
+		Name:     name,
+	}
+}
+
+// MatchName reports whether the full name of a type, as returned by
+// Named.String, matches the given pattern. Patterns are full names whose
+// package path and type name can have the wildcards of path.Match, such as
+// "github.com/google/uuid.*" or "github.com/org/*.ID", and a "*" package
+// path, as in "*.ID", matches any package. A pattern with no wildcards only
+// matches the same name.
+func MatchName(pattern, name string) bool {
+	if pattern == name {
+		return true
+	}
+
+	if !strings.ContainsAny(pattern, "*?[") {
+		return false
+	}
+
+	pkgPattern, namePattern := splitName(pattern)
+	pkg, typeName := splitName(name)
+	if ok, _ := path.Match(namePattern, typeName); !ok {
+		return false
+	}
+
+	if pkgPattern == "*" {
+		return pkg != ""
+	}
+
+	ok, _ := path.Match(pkgPattern, pkg)
+	return ok
+}
+
+// splitName splits a full type name into its package path and name.
+func splitName(name string) (string, string) {
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		return name[:idx], name[idx+1:]
 	}
+	return "", name
 }
 
 // Alias represents a type declaration from a type to another type
@@ -187,6 +266,14 @@ func NewAlias(typ, underlying Type) Type {
 func (a Alias) IsNullable() bool { return a.Type.IsNullable() || a.Underlying.IsNullable() }
 func (a Alias) IsRepeated() bool { return a.Type.IsRepeated() || a.Underlying.IsRepeated() }
 
+// ArrayLen returns the length of the array if the aliased type is an array.
+func (a Alias) ArrayLen() int64 {
+	if a.Type.IsRepeated() {
+		return a.Type.ArrayLen()
+	}
+	return a.Underlying.ArrayLen()
+}
+
 // String returns a string representation for the type
 func (a Alias) String() string {
 	return fmt.Sprintf("type %s %s", a.Type.String(), a.Underlying.String())
@@ -242,15 +329,20 @@ type Documentable interface {
 // Docs holds the documentation of a struct, enum, value, field, etc.
 type Docs struct {
 	Doc []string
+	// Directives contains the directives found in the documentation.
+	Directives Directives
 }
 
 // SetDocs sets the documentation from an AST comment group.
-// It removes the //proteus:generate comment from the comments.
+// It removes the //proteus:generate comment and the rest of directives from
+// the comments, which are kept in Directives.
 func (d *Docs) SetDocs(comments *ast.CommentGroup) {
+	d.Directives = findDirectives(comments)
+
 	var list []*ast.Comment
 	if comments != nil {
 		for _, c := range comments.List {
-			if !strings.HasPrefix(c.Text, genComment) {
+			if !isDirective(c.Text) {
 				list = append(list, c)
 			}
 		}
@@ -269,6 +361,9 @@ type Enum struct {
 	Name       string
 	Values     []*EnumValue
 	IsStringer bool
+	// Underlying is the name of the basic type the enum is declared as, e.g.
+	// byte for "type Kind byte".
+	Underlying string
 }
 
 // EnumValue is a possible value of an enum.
@@ -302,6 +397,22 @@ type Field struct {
 	Docs
 	Name string
 	Type Type
+	// Tags contains the options given in the proteus struct tag of the field.
+	Tags []string
+	// StructTag is the whole struct tag of the field.
+	StructTag reflect.StructTag
+}
+
+// Tag returns the value of the `name=value` option with the given name in
+// the proteus struct tag of the field and whether it was found or not.
+func (f *Field) Tag(name string) (string, bool) {
+	return findTagValue(f.Tags, name)
+}
+
+// TagValues returns the values of all the `name=value` options with the
+// given name in the proteus struct tag of the field.
+func (f *Field) TagValues(name string) []string {
+	return findTagValues(f.Tags, name)
 }
 
 // Func is either a function or a method. Receiver will be nil in functions,
@@ -87,6 +87,20 @@ func TestAlias_IsRepeated(t *testing.T) {
 	assert.True(t, typ.IsRepeated(), "Alias is repeated if both the type and the underlying are")
 }
 
+func TestAlias_ArrayLen(t *testing.T) {
+	typ := NewAlias(newBaseType(), newBaseType()).(*Alias)
+	assert.Equal(t, int64(0), typ.ArrayLen(), "Alias is not an array if neither the type nor the underlying is")
+
+	typ.Underlying.SetRepeated(true)
+	typ.Underlying.SetArrayLen(32)
+	assert.Equal(t, int64(32), typ.ArrayLen(), "Alias has the length of the underlying array")
+
+	typ.Type.SetRepeated(true)
+	assert.Equal(t, int64(0), typ.ArrayLen(), "Alias used in a slice is not an array")
+	typ.Type.SetArrayLen(4)
+	assert.Equal(t, int64(4), typ.ArrayLen(), "Alias used in an array has the length of that array")
+}
+
 func TestAlias_stringMethods(t *testing.T) {
 	typ := NewAlias(NewNamed("", "Aliasing"), NewBasic("string"))
 
@@ -123,3 +137,26 @@ func assertNullable(t *testing.T, typ Type, name string) {
 	typ.SetNullable(false)
 	assert.False(t, typ.IsNullable(), "%s can be set as not nullable", name)
 }
+
+func TestMatchName(t *testing.T) {
+	cases := []struct {
+		pattern, name string
+		match         bool
+	}{
+		{"time.Time", "time.Time", true},
+		{"time.Time", "time.Duration", false},
+		{"*.ID", "github.com/foo/bar.ID", true},
+		{"*.ID", "bar.ID", true},
+		{"*.ID", "ID", false},
+		{"*.ID", "github.com/foo/bar.UID", false},
+		{"github.com/google/uuid.*", "github.com/google/uuid.UUID", true},
+		{"github.com/google/uuid.*", "github.com/google/uuid/v2.UUID", false},
+		{"github.com/foo/*.ID", "github.com/foo/bar.ID", true},
+		{"github.com/foo/*.ID", "github.com/foo/bar/baz.ID", false},
+		{"github.com/foo/bar.*ID", "github.com/foo/bar.UserID", true},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.match, MatchName(c.pattern, c.name), "%s matches %s", c.pattern, c.name)
+	}
+}
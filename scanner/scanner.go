@@ -6,6 +6,7 @@ import (
 	"go/types"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"strings"
 	"sync"
@@ -115,9 +116,11 @@ func buildPackage(ctx *context, gopkg *types.Package) (*Package, error) {
 	objs := objectsInScope(gopkg.Scope())
 
 	pkg := &Package{
-		Path:    removeGoPath(gopkg),
-		Name:    gopkg.Name(),
-		Aliases: make(map[string]Type),
+		Path:       removeGoPath(gopkg),
+		Name:       gopkg.Name(),
+		Aliases:    make(map[string]Type),
+		Directives: ctx.pkgDirectives,
+		Protos:     ctx.findProtos(),
 	}
 
 	for _, o := range objs {
@@ -223,20 +226,23 @@ func nameForType(o types.Type) (name string) {
 }
 
 func scanType(typ types.Type) (t Type) {
-	switch u := typ.(type) {
+	switch u := unalias(typ).(type) {
 	case *types.Basic:
 		t = NewBasic(u.Name())
 	case *types.Named:
-		t = NewNamed(
-			removeGoPath(u.Obj().Pkg()),
-			u.Obj().Name(),
-		)
+		t = &Named{
+			BaseType: newBaseType(),
+			Path:     removeGoPath(u.Obj().Pkg()),
+			Name:     u.Obj().Name(),
+			TypeArgs: typeArgs(u),
+		}
 	case *types.Slice:
 		t = scanType(u.Elem())
 		t.SetRepeated(true)
 	case *types.Array:
 		t = scanType(u.Elem())
 		t.SetRepeated(true)
+		t.SetArrayLen(u.Len())
 	case *types.Pointer:
 		t = scanType(u.Elem())
 		t.SetNullable(true)
@@ -252,6 +258,23 @@ func scanType(typ types.Type) (t Type) {
 	return
 }
 
+// typeArgs returns the type arguments of an instantiation of a generic type,
+// qualified with their package path (e.g. "time.Time" for Null[time.Time]),
+// so the mappings of the instantiations can be told apart.
+func typeArgs(n *types.Named) []string {
+	args := n.TypeArgs()
+	if args.Len() == 0 {
+		return nil
+	}
+
+	var names = make([]string, args.Len())
+	for i := 0; i < args.Len(); i++ {
+		names[i] = types.TypeString(args.At(i), removeGoPath)
+	}
+
+	return names
+}
+
 func scanEnumValue(ctx *context, name string, named *types.Named, hasStringMethod bool) {
 	typ := objName(named.Obj())
 	ctx.enumValues[typ] = append(ctx.enumValues[typ], name)
@@ -288,8 +311,10 @@ func scanStruct(s *Struct, elem *types.Struct) *Struct {
 		}
 
 		f := &Field{
-			Name: v.Name(),
-			Type: scanType(v.Type()),
+			Name:      v.Name(),
+			Type:      scanType(v.Type()),
+			Tags:      tags,
+			StructTag: reflect.StructTag(elem.Tag(i)),
 		}
 		if f.Type == nil {
 			continue
@@ -45,7 +45,7 @@ func TestScanType(t *testing.T) {
 		{
 			"basic array",
 			types.NewArray(types.Typ[types.Int], 8),
-			repeated(NewBasic("int")),
+			array(NewBasic("int"), 8),
 		},
 		{
 			"basic slice",
@@ -76,13 +76,23 @@ func TestScanType(t *testing.T) {
 		{
 			"array of pointers",
 			types.NewArray(types.NewPointer(types.Typ[types.Int]), 8),
-			nullable(repeated(NewBasic("int"))),
+			nullable(array(NewBasic("int"), 8)),
 		},
 		{
 			"slice of pointers",
 			types.NewSlice(types.NewPointer(types.Typ[types.Int])),
 			nullable(repeated(NewBasic("int"))),
 		},
+		{
+			"generic instantiation",
+			newGenericInstance("database/sql", "Null", newNamedWithUnderlying("time", "Time", nil)),
+			&Named{BaseType: newBaseType(), Path: "database/sql", Name: "Null", TypeArgs: []string{"time.Time"}},
+		},
+		{
+			"generic instantiation with several type arguments",
+			newGenericInstance("/foo/bar", "Pair", types.Typ[types.String], types.Typ[types.Byte]),
+			&Named{BaseType: newBaseType(), Path: "/foo/bar", Name: "Pair", TypeArgs: []string{"string", "uint8"}},
+		},
 		{
 			"struct",
 			types.NewStruct(nil, nil),
@@ -249,6 +259,27 @@ func TestScanStruct(t *testing.T) {
 				},
 			},
 		},
+		{
+			"struct with tag options",
+			types.NewStruct(
+				[]*types.Var{
+					mkField("Foo", types.Typ[types.Int], false),
+					mkField("Bar", types.Typ[types.String], false),
+				},
+				[]string{`json:"foo"`, `proteus:"time=millis, duration=string"`},
+			),
+			&Struct{
+				Fields: []*Field{
+					{Name: "Foo", Type: NewBasic("int"), StructTag: `json:"foo"`},
+					{
+						Name:      "Bar",
+						Type:      NewBasic("string"),
+						Tags:      []string{"time=millis", "duration=string"},
+						StructTag: `proteus:"time=millis, duration=string"`,
+					},
+				},
+			},
+		},
 		{
 			"invalid embedded type",
 			types.NewStruct(
@@ -430,6 +461,7 @@ func TestScanner(t *testing.T) {
 
 	require.Equal(1, len(pkg.Enums), "pkg enums")
 	require.Equal("Baz", pkg.Enums[0].Name)
+	require.Equal("byte", pkg.Enums[0].Underlying)
 
 	assertEnumValues(t, pkg.Enums[0].Values, "ABaz", "BBaz", "CBaz", "DBaz")
 
@@ -543,6 +575,12 @@ func repeated(t Type) Type {
 	return t
 }
 
+func array(t Type, len int64) Type {
+	t.SetRepeated(true)
+	t.SetArrayLen(len)
+	return t
+}
+
 func nullable(t Type) Type {
 	t.SetNullable(true)
 	return t
@@ -558,6 +596,26 @@ func newNamedWithUnderlying(path, name string, underlying types.Type) types.Type
 	return types.NewNamed(obj, underlying, nil)
 }
 
+func newGenericInstance(path, name string, args ...types.Type) types.Type {
+	obj := types.NewTypeName(token.NoPos, types.NewPackage(path, "mock"), name, nil)
+	named := types.NewNamed(obj, types.NewStruct(nil, nil), nil)
+
+	var params = make([]*types.TypeParam, len(args))
+	for i := range args {
+		params[i] = types.NewTypeParam(
+			types.NewTypeName(token.NoPos, nil, fmt.Sprintf("T%d", i), nil),
+			types.NewInterfaceType(nil, nil),
+		)
+	}
+	named.SetTypeParams(params)
+
+	inst, err := types.Instantiate(nil, named, args, false)
+	if err != nil {
+		panic(err)
+	}
+	return inst
+}
+
 func projectPkg(pkg string) string {
 	return filepath.Join(project, pkg)
 }
@@ -565,3 +623,17 @@ func projectPkg(pkg string) string {
 func absPath(path string) string {
 	return filepath.Join(goPath, "src", project, path)
 }
+
+func TestModuleRoot(t *testing.T) {
+	require := require.New(t)
+
+	require.Nil(os.MkdirAll(absPath("fixtures/module/foo/bar"), 0777))
+	defer os.RemoveAll(absPath("fixtures/module"))
+
+	require.Nil(os.MkdirAll(absPath("fixtures/module/.git"), 0777))
+	require.Equal(projectPkg("fixtures/module"), ModuleRoot(projectPkg("fixtures/module/foo/bar")))
+
+	require.Nil(ioutil.WriteFile(absPath("fixtures/module/foo/go.mod"), []byte("module example.com/foo\n"), 0777))
+	require.Equal(projectPkg("fixtures/module/foo"), ModuleRoot(projectPkg("fixtures/module/foo/bar")))
+	require.Equal(projectPkg("fixtures/module/foo"), ModuleRoot(projectPkg("fixtures/module/foo")))
+}
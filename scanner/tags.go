@@ -12,9 +12,50 @@ func findProtoTags(tag string) []string {
 		return nil
 	}
 
-	tags := strings.Split(protoTagRegex.FindStringSubmatch(tag)[1], ",")
+	tags := splitTags(protoTagRegex.FindStringSubmatch(tag)[1])
 	for i, t := range tags {
 		tags[i] = strings.TrimSpace(t)
 	}
 	return tags
 }
+
+// splitTags splits the options of a tag by commas, except the ones between
+// single quotes, which can be used to write string values.
+func splitTags(tag string) []string {
+	var (
+		tags   []string
+		quoted bool
+		start  int
+	)
+	for i, r := range tag {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			tags = append(tags, tag[start:i])
+			start = i + 1
+		}
+	}
+	return append(tags, tag[start:])
+}
+
+// findTagValue returns the value of the `name=value` option with the given
+// name in the list of tags and whether it was found or not.
+func findTagValue(tags []string, name string) (string, bool) {
+	values := findTagValues(tags, name)
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[len(values)-1], true
+}
+
+// findTagValues returns the values of all the `name=value` options with the
+// given name in the list of tags.
+func findTagValues(tags []string, name string) (values []string) {
+	for _, t := range tags {
+		if strings.HasPrefix(t, name+"=") {
+			values = append(values, strings.TrimSpace(t[len(name)+1:]))
+		}
+	}
+	return
+}
@@ -0,0 +1,113 @@
+package stdtypes
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// BigInt is a math/big.Int represented on the wire as the number in base 10.
+type BigInt struct {
+	*big.Int
+}
+
+// Marshal returns the number in base 10. A nil number is encoded as an empty
+// value.
+func (b BigInt) Marshal() ([]byte, error) {
+	if b.Int == nil {
+		return nil, nil
+	}
+	return b.Int.MarshalText()
+}
+
+// MarshalTo writes the number in base 10 to data.
+func (b *BigInt) MarshalTo(data []byte) (int, error) {
+	return marshalTo(b, data)
+}
+
+// Unmarshal parses the number in base 10 in data.
+func (b *BigInt) Unmarshal(data []byte) error {
+	if len(data) == 0 {
+		b.Int = nil
+		return nil
+	}
+
+	v, ok := new(big.Int).SetString(string(data), 10)
+	if !ok {
+		return fmt.Errorf("stdtypes: invalid big.Int %q", data)
+	}
+
+	b.Int = v
+	return nil
+}
+
+// Size returns the size of the number in base 10.
+func (b *BigInt) Size() int {
+	if b.Int == nil {
+		return 0
+	}
+	return len(b.Int.String())
+}
+
+// MarshalJSON encodes the number as a JSON string, so no precision is lost.
+func (b BigInt) MarshalJSON() ([]byte, error) {
+	return marshalJSON(b)
+}
+
+// UnmarshalJSON decodes the number from a JSON string.
+func (b *BigInt) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(b, data)
+}
+
+// BigRat is a math/big.Rat represented on the wire as the fraction in the
+// "a/b" form.
+type BigRat struct {
+	*big.Rat
+}
+
+// Marshal returns the fraction in the "a/b" form. A nil number is encoded as
+// an empty value.
+func (r BigRat) Marshal() ([]byte, error) {
+	if r.Rat == nil {
+		return nil, nil
+	}
+	return []byte(r.Rat.String()), nil
+}
+
+// MarshalTo writes the fraction to data.
+func (r *BigRat) MarshalTo(data []byte) (int, error) {
+	return marshalTo(r, data)
+}
+
+// Unmarshal parses the fraction in data.
+func (r *BigRat) Unmarshal(data []byte) error {
+	if len(data) == 0 {
+		r.Rat = nil
+		return nil
+	}
+
+	v, ok := new(big.Rat).SetString(string(data))
+	if !ok {
+		return fmt.Errorf("stdtypes: invalid big.Rat %q", data)
+	}
+
+	r.Rat = v
+	return nil
+}
+
+// Size returns the size of the fraction.
+func (r *BigRat) Size() int {
+	if r.Rat == nil {
+		return 0
+	}
+	return len(r.Rat.String())
+}
+
+// MarshalJSON encodes the fraction as a JSON string.
+func (r BigRat) MarshalJSON() ([]byte, error) {
+	return marshalJSON(r)
+}
+
+// UnmarshalJSON decodes the fraction from a JSON string.
+func (r *BigRat) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(r, data)
+}
@@ -0,0 +1,31 @@
+package stdtypes
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// UnmarshalFixedBytes copies data into dst, which is the slice of a
+// fixed-size byte array, returning an error if data does not have exactly
+// the length of the array. It is used by the methods generated for the type
+// declarations of byte arrays, such as `type Hash [32]byte`, which are
+// mapped as `bytes` with a customtype.
+func UnmarshalFixedBytes(dst, data []byte) error {
+	if len(data) != len(dst) {
+		return fmt.Errorf("stdtypes: expecting %d bytes, got %d", len(dst), len(data))
+	}
+
+	copy(dst, data)
+	return nil
+}
+
+// UnmarshalFixedBytesJSON decodes the base64 JSON string in data into dst,
+// which is the slice of a fixed-size byte array, returning an error if the
+// decoded bytes do not have exactly the length of the array.
+func UnmarshalFixedBytesJSON(dst, data []byte) error {
+	var b []byte
+	if err := json.Unmarshal(data, &b); err != nil {
+		return err
+	}
+	return UnmarshalFixedBytes(dst, b)
+}
@@ -0,0 +1,30 @@
+package stdtypes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalFixedBytes(t *testing.T) {
+	require := require.New(t)
+
+	var dst [4]byte
+	require.Nil(UnmarshalFixedBytes(dst[:], []byte{1, 2, 3, 4}))
+	require.Equal([4]byte{1, 2, 3, 4}, dst)
+
+	require.NotNil(UnmarshalFixedBytes(dst[:], []byte{1, 2, 3}))
+	require.NotNil(UnmarshalFixedBytes(dst[:], []byte{1, 2, 3, 4, 5}))
+	require.Equal([4]byte{1, 2, 3, 4}, dst, "dst is not modified on error")
+}
+
+func TestUnmarshalFixedBytesJSON(t *testing.T) {
+	require := require.New(t)
+
+	var dst [4]byte
+	require.Nil(UnmarshalFixedBytesJSON(dst[:], []byte(`"BAMCAQ=="`)))
+	require.Equal([4]byte{4, 3, 2, 1}, dst)
+
+	require.NotNil(UnmarshalFixedBytesJSON(dst[:], []byte(`"BAMC"`)))
+	require.NotNil(UnmarshalFixedBytesJSON(dst[:], []byte(`42`)))
+}
@@ -0,0 +1,57 @@
+package stdtypes
+
+import "time"
+
+// Location is a time.Location represented on the wire as its IANA name
+// (e.g. "Europe/Madrid").
+type Location struct {
+	*time.Location
+}
+
+// Marshal returns the name of the location. A nil location is encoded as an
+// empty value.
+func (l Location) Marshal() ([]byte, error) {
+	if l.Location == nil {
+		return nil, nil
+	}
+	return []byte(l.Location.String()), nil
+}
+
+// MarshalTo writes the name of the location to data.
+func (l *Location) MarshalTo(data []byte) (int, error) {
+	return marshalTo(l, data)
+}
+
+// Unmarshal loads the location with the name in data.
+func (l *Location) Unmarshal(data []byte) error {
+	if len(data) == 0 {
+		l.Location = nil
+		return nil
+	}
+
+	loc, err := time.LoadLocation(string(data))
+	if err != nil {
+		return err
+	}
+
+	l.Location = loc
+	return nil
+}
+
+// Size returns the size of the name of the location.
+func (l *Location) Size() int {
+	if l.Location == nil {
+		return 0
+	}
+	return len(l.Location.String())
+}
+
+// MarshalJSON encodes the location as a JSON string.
+func (l Location) MarshalJSON() ([]byte, error) {
+	return marshalJSON(l)
+}
+
+// UnmarshalJSON decodes the location from a JSON string.
+func (l *Location) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(l, data)
+}
@@ -0,0 +1,64 @@
+//go:build go1.18
+// +build go1.18
+
+package stdtypes
+
+import (
+	"encoding/json"
+	"net/netip"
+)
+
+// Addr is a net/netip.Addr represented on the wire as its binary form, that
+// is, 4 bytes for IPv4, 16 bytes for IPv6 and 16 bytes followed by the zone
+// for IPv6 addresses with a zone.
+type Addr netip.Addr
+
+// Marshal returns the binary form of the address.
+func (a Addr) Marshal() ([]byte, error) {
+	return netip.Addr(a).MarshalBinary()
+}
+
+// MarshalTo writes the binary form of the address to data.
+func (a *Addr) MarshalTo(data []byte) (int, error) {
+	return marshalTo(a, data)
+}
+
+// Unmarshal decodes the binary form of the address in data.
+func (a *Addr) Unmarshal(data []byte) error {
+	var v netip.Addr
+	if err := v.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	*a = Addr(v)
+	return nil
+}
+
+// Size returns the size of the binary form of the address.
+func (a *Addr) Size() int {
+	v := netip.Addr(*a)
+	switch {
+	case !v.IsValid():
+		return 0
+	case v.Is4():
+		return 4
+	}
+	return 16 + len(v.Zone())
+}
+
+// MarshalJSON encodes the address as a JSON string in its text form, as the
+// binary form is not printable.
+func (a Addr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(netip.Addr(a))
+}
+
+// UnmarshalJSON decodes the address from a JSON string in its text form.
+func (a *Addr) UnmarshalJSON(data []byte) error {
+	var v netip.Addr
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*a = Addr(v)
+	return nil
+}
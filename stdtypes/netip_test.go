@@ -0,0 +1,14 @@
+//go:build go1.18
+// +build go1.18
+
+package stdtypes
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestAddr(t *testing.T) {
+	v := Addr(netip.MustParseAddr("192.168.1.10"))
+	assertRoundTrip(t, "Addr", &v, new(Addr), string([]byte{192, 168, 1, 10}))
+}
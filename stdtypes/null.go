@@ -0,0 +1,60 @@
+//go:build go1.22
+// +build go1.22
+
+package stdtypes
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Null is a database/sql.Null represented on the wire as the well-known
+// wrapper type of its value type. Only the value types with a non-generic
+// database/sql counterpart are supported: string, int64, int32, int16, byte,
+// float64, bool and time.Time.
+type Null[T any] sql.Null[T]
+
+// Non-generic names for the supported instantiations of Null, as protobuf
+// custom types need to be referenced by a plain type name.
+type (
+	NullOfString  = Null[string]
+	NullOfInt64   = Null[int64]
+	NullOfInt32   = Null[int32]
+	NullOfInt16   = Null[int16]
+	NullOfByte    = Null[byte]
+	NullOfFloat64 = Null[float64]
+	NullOfBool    = Null[bool]
+	NullOfTime    = Null[time.Time]
+)
+
+// Marshal encodes the value as its well-known wrapper type.
+func (n Null[T]) Marshal() ([]byte, error) {
+	return marshalNull(n.V)
+}
+
+// MarshalTo writes the encoded value to data.
+func (n *Null[T]) MarshalTo(data []byte) (int, error) {
+	return marshalNullTo(n.V, data)
+}
+
+// Unmarshal decodes the value from its well-known wrapper type.
+func (n *Null[T]) Unmarshal(data []byte) error {
+	n.Valid = true
+	return unmarshalNull(data, &n.V)
+}
+
+// Size returns the size of the encoded value.
+func (n *Null[T]) Size() int {
+	return sizeNull(n.V)
+}
+
+// MarshalJSON encodes the value as JSON, or null if it is not valid.
+func (n Null[T]) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(n.Valid, n.V)
+}
+
+// UnmarshalJSON decodes the value from JSON.
+func (n *Null[T]) UnmarshalJSON(data []byte) (err error) {
+	n.Valid, err = unmarshalNullJSON(data, &n.V)
+	return
+}
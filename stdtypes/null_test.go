@@ -0,0 +1,28 @@
+//go:build go1.22
+// +build go1.22
+
+package stdtypes
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNull(t *testing.T) {
+	assertRoundTrip(t, "Null[string]", &NullOfString{V: "foo", Valid: true}, new(NullOfString), "\x0a\x03foo")
+	assertRoundTrip(t, "empty Null[string]", &NullOfString{Valid: true}, new(NullOfString), "")
+	assertRoundTrip(t, "Null[int64]", &NullOfInt64{V: 300, Valid: true}, new(NullOfInt64), "\x08\xac\x02")
+	assertRoundTrip(t, "Null[bool]", &NullOfBool{V: true, Valid: true}, new(NullOfBool), "\x08\x01")
+	assertRoundTrip(t, "Null[time.Time]", &NullOfTime{V: time.Unix(1, 0).UTC(), Valid: true}, new(NullOfTime), "\x08\x01")
+
+	_, err := Null[[]int]{V: []int{1}, Valid: true}.Marshal()
+	require.NotNil(t, err)
+}
+
+func TestNullConversion(t *testing.T) {
+	n := sql.Null[int32]{V: 3, Valid: true}
+	require.Equal(t, n, sql.Null[int32](Null[int32](n)))
+}
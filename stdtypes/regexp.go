@@ -0,0 +1,57 @@
+package stdtypes
+
+import "regexp"
+
+// Regexp is a regexp.Regexp represented on the wire as the source text of
+// the expression.
+type Regexp struct {
+	*regexp.Regexp
+}
+
+// Marshal returns the source text of the expression. A nil expression is
+// encoded as an empty value.
+func (r Regexp) Marshal() ([]byte, error) {
+	if r.Regexp == nil {
+		return nil, nil
+	}
+	return []byte(r.Regexp.String()), nil
+}
+
+// MarshalTo writes the source text of the expression to data.
+func (r *Regexp) MarshalTo(data []byte) (int, error) {
+	return marshalTo(r, data)
+}
+
+// Unmarshal compiles the expression in data.
+func (r *Regexp) Unmarshal(data []byte) error {
+	if len(data) == 0 {
+		r.Regexp = nil
+		return nil
+	}
+
+	re, err := regexp.Compile(string(data))
+	if err != nil {
+		return err
+	}
+
+	r.Regexp = re
+	return nil
+}
+
+// Size returns the size of the source text of the expression.
+func (r *Regexp) Size() int {
+	if r.Regexp == nil {
+		return 0
+	}
+	return len(r.Regexp.String())
+}
+
+// MarshalJSON encodes the expression as a JSON string.
+func (r Regexp) MarshalJSON() ([]byte, error) {
+	return marshalJSON(r)
+}
+
+// UnmarshalJSON decodes the expression from a JSON string.
+func (r *Regexp) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(r, data)
+}
@@ -0,0 +1,294 @@
+//go:build go1.17
+// +build go1.17
+
+package stdtypes
+
+import "database/sql"
+
+// NullString is a database/sql.NullString represented on the wire as a
+// google.protobuf.StringValue.
+type NullString sql.NullString
+
+// Marshal encodes the value as a google.protobuf.StringValue.
+func (n NullString) Marshal() ([]byte, error) {
+	return marshalNull(n.String)
+}
+
+// MarshalTo writes the encoded value to data.
+func (n *NullString) MarshalTo(data []byte) (int, error) {
+	return marshalNullTo(n.String, data)
+}
+
+// Unmarshal decodes the value from a google.protobuf.StringValue.
+func (n *NullString) Unmarshal(data []byte) error {
+	n.Valid = true
+	return unmarshalNull(data, &n.String)
+}
+
+// Size returns the size of the encoded value.
+func (n *NullString) Size() int {
+	return sizeNull(n.String)
+}
+
+// MarshalJSON encodes the value as JSON, or null if it is not valid.
+func (n NullString) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(n.Valid, n.String)
+}
+
+// UnmarshalJSON decodes the value from JSON.
+func (n *NullString) UnmarshalJSON(data []byte) (err error) {
+	n.Valid, err = unmarshalNullJSON(data, &n.String)
+	return
+}
+
+// NullInt64 is a database/sql.NullInt64 represented on the wire as a
+// google.protobuf.Int64Value.
+type NullInt64 sql.NullInt64
+
+// Marshal encodes the value as a google.protobuf.Int64Value.
+func (n NullInt64) Marshal() ([]byte, error) {
+	return marshalNull(n.Int64)
+}
+
+// MarshalTo writes the encoded value to data.
+func (n *NullInt64) MarshalTo(data []byte) (int, error) {
+	return marshalNullTo(n.Int64, data)
+}
+
+// Unmarshal decodes the value from a google.protobuf.Int64Value.
+func (n *NullInt64) Unmarshal(data []byte) error {
+	n.Valid = true
+	return unmarshalNull(data, &n.Int64)
+}
+
+// Size returns the size of the encoded value.
+func (n *NullInt64) Size() int {
+	return sizeNull(n.Int64)
+}
+
+// MarshalJSON encodes the value as JSON, or null if it is not valid.
+func (n NullInt64) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(n.Valid, n.Int64)
+}
+
+// UnmarshalJSON decodes the value from JSON.
+func (n *NullInt64) UnmarshalJSON(data []byte) (err error) {
+	n.Valid, err = unmarshalNullJSON(data, &n.Int64)
+	return
+}
+
+// NullInt32 is a database/sql.NullInt32 represented on the wire as a
+// google.protobuf.Int32Value.
+type NullInt32 sql.NullInt32
+
+// Marshal encodes the value as a google.protobuf.Int32Value.
+func (n NullInt32) Marshal() ([]byte, error) {
+	return marshalNull(n.Int32)
+}
+
+// MarshalTo writes the encoded value to data.
+func (n *NullInt32) MarshalTo(data []byte) (int, error) {
+	return marshalNullTo(n.Int32, data)
+}
+
+// Unmarshal decodes the value from a google.protobuf.Int32Value.
+func (n *NullInt32) Unmarshal(data []byte) error {
+	n.Valid = true
+	return unmarshalNull(data, &n.Int32)
+}
+
+// Size returns the size of the encoded value.
+func (n *NullInt32) Size() int {
+	return sizeNull(n.Int32)
+}
+
+// MarshalJSON encodes the value as JSON, or null if it is not valid.
+func (n NullInt32) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(n.Valid, n.Int32)
+}
+
+// UnmarshalJSON decodes the value from JSON.
+func (n *NullInt32) UnmarshalJSON(data []byte) (err error) {
+	n.Valid, err = unmarshalNullJSON(data, &n.Int32)
+	return
+}
+
+// NullInt16 is a database/sql.NullInt16 represented on the wire as a
+// google.protobuf.Int32Value.
+type NullInt16 sql.NullInt16
+
+// Marshal encodes the value as a google.protobuf.Int32Value.
+func (n NullInt16) Marshal() ([]byte, error) {
+	return marshalNull(n.Int16)
+}
+
+// MarshalTo writes the encoded value to data.
+func (n *NullInt16) MarshalTo(data []byte) (int, error) {
+	return marshalNullTo(n.Int16, data)
+}
+
+// Unmarshal decodes the value from a google.protobuf.Int32Value.
+func (n *NullInt16) Unmarshal(data []byte) error {
+	n.Valid = true
+	return unmarshalNull(data, &n.Int16)
+}
+
+// Size returns the size of the encoded value.
+func (n *NullInt16) Size() int {
+	return sizeNull(n.Int16)
+}
+
+// MarshalJSON encodes the value as JSON, or null if it is not valid.
+func (n NullInt16) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(n.Valid, n.Int16)
+}
+
+// UnmarshalJSON decodes the value from JSON.
+func (n *NullInt16) UnmarshalJSON(data []byte) (err error) {
+	n.Valid, err = unmarshalNullJSON(data, &n.Int16)
+	return
+}
+
+// NullByte is a database/sql.NullByte represented on the wire as a
+// google.protobuf.UInt32Value.
+type NullByte sql.NullByte
+
+// Marshal encodes the value as a google.protobuf.UInt32Value.
+func (n NullByte) Marshal() ([]byte, error) {
+	return marshalNull(n.Byte)
+}
+
+// MarshalTo writes the encoded value to data.
+func (n *NullByte) MarshalTo(data []byte) (int, error) {
+	return marshalNullTo(n.Byte, data)
+}
+
+// Unmarshal decodes the value from a google.protobuf.UInt32Value.
+func (n *NullByte) Unmarshal(data []byte) error {
+	n.Valid = true
+	return unmarshalNull(data, &n.Byte)
+}
+
+// Size returns the size of the encoded value.
+func (n *NullByte) Size() int {
+	return sizeNull(n.Byte)
+}
+
+// MarshalJSON encodes the value as JSON, or null if it is not valid.
+func (n NullByte) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(n.Valid, n.Byte)
+}
+
+// UnmarshalJSON decodes the value from JSON.
+func (n *NullByte) UnmarshalJSON(data []byte) (err error) {
+	n.Valid, err = unmarshalNullJSON(data, &n.Byte)
+	return
+}
+
+// NullFloat64 is a database/sql.NullFloat64 represented on the wire as a
+// google.protobuf.DoubleValue.
+type NullFloat64 sql.NullFloat64
+
+// Marshal encodes the value as a google.protobuf.DoubleValue.
+func (n NullFloat64) Marshal() ([]byte, error) {
+	return marshalNull(n.Float64)
+}
+
+// MarshalTo writes the encoded value to data.
+func (n *NullFloat64) MarshalTo(data []byte) (int, error) {
+	return marshalNullTo(n.Float64, data)
+}
+
+// Unmarshal decodes the value from a google.protobuf.DoubleValue.
+func (n *NullFloat64) Unmarshal(data []byte) error {
+	n.Valid = true
+	return unmarshalNull(data, &n.Float64)
+}
+
+// Size returns the size of the encoded value.
+func (n *NullFloat64) Size() int {
+	return sizeNull(n.Float64)
+}
+
+// MarshalJSON encodes the value as JSON, or null if it is not valid.
+func (n NullFloat64) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(n.Valid, n.Float64)
+}
+
+// UnmarshalJSON decodes the value from JSON.
+func (n *NullFloat64) UnmarshalJSON(data []byte) (err error) {
+	n.Valid, err = unmarshalNullJSON(data, &n.Float64)
+	return
+}
+
+// NullBool is a database/sql.NullBool represented on the wire as a
+// google.protobuf.BoolValue.
+type NullBool sql.NullBool
+
+// Marshal encodes the value as a google.protobuf.BoolValue.
+func (n NullBool) Marshal() ([]byte, error) {
+	return marshalNull(n.Bool)
+}
+
+// MarshalTo writes the encoded value to data.
+func (n *NullBool) MarshalTo(data []byte) (int, error) {
+	return marshalNullTo(n.Bool, data)
+}
+
+// Unmarshal decodes the value from a google.protobuf.BoolValue.
+func (n *NullBool) Unmarshal(data []byte) error {
+	n.Valid = true
+	return unmarshalNull(data, &n.Bool)
+}
+
+// Size returns the size of the encoded value.
+func (n *NullBool) Size() int {
+	return sizeNull(n.Bool)
+}
+
+// MarshalJSON encodes the value as JSON, or null if it is not valid.
+func (n NullBool) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(n.Valid, n.Bool)
+}
+
+// UnmarshalJSON decodes the value from JSON.
+func (n *NullBool) UnmarshalJSON(data []byte) (err error) {
+	n.Valid, err = unmarshalNullJSON(data, &n.Bool)
+	return
+}
+
+// NullTime is a database/sql.NullTime represented on the wire as a
+// google.protobuf.Timestamp.
+type NullTime sql.NullTime
+
+// Marshal encodes the value as a google.protobuf.Timestamp.
+func (n NullTime) Marshal() ([]byte, error) {
+	return marshalNull(n.Time)
+}
+
+// MarshalTo writes the encoded value to data.
+func (n *NullTime) MarshalTo(data []byte) (int, error) {
+	return marshalNullTo(n.Time, data)
+}
+
+// Unmarshal decodes the value from a google.protobuf.Timestamp.
+func (n *NullTime) Unmarshal(data []byte) error {
+	n.Valid = true
+	return unmarshalNull(data, &n.Time)
+}
+
+// Size returns the size of the encoded value.
+func (n *NullTime) Size() int {
+	return sizeNull(n.Time)
+}
+
+// MarshalJSON encodes the value as JSON, or null if it is not valid.
+func (n NullTime) MarshalJSON() ([]byte, error) {
+	return marshalNullJSON(n.Valid, n.Time)
+}
+
+// UnmarshalJSON decodes the value from JSON.
+func (n *NullTime) UnmarshalJSON(data []byte) (err error) {
+	n.Valid, err = unmarshalNullJSON(data, &n.Time)
+	return
+}
@@ -0,0 +1,76 @@
+//go:build go1.17
+// +build go1.17
+
+package stdtypes
+
+import (
+	"database/sql"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNullTypes(t *testing.T) {
+	ts := time.Date(2017, time.March, 1, 10, 30, 0, 500, time.UTC)
+	cases := []struct {
+		name  string
+		v     customType
+		empty customType
+		wire  string
+	}{
+		{"NullString", &NullString{String: "foo", Valid: true}, new(NullString), "\x0a\x03foo"},
+		{"empty NullString", &NullString{Valid: true}, new(NullString), ""},
+		{"NullInt64", &NullInt64{Int64: 300, Valid: true}, new(NullInt64), "\x08\xac\x02"},
+		{"zero NullInt64", &NullInt64{Valid: true}, new(NullInt64), ""},
+		{"NullInt32", &NullInt32{Int32: -1, Valid: true}, new(NullInt32), "\x08\xff\xff\xff\xff\xff\xff\xff\xff\xff\x01"},
+		{"NullInt16", &NullInt16{Int16: 2, Valid: true}, new(NullInt16), "\x08\x02"},
+		{"NullByte", &NullByte{Byte: 255, Valid: true}, new(NullByte), "\x08\xff\x01"},
+		{"NullFloat64", &NullFloat64{Float64: 1, Valid: true}, new(NullFloat64), "\x09\x00\x00\x00\x00\x00\x00\xf0\x3f"},
+		{"NullBool", &NullBool{Bool: false, Valid: true}, new(NullBool), ""},
+		{"NullTime", &NullTime{Time: ts, Valid: true}, new(NullTime), "\x08\xa8\xc5\xda\xc5\x05\x10\xf4\x03"},
+		{"epoch NullTime", &NullTime{Time: time.Unix(0, 0).UTC(), Valid: true}, new(NullTime), ""},
+	}
+
+	for _, c := range cases {
+		assertRoundTrip(t, c.name, c.v, c.empty, c.wire)
+	}
+}
+
+func TestNullTypesJSON(t *testing.T) {
+	data, err := NullInt64{Int64: math.MaxInt64, Valid: true}.MarshalJSON()
+	require.Nil(t, err)
+	require.Equal(t, `"9223372036854775807"`, string(data))
+
+	data, err = NullString{}.MarshalJSON()
+	require.Nil(t, err)
+	require.Equal(t, "null", string(data))
+
+	var n NullInt64
+	require.Nil(t, n.UnmarshalJSON([]byte("42")))
+	require.Equal(t, NullInt64{Int64: 42, Valid: true}, n)
+
+	require.Nil(t, n.UnmarshalJSON([]byte("null")))
+	require.Equal(t, NullInt64{}, n)
+}
+
+func TestNullTypesWellKnownTypes(t *testing.T) {
+	require := require.New(t)
+
+	data, err := (&types.StringValue{Value: "foo"}).Marshal()
+	require.Nil(err)
+
+	var n NullString
+	require.Nil(n.Unmarshal(data))
+	require.Equal(NullString{String: "foo", Valid: true}, n)
+
+	require.Nil(n.Unmarshal(nil))
+	require.Equal(NullString{Valid: true}, n, "a present field is valid even if its value is not encoded")
+}
+
+func TestNullTypesConversion(t *testing.T) {
+	ns := sql.NullString{String: "foo", Valid: true}
+	require.Equal(t, ns, sql.NullString(NullString(ns)))
+}
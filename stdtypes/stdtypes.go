@@ -0,0 +1,119 @@
+// Package stdtypes contains the wire representation of the standard library
+// types that proteus maps out of the box, along with the helpers to convert
+// them back and forth.
+//
+// Types whose Go representation is already a byte slice (net.IP and
+// encoding/json.RawMessage) are mapped as `bytes` with a casttype and need no
+// helpers. The rest of them do not implement the methods required by
+// gogo/protobuf to marshal and unmarshal custom types, so the fields must use
+// the wrappers defined in this package instead, which implement them and are
+// mapped as `bytes` with a customtype. The standard library types themselves
+// are not mapped, so the fields using them are ignored:
+//
+//	net/url.URL     -> URL       the URL as returned by url.URL.String
+//	math/big.Int    -> BigInt    the number in base 10
+//	math/big.Rat    -> BigRat    the fraction in the "a/b" form
+//	net/netip.Addr  -> Addr      the binary form of netip.Addr (Go 1.18+)
+//	time.Location   -> Location  the IANA name of the location
+//	regexp.Regexp   -> Regexp    the source text of the expression
+//
+// Note that proto3 `bytes` and `string` are wire compatible, so all the text
+// representations can be decoded as strings by consumers in other languages.
+//
+// The wrappers of the null types of database/sql (sql.NullString,
+// sql.NullInt64, ...) and of the generic sql.Null have the same name
+// (NullString, NullInt64, ... and Null) and are mapped to the well-known
+// wrapper types of protobuf (google.protobuf.StringValue,
+// google.protobuf.Int64Value, ...) and google.protobuf.Timestamp in the case
+// of sql.NullTime, with a customtype pointing to them. The well-known types
+// of the gogo/protobuf types package are used to encode them. The fields
+// using them must be pointers, such as *stdtypes.NullString: a nil pointer
+// is a NULL value, which is not sent, and a value that is sent is always
+// valid, even if it is the zero value, so the Valid flag survives the
+// round-trip and consumers in other languages can tell NULL values apart.
+package stdtypes // import "gopkg.in/src-d/proteus.v1/stdtypes"
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Path is the import path of this package.
+const Path = "gopkg.in/src-d/proteus.v1/stdtypes"
+
+// Types contains all standard library types with a built-in mapping indexed
+// by their fully qualified name, and the name of the wrapper in this package
+// that implements their wire representation, if they need one. Only the
+// types without a wrapper are mapped themselves, the rest of them must be
+// replaced by their wrappers.
+var Types = map[string]string{
+	"net/url.URL":              "URL",
+	"math/big.Int":             "BigInt",
+	"math/big.Rat":             "BigRat",
+	"net.IP":                   "",
+	"net/netip.Addr":           "Addr",
+	"encoding/json.RawMessage": "",
+	"time.Location":            "Location",
+	"regexp.Regexp":            "Regexp",
+
+	"database/sql.NullString":      "NullString",
+	"database/sql.NullInt64":       "NullInt64",
+	"database/sql.NullInt32":       "NullInt32",
+	"database/sql.NullInt16":       "NullInt16",
+	"database/sql.NullByte":        "NullByte",
+	"database/sql.NullFloat64":     "NullFloat64",
+	"database/sql.NullBool":        "NullBool",
+	"database/sql.NullTime":        "NullTime",
+	"database/sql.Null[string]":    "NullOfString",
+	"database/sql.Null[int64]":     "NullOfInt64",
+	"database/sql.Null[int32]":     "NullOfInt32",
+	"database/sql.Null[int16]":     "NullOfInt16",
+	"database/sql.Null[uint8]":     "NullOfByte",
+	"database/sql.Null[float64]":   "NullOfFloat64",
+	"database/sql.Null[bool]":      "NullOfBool",
+	"database/sql.Null[time.Time]": "NullOfTime",
+}
+
+// WrapperName returns the full name of the given wrapper of the standard
+// library type with the given name, both as in Types, the way the scanner
+// names it. The wrappers of the instantiations of the generic sql.Null are
+// aliases of instantiations of Null, so their name is the one of these, such
+// as "gopkg.in/src-d/proteus.v1/stdtypes.Null[string]" for NullOfString.
+func WrapperName(name, wrapper string) string {
+	if idx := strings.Index(name, "["); idx >= 0 {
+		return Path + ".Null" + name[idx:]
+	}
+	return Path + "." + wrapper
+}
+
+type marshaler interface {
+	Marshal() ([]byte, error)
+}
+
+type unmarshaler interface {
+	Unmarshal([]byte) error
+}
+
+func marshalTo(m marshaler, data []byte) (int, error) {
+	b, err := m.Marshal()
+	if err != nil {
+		return 0, err
+	}
+	return copy(data, b), nil
+}
+
+func marshalJSON(m marshaler) ([]byte, error) {
+	b, err := m.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(b))
+}
+
+func unmarshalJSON(u unmarshaler, data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return u.Unmarshal([]byte(s))
+}
@@ -0,0 +1,71 @@
+package stdtypes
+
+import (
+	"math/big"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type customType interface {
+	marshaler
+	unmarshaler
+	MarshalTo([]byte) (int, error)
+	Size() int
+	MarshalJSON() ([]byte, error)
+	UnmarshalJSON([]byte) error
+}
+
+func assertRoundTrip(t *testing.T, name string, v, empty customType, wire string) {
+	data, err := v.Marshal()
+	require.Nil(t, err, name)
+	require.Equal(t, wire, string(data), "wire representation of %s", name)
+	require.Equal(t, len(wire), v.Size(), "size of %s", name)
+
+	buf := make([]byte, v.Size())
+	n, err := v.MarshalTo(buf)
+	require.Nil(t, err, name)
+	require.Equal(t, len(wire), n, "marshalled bytes of %s", name)
+
+	require.Nil(t, empty.Unmarshal(buf), name)
+	require.Equal(t, v, empty, "unmarshalled %s", name)
+
+	js, err := v.MarshalJSON()
+	require.Nil(t, err, name)
+	require.Nil(t, empty.UnmarshalJSON(js), name)
+	require.Equal(t, v, empty, "unmarshalled JSON %s", name)
+}
+
+func TestURL(t *testing.T) {
+	u, err := url.Parse("https://user@example.com:8080/foo?bar=baz#qux")
+	require.Nil(t, err)
+
+	v := URL(*u)
+	assertRoundTrip(t, "URL", &v, new(URL), "https://user@example.com:8080/foo?bar=baz#qux")
+	require.NotNil(t, new(URL).Unmarshal([]byte("%zz")))
+}
+
+func TestBigInt(t *testing.T) {
+	n, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	assertRoundTrip(t, "BigInt", &BigInt{n}, new(BigInt), "-123456789012345678901234567890")
+	assertRoundTrip(t, "nil BigInt", new(BigInt), &BigInt{big.NewInt(1)}, "")
+	require.NotNil(t, new(BigInt).Unmarshal([]byte("1.5")))
+}
+
+func TestBigRat(t *testing.T) {
+	assertRoundTrip(t, "BigRat", &BigRat{big.NewRat(-3, 4)}, new(BigRat), "-3/4")
+	require.NotNil(t, new(BigRat).Unmarshal([]byte("a/b")))
+}
+
+func TestLocation(t *testing.T) {
+	assertRoundTrip(t, "Location", &Location{time.UTC}, new(Location), "UTC")
+	require.NotNil(t, new(Location).Unmarshal([]byte("Nowhere/Nothing")))
+}
+
+func TestRegexp(t *testing.T) {
+	assertRoundTrip(t, "Regexp", &Regexp{regexp.MustCompile(`^a+b*$`)}, new(Regexp), `^a+b*$`)
+	require.NotNil(t, new(Regexp).Unmarshal([]byte("(")))
+}
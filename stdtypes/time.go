@@ -0,0 +1,138 @@
+package stdtypes
+
+import "time"
+
+// The types in this file are the alternative representations of time.Time
+// and time.Duration that can be chosen instead of the well-known types
+// google.protobuf.Timestamp and google.protobuf.Duration. They are mapped as
+// `int64` or `string` with a casttype pointing to them, so they need no
+// marshaling methods, only the helpers to convert them back and forth.
+//
+//	time.Time      seconds -> UnixSeconds      seconds since the Unix epoch
+//	               millis  -> UnixMillis       milliseconds since the Unix epoch
+//	               nanos   -> UnixNanos        nanoseconds since the Unix epoch
+//	               string  -> RFC3339          the time in RFC 3339 format
+//	time.Duration  seconds -> DurationSeconds  the duration in seconds
+//	               millis  -> DurationMillis   the duration in milliseconds
+//	               nanos   -> time.Duration    the duration in nanoseconds
+//	               string  -> DurationString   the duration as time.Duration.String
+
+// TimeTypes contains all the alternative time representations of this
+// package indexed by name, and the protobuf scalar type they are mapped to.
+var TimeTypes = map[string]string{
+	"UnixSeconds":     "int64",
+	"UnixMillis":      "int64",
+	"UnixNanos":       "int64",
+	"RFC3339":         "string",
+	"DurationSeconds": "int64",
+	"DurationMillis":  "int64",
+	"DurationString":  "string",
+}
+
+// UnixSeconds is a time.Time represented as the number of seconds elapsed
+// since the Unix epoch. Fractions of a second are lost.
+type UnixSeconds int64
+
+// NewUnixSeconds returns the given time as UnixSeconds.
+func NewUnixSeconds(t time.Time) UnixSeconds {
+	return UnixSeconds(t.Unix())
+}
+
+// Time returns the time in UTC.
+func (u UnixSeconds) Time() time.Time {
+	return time.Unix(int64(u), 0).UTC()
+}
+
+// UnixMillis is a time.Time represented as the number of milliseconds
+// elapsed since the Unix epoch. Fractions of a millisecond are lost.
+type UnixMillis int64
+
+// NewUnixMillis returns the given time as UnixMillis.
+func NewUnixMillis(t time.Time) UnixMillis {
+	return UnixMillis(t.UnixMilli())
+}
+
+// Time returns the time in UTC.
+func (u UnixMillis) Time() time.Time {
+	return time.UnixMilli(int64(u)).UTC()
+}
+
+// UnixNanos is a time.Time represented as the number of nanoseconds elapsed
+// since the Unix epoch. Only the times between the years 1678 and 2262 can
+// be represented.
+type UnixNanos int64
+
+// NewUnixNanos returns the given time as UnixNanos.
+func NewUnixNanos(t time.Time) UnixNanos {
+	return UnixNanos(t.UnixNano())
+}
+
+// Time returns the time in UTC.
+func (u UnixNanos) Time() time.Time {
+	return time.Unix(0, int64(u)).UTC()
+}
+
+// RFC3339 is a time.Time represented as a string in RFC 3339 format with
+// nanoseconds, if any. The zero time is represented as an empty string.
+type RFC3339 string
+
+// NewRFC3339 returns the given time as RFC3339.
+func NewRFC3339(t time.Time) RFC3339 {
+	if t.IsZero() {
+		return ""
+	}
+	return RFC3339(t.Format(time.RFC3339Nano))
+}
+
+// Time parses the time. An empty string is parsed as the zero time.
+func (r RFC3339) Time() (time.Time, error) {
+	if r == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, string(r))
+}
+
+// DurationSeconds is a time.Duration represented as a number of seconds.
+// Fractions of a second are lost.
+type DurationSeconds int64
+
+// NewDurationSeconds returns the given duration as DurationSeconds.
+func NewDurationSeconds(d time.Duration) DurationSeconds {
+	return DurationSeconds(d / time.Second)
+}
+
+// Duration returns the duration.
+func (d DurationSeconds) Duration() time.Duration {
+	return time.Duration(d) * time.Second
+}
+
+// DurationMillis is a time.Duration represented as a number of milliseconds.
+// Fractions of a millisecond are lost.
+type DurationMillis int64
+
+// NewDurationMillis returns the given duration as DurationMillis.
+func NewDurationMillis(d time.Duration) DurationMillis {
+	return DurationMillis(d / time.Millisecond)
+}
+
+// Duration returns the duration.
+func (d DurationMillis) Duration() time.Duration {
+	return time.Duration(d) * time.Millisecond
+}
+
+// DurationString is a time.Duration represented as a string in the format
+// returned by time.Duration.String, such as "1h2m0.5s".
+type DurationString string
+
+// NewDurationString returns the given duration as DurationString.
+func NewDurationString(d time.Duration) DurationString {
+	return DurationString(d.String())
+}
+
+// Duration parses the duration. An empty string is parsed as zero.
+func (d DurationString) Duration() (time.Duration, error) {
+	if d == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(string(d))
+}
@@ -0,0 +1,66 @@
+package stdtypes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnixTimes(t *testing.T) {
+	require := require.New(t)
+	tm := time.Date(2017, time.March, 1, 10, 30, 0, 123456789, time.UTC)
+
+	require.Equal(UnixSeconds(1488364200), NewUnixSeconds(tm))
+	require.Equal(tm.Truncate(time.Second), NewUnixSeconds(tm).Time())
+
+	require.Equal(UnixMillis(1488364200123), NewUnixMillis(tm))
+	require.Equal(tm.Truncate(time.Millisecond), NewUnixMillis(tm).Time())
+
+	far := time.Date(3000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	require.Equal(UnixMillis(32503680000000), NewUnixMillis(far))
+	require.Equal(far, NewUnixMillis(far).Time(), "times out of the range of UnixNanos")
+
+	require.Equal(UnixNanos(1488364200123456789), NewUnixNanos(tm))
+	require.Equal(tm, NewUnixNanos(tm).Time())
+}
+
+func TestRFC3339(t *testing.T) {
+	require := require.New(t)
+	tm := time.Date(2017, time.March, 1, 10, 30, 0, 500000000, time.UTC)
+
+	r := NewRFC3339(tm)
+	require.Equal(RFC3339("2017-03-01T10:30:00.5Z"), r)
+	parsed, err := r.Time()
+	require.NoError(err)
+	require.Equal(tm, parsed)
+
+	require.Equal(RFC3339(""), NewRFC3339(time.Time{}))
+	parsed, err = RFC3339("").Time()
+	require.NoError(err)
+	require.True(parsed.IsZero())
+
+	_, err = RFC3339("yesterday").Time()
+	require.Error(err)
+}
+
+func TestDurations(t *testing.T) {
+	require := require.New(t)
+	d := time.Hour + 2*time.Minute + 1500*time.Millisecond
+
+	require.Equal(DurationSeconds(3721), NewDurationSeconds(d))
+	require.Equal(d.Truncate(time.Second), NewDurationSeconds(d).Duration())
+
+	require.Equal(DurationMillis(3721500), NewDurationMillis(d))
+	require.Equal(d, NewDurationMillis(d).Duration())
+
+	s := NewDurationString(d)
+	require.Equal(DurationString("1h2m1.5s"), s)
+	parsed, err := s.Duration()
+	require.NoError(err)
+	require.Equal(d, parsed)
+
+	parsed, err = DurationString("").Duration()
+	require.NoError(err)
+	require.Equal(time.Duration(0), parsed)
+}
@@ -0,0 +1,44 @@
+package stdtypes
+
+import "net/url"
+
+// URL is a net/url.URL represented on the wire as its string form.
+type URL url.URL
+
+// Marshal returns the string form of the URL.
+func (u URL) Marshal() ([]byte, error) {
+	v := url.URL(u)
+	return []byte(v.String()), nil
+}
+
+// MarshalTo writes the string form of the URL to data.
+func (u *URL) MarshalTo(data []byte) (int, error) {
+	return marshalTo(u, data)
+}
+
+// Unmarshal parses the URL in data.
+func (u *URL) Unmarshal(data []byte) error {
+	v, err := url.Parse(string(data))
+	if err != nil {
+		return err
+	}
+
+	*u = URL(*v)
+	return nil
+}
+
+// Size returns the size of the string form of the URL.
+func (u *URL) Size() int {
+	v := url.URL(*u)
+	return len(v.String())
+}
+
+// MarshalJSON encodes the URL as a JSON string.
+func (u URL) MarshalJSON() ([]byte, error) {
+	return marshalJSON(u)
+}
+
+// UnmarshalJSON decodes the URL from a JSON string.
+func (u *URL) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON(u, data)
+}
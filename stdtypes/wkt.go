@@ -0,0 +1,184 @@
+package stdtypes
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+)
+
+// The database/sql null types are represented on the wire as the well-known
+// wrapper types of protobuf (google.protobuf.StringValue, etc.) and
+// google.protobuf.Timestamp for times, as implemented by the gogo/protobuf
+// types package. The fields using them are pointers, so a NULL value is an
+// absent field and the presence of the field means the value is valid, even
+// if it is the zero value, which proto3 does not encode.
+// The functions in this file implement that encoding for all the value types
+// supported by the null types.
+
+// wellKnownType is a well-known type of the gogo/protobuf types package.
+type wellKnownType interface {
+	Marshal() ([]byte, error)
+	MarshalTo([]byte) (int, error)
+	Size() int
+}
+
+// newWellKnownType returns the well-known type with the value v, which must
+// be one of the supported value types.
+func newWellKnownType(v interface{}) (wellKnownType, error) {
+	switch v := v.(type) {
+	case string:
+		return &types.StringValue{Value: v}, nil
+	case int64:
+		return &types.Int64Value{Value: v}, nil
+	case int32:
+		return &types.Int32Value{Value: v}, nil
+	case int16:
+		return &types.Int32Value{Value: int32(v)}, nil
+	case uint8:
+		return &types.UInt32Value{Value: uint32(v)}, nil
+	case float64:
+		return &types.DoubleValue{Value: v}, nil
+	case bool:
+		return &types.BoolValue{Value: v}, nil
+	case time.Time:
+		return types.TimestampProto(v)
+	}
+	return nil, fmt.Errorf("stdtypes: unsupported null value type %T", v)
+}
+
+// marshalNull encodes the value v as its well-known type.
+func marshalNull(v interface{}) ([]byte, error) {
+	wkt, err := newWellKnownType(v)
+	if err != nil {
+		return nil, err
+	}
+	return wkt.Marshal()
+}
+
+// marshalNullTo writes the value v encoded as its well-known type to data.
+func marshalNullTo(v interface{}, data []byte) (int, error) {
+	wkt, err := newWellKnownType(v)
+	if err != nil {
+		return 0, err
+	}
+	return wkt.MarshalTo(data)
+}
+
+// sizeNull returns the size of the value v encoded as its well-known type.
+func sizeNull(v interface{}) int {
+	wkt, err := newWellKnownType(v)
+	if err != nil {
+		return 0
+	}
+	return wkt.Size()
+}
+
+// unmarshalNull decodes data in v, which must be a pointer to one of the
+// supported value types.
+func unmarshalNull(data []byte, v interface{}) error {
+	switch v := v.(type) {
+	case *string:
+		var wkt types.StringValue
+		if err := wkt.Unmarshal(data); err != nil {
+			return err
+		}
+		*v = wkt.Value
+	case *int64:
+		var wkt types.Int64Value
+		if err := wkt.Unmarshal(data); err != nil {
+			return err
+		}
+		*v = wkt.Value
+	case *int32:
+		var wkt types.Int32Value
+		if err := wkt.Unmarshal(data); err != nil {
+			return err
+		}
+		*v = wkt.Value
+	case *int16:
+		var wkt types.Int32Value
+		if err := wkt.Unmarshal(data); err != nil {
+			return err
+		}
+		*v = int16(wkt.Value)
+	case *uint8:
+		var wkt types.UInt32Value
+		if err := wkt.Unmarshal(data); err != nil {
+			return err
+		}
+		*v = uint8(wkt.Value)
+	case *float64:
+		var wkt types.DoubleValue
+		if err := wkt.Unmarshal(data); err != nil {
+			return err
+		}
+		*v = wkt.Value
+	case *bool:
+		var wkt types.BoolValue
+		if err := wkt.Unmarshal(data); err != nil {
+			return err
+		}
+		*v = wkt.Value
+	case *time.Time:
+		var wkt types.Timestamp
+		if err := wkt.Unmarshal(data); err != nil {
+			return err
+		}
+
+		t, err := types.TimestampFromProto(&wkt)
+		if err != nil {
+			return err
+		}
+		*v = t
+	default:
+		return fmt.Errorf("stdtypes: unsupported null value type %T", v)
+	}
+	return nil
+}
+
+// marshalNullJSON encodes the value following the protobuf JSON mapping of
+// the well-known types, that is, null if the value is not valid and 64-bit
+// integers as strings.
+func marshalNullJSON(valid bool, v interface{}) ([]byte, error) {
+	if !valid {
+		return []byte("null"), nil
+	}
+
+	switch v := v.(type) {
+	case int64:
+		return json.Marshal(strconv.FormatInt(v, 10))
+	case time.Time:
+		return json.Marshal(v.UTC().Format(time.RFC3339Nano))
+	}
+	return json.Marshal(v)
+}
+
+// unmarshalNullJSON decodes the JSON value in v and reports whether it was
+// valid.
+func unmarshalNullJSON(data []byte, v interface{}) (bool, error) {
+	if string(data) == "null" {
+		zero := reflect.ValueOf(v).Elem()
+		zero.Set(reflect.Zero(zero.Type()))
+		return false, nil
+	}
+
+	switch v := v.(type) {
+	case *int64:
+		var s json.Number
+		if err := json.Unmarshal(data, &s); err != nil {
+			return false, err
+		}
+
+		n, err := s.Int64()
+		if err != nil {
+			return false, err
+		}
+		*v = n
+		return true, nil
+	}
+	return true, json.Unmarshal(data, v)
+}
@@ -0,0 +1,385 @@
+package thrift // import "gopkg.in/src-d/proteus.v1/thrift"
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+// Generator is in charge of generating the Thrift IDL of a protobuf package
+// and write it to disk at the given path, in a file named after the last
+// element of the package path, e.g. "foo.thrift" for "example.com/foo".
+//
+// Messages are structs whose fields have the same ids as in the proto file,
+// enums keep their values and the service of the package is a service with
+// a function for every RPC. The arguments of the Go function are the
+// arguments of the Thrift function and its result is the type of the
+// function, or void if it returns nothing. The functions of the Go
+// functions that return an error throw a generated exception, named after
+// the service with an "Error" suffix.
+//
+// Thrift has no unsigned integers, so they are signed integers of the next
+// size, except for 64-bit integers, which keep their bits. Timestamps and
+// durations are the number of nanoseconds in an i64.
+type Generator struct {
+	basePath string
+}
+
+// NewGenerator creates a new Generator with the given base path.
+func NewGenerator(basePath string) *Generator {
+	return &Generator{basePath}
+}
+
+// Generate generates the Thrift IDL of the given package and writes it to
+// disk.
+func (g *Generator) Generate(pkg *protobuf.Package) error {
+	w := newWriter(pkg)
+
+	var body bytes.Buffer
+	w.writeDefinitions(&body)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by proteus. DO NOT EDIT.\n\n")
+	w.writeHeaders(&buf)
+	buf.Write(bytes.TrimRight(body.Bytes(), "\n"))
+	buf.WriteRune('\n')
+
+	return g.writeFile(pkg.Path, buf.Bytes())
+}
+
+func (g *Generator) writeFile(pkgPath string, data []byte) error {
+	dir := filepath.Join(g.basePath, pkgPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	file := filepath.Join(dir, fileName(pkgPath))
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		return err
+	}
+
+	report.Info("Generated Thrift IDL: %s", file)
+	return nil
+}
+
+// fileName returns the name of the Thrift file of a package.
+func fileName(pkgPath string) string {
+	return includeName(pkgPath) + ".thrift"
+}
+
+// includeName returns the name other files use to refer to the definitions
+// of a package, which is the name of its file without extension.
+func includeName(pkgPath string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			return r
+		}
+		return '_'
+	}, path.Base(pkgPath))
+}
+
+// writer writes the definitions of a package and keeps track of the
+// packages it needs to include, by include name.
+type writer struct {
+	pkg      *protobuf.Package
+	messages map[string]*protobuf.Message
+	// wrappers are the generated messages wrapping the arguments and results
+	// of the functions, which are not defined as structs.
+	wrappers map[string]bool
+	includes map[string]string
+	typedefs map[string]bool
+}
+
+func newWriter(pkg *protobuf.Package) *writer {
+	w := &writer{
+		pkg:      pkg,
+		messages: make(map[string]*protobuf.Message),
+		wrappers: make(map[string]bool),
+		includes: make(map[string]string),
+		typedefs: make(map[string]bool),
+	}
+
+	for _, msg := range pkg.Messages {
+		w.messages[msg.Name] = msg
+	}
+
+	for _, rpc := range pkg.RPCs {
+		if msg := w.generated(rpc.Input); msg != nil {
+			w.wrappers[msg.Name] = true
+		}
+
+		if msg := w.generated(rpc.Output); msg != nil && len(msg.Fields) <= 1 {
+			w.wrappers[msg.Name] = true
+		}
+	}
+
+	return w
+}
+
+// generated returns the message of the type if it was generated by proteus
+// to wrap the arguments or results of a function.
+func (w *writer) generated(typ protobuf.Type) *protobuf.Message {
+	if named, ok := typ.(*protobuf.Named); ok && named.Generated && named.Package == w.pkg.Name {
+		return w.messages[named.Name]
+	}
+	return nil
+}
+
+// typedefs are the definitions of the well-known types used by the
+// definitions, which must be written before them.
+var typedefs = map[string]string{
+	"Timestamp": "/**\n * Timestamp is the number of nanoseconds since the Unix epoch.\n */\ntypedef i64 Timestamp\n",
+	"Duration":  "/**\n * Duration is a number of nanoseconds.\n */\ntypedef i64 Duration\n",
+}
+
+func (w *writer) writeHeaders(buf *bytes.Buffer) {
+	var names []string
+	for name := range w.includes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		buf.WriteString(fmt.Sprintf("include %q\n", w.includes[name]))
+	}
+	if len(names) > 0 {
+		buf.WriteRune('\n')
+	}
+
+	buf.WriteString(fmt.Sprintf("namespace go %s\n\n", includeName(w.pkg.Path)))
+
+	names = names[:0]
+	for name := range w.typedefs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		buf.WriteString(typedefs[name])
+		buf.WriteRune('\n')
+	}
+}
+
+func (w *writer) writeDefinitions(buf *bytes.Buffer) {
+	for _, enum := range w.pkg.Enums {
+		writeEnum(buf, enum)
+		buf.WriteRune('\n')
+	}
+
+	for _, msg := range w.pkg.Messages {
+		if w.wrappers[msg.Name] {
+			continue
+		}
+
+		w.writeStruct(buf, msg)
+		buf.WriteRune('\n')
+	}
+
+	if len(w.pkg.RPCs) == 0 {
+		return
+	}
+
+	var hasError bool
+	for _, rpc := range w.pkg.RPCs {
+		hasError = hasError || rpc.HasError
+	}
+
+	if hasError {
+		protobuf.WriteBlockComment(buf, []string{w.exceptionName() + " is thrown when a function returns an error."}, "")
+		buf.WriteString(fmt.Sprintf("exception %s {\n  1: string message,\n}\n\n", w.exceptionName()))
+	}
+
+	w.writeService(buf)
+}
+
+func (w *writer) exceptionName() string {
+	return w.pkg.ServiceName() + "Error"
+}
+
+func writeEnum(buf *bytes.Buffer, enum *protobuf.Enum) {
+	protobuf.WriteBlockComment(buf, enum.Docs, "")
+	buf.WriteString(fmt.Sprintf("enum %s {\n", enum.Name))
+	for _, v := range enum.Values {
+		protobuf.WriteBlockComment(buf, v.Docs, "  ")
+		buf.WriteString(fmt.Sprintf("  %s = %d,\n", v.Name, v.Value))
+	}
+	buf.WriteString("}\n")
+}
+
+func (w *writer) writeStruct(buf *bytes.Buffer, msg *protobuf.Message) {
+	protobuf.WriteBlockComment(buf, msg.Docs, "")
+	buf.WriteString(fmt.Sprintf("struct %s {\n", msg.Name))
+	for _, f := range msg.Fields {
+		protobuf.WriteBlockComment(buf, f.Docs, "  ")
+		buf.WriteString(fmt.Sprintf("  %s,\n", w.field(f)))
+	}
+	buf.WriteString("}\n")
+}
+
+// field returns the definition of a field. Fields that can be null in Go
+// are optional.
+func (w *writer) field(f *protobuf.Field) string {
+	var requiredness string
+	if !f.Repeated && w.isOptional(f.Type) {
+		requiredness = "optional "
+	}
+
+	return fmt.Sprintf("%d: %s%s %s", f.Pos, requiredness, w.fieldType(f), f.Name)
+}
+
+func (w *writer) isOptional(typ protobuf.Type) bool {
+	named, ok := typ.(*protobuf.Named)
+	if !ok {
+		return false
+	}
+
+	if named.Package == "google.protobuf" {
+		_, wrapper := protobuf.WrapperScalar(named.Name)
+		return wrapper || named.IsNullable()
+	}
+
+	_, isMessage := w.messages[named.Name]
+	return (isMessage || named.Package != w.pkg.Name) && named.IsNullable()
+}
+
+func (w *writer) fieldType(f *protobuf.Field) string {
+	typ := w.typeName(f.Type)
+	if f.Repeated {
+		return fmt.Sprintf("list<%s>", typ)
+	}
+	return typ
+}
+
+func (w *writer) writeService(buf *bytes.Buffer) {
+	buf.WriteString(fmt.Sprintf("service %s {\n", w.pkg.ServiceName()))
+	for _, rpc := range w.pkg.RPCs {
+		protobuf.WriteBlockComment(buf, rpc.Docs, "  ")
+		var throws string
+		if rpc.HasError {
+			throws = fmt.Sprintf(" throws (1: %s error)", w.exceptionName())
+		}
+
+		buf.WriteString(fmt.Sprintf("  %s %s(%s)%s,\n", w.result(rpc), rpc.Name, w.arguments(rpc), throws))
+	}
+	buf.WriteString("}\n")
+}
+
+// arguments returns the arguments of the function of the RPC, which are the
+// fields of the request if it was generated by proteus or the request
+// otherwise.
+func (w *writer) arguments(rpc *protobuf.RPC) string {
+	msg := w.generated(rpc.Input)
+	if msg == nil {
+		return fmt.Sprintf("1: %s request", w.typeName(rpc.Input))
+	}
+
+	var args = make([]string, len(msg.Fields))
+	for i, f := range msg.Fields {
+		args[i] = fmt.Sprintf("%d: %s %s", f.Pos, w.fieldType(f), f.Name)
+	}
+	return strings.Join(args, ", ")
+}
+
+// result returns the type of the function of the RPC. Responses generated
+// by proteus with a single result are unwrapped and empty responses are
+// void.
+func (w *writer) result(rpc *protobuf.RPC) string {
+	msg := w.generated(rpc.Output)
+	if msg == nil || !w.wrappers[msg.Name] {
+		return w.typeName(rpc.Output)
+	}
+
+	if len(msg.Fields) == 0 {
+		return "void"
+	}
+
+	return w.fieldType(msg.Fields[0])
+}
+
+func (w *writer) typeName(typ protobuf.Type) string {
+	switch t := typ.(type) {
+	case *protobuf.Basic:
+		return basicType(t.Name)
+	case *protobuf.Alias:
+		return w.typeName(t.Underlying)
+	case *protobuf.Map:
+		return fmt.Sprintf("map<%s, %s>", w.typeName(t.Key), w.typeName(t.Value))
+	case *protobuf.Named:
+		return w.namedType(t)
+	}
+
+	return "binary"
+}
+
+func (w *writer) namedType(t *protobuf.Named) string {
+	if t.Package == w.pkg.Name {
+		return t.Name
+	}
+
+	if t.Package == "google.protobuf" {
+		return w.wellKnownType(t.Name)
+	}
+
+	if src, ok := t.Source().(*scanner.Named); ok && src.Path != "" {
+		rel, err := filepath.Rel(w.pkg.Path, src.Path)
+		if err == nil {
+			name := includeName(src.Path)
+			file := filepath.ToSlash(filepath.Join(rel, fileName(src.Path)))
+			if included, ok := w.includes[name]; ok && included != file {
+				report.Warn("packages %s and %s have the same Thrift file name, %s cannot be included in the Thrift IDL of %s", included, file, t, w.pkg.Name)
+			} else {
+				w.includes[name] = file
+			}
+			return fmt.Sprintf("%s.%s", name, t.Name)
+		}
+	}
+
+	report.Warn("type %s cannot be included in the Thrift IDL of %s, using binary", t, w.pkg.Name)
+	return "binary"
+}
+
+// basicType returns the Thrift type of a scalar type.
+func basicType(name string) string {
+	switch name {
+	case "bool":
+		return "bool"
+	case "int32", "sint32", "sfixed32":
+		return "i32"
+	case "uint32", "fixed32", "int64", "sint64", "sfixed64", "uint64", "fixed64":
+		return "i64"
+	case "float", "double":
+		return "double"
+	case "string":
+		return "string"
+	case "bytes":
+		return "binary"
+	}
+
+	return "binary"
+}
+
+// wellKnownType returns the Thrift type of a well-known type. Wrappers are
+// optional fields of their scalar type and the rest of types are JSON
+// strings.
+func (w *writer) wellKnownType(name string) string {
+	if scalar, ok := protobuf.WrapperScalar(name); ok {
+		return basicType(scalar)
+	}
+
+	if _, ok := typedefs[name]; ok {
+		w.typedefs[name] = true
+		return name
+	}
+
+	return "string"
+}
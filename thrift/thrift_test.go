@@ -0,0 +1,134 @@
+package thrift
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/protobuf/protobuftest"
+)
+
+// mockPackage returns the fixture package with a field that is not
+// nullable, a RPC with no results and another one with a single result.
+func mockPackage() *protobuf.Package {
+	pkg := protobuftest.Package()
+	pkg.Messages[0].Fields = append(pkg.Messages[0].Fields, &protobuf.Field{
+		Name: "home",
+		Pos:  13,
+		Type: protobuftest.NotNullable(protobuftest.Named("Address")),
+	})
+	pkg.Messages = append(pkg.Messages,
+		&protobuf.Message{Name: "DeleteUserResponse"},
+		&protobuf.Message{
+			Name: "CountResponse",
+			Fields: []*protobuf.Field{
+				{Name: "result1", Pos: 1, Type: protobuf.NewBasic("int32")},
+			},
+		},
+	)
+	pkg.RPCs = append(pkg.RPCs,
+		&protobuf.RPC{
+			Name:     "DeleteUser",
+			HasError: true,
+			Input:    protobuftest.Named("User"),
+			Output:   protobuftest.Generated("DeleteUserResponse"),
+		},
+		&protobuf.RPC{
+			Name:   "Count",
+			Input:  protobuftest.Named("Address"),
+			Output: protobuftest.Generated("CountResponse"),
+		},
+	)
+	return pkg
+}
+
+const expected = `// Code generated by proteus. DO NOT EDIT.
+
+include "../bar/bar.thrift"
+
+namespace go foo
+
+/**
+ * Timestamp is the number of nanoseconds since the Unix epoch.
+ */
+typedef i64 Timestamp
+
+/**
+ * Status of an user.
+ */
+enum Status {
+  /**
+   * ACTIVE users can log in.
+   */
+  ACTIVE = 0,
+  BANNED = 1,
+}
+
+/**
+ * User is an user.
+ */
+struct User {
+  /**
+   * ID of the user.
+   */
+  1: i64 id,
+  2: string first_name,
+  3: i64 age,
+  4: Status status,
+  /**
+   * Address of the user.
+   */
+  5: optional Address address,
+  6: list<string> tags,
+  7: optional Timestamp created_at,
+  8: optional string nickname,
+  9: optional double score,
+  10: map<string, i32> labels,
+  11: bar.Group group,
+  12: binary avatar,
+  13: Address home,
+}
+
+struct Address {
+  1: string city,
+}
+
+/**
+ * FooServiceError is thrown when a function returns an error.
+ */
+exception FooServiceError {
+  1: string message,
+}
+
+service FooService {
+  /**
+   * GetUser returns an user.
+   */
+  User GetUser(1: i64 arg1, 2: Status arg2, 3: Address arg3) throws (1: FooServiceError error),
+  User UpdateAddress(1: User request),
+  User CreateUser(1: User request),
+  void DeleteUser(1: User request) throws (1: FooServiceError error),
+  i32 Count(1: Address request),
+}
+`
+
+func TestGenerate(t *testing.T) {
+	path, err := ioutil.TempDir("", "proteus")
+	require.Nil(t, err)
+	defer os.RemoveAll(path)
+
+	require.Nil(t, NewGenerator(path).Generate(mockPackage()))
+
+	data, err := ioutil.ReadFile(filepath.Join(path, "github.com/example/foo", "foo.thrift"))
+	require.Nil(t, err)
+	require.Equal(t, expected, string(data))
+}
+
+func TestFileName(t *testing.T) {
+	require.Equal(t, "foo.thrift", fileName("github.com/example/foo"))
+	require.Equal(t, "proteus_v1.thrift", fileName("gopkg.in/src-d/proteus.v1"))
+}
@@ -0,0 +1,242 @@
+package typescript // import "gopkg.in/src-d/proteus.v1/typescript"
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/report"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+// Generator is in charge of generating the TypeScript type definitions of
+// a protobuf package and write them to disk in a file named "generated.ts"
+// at the given path.
+//
+// The types match the JSON mapping of proto3 used by protojson:
+//   - messages are interfaces whose fields are named in lower camel case and
+//     are optional, as fields with default values are omitted.
+//   - 64-bit integers are strings.
+//   - enums are unions of the names of their values.
+//   - well-known types have their JSON representation, e.g.
+//     google.protobuf.Timestamp is a string with an RFC 3339 date.
+//   - the service is an interface with a method for every RPC, which returns
+//     a promise of the response, so it can be implemented by the HTTP or gRPC
+//     web client of your choice.
+//
+// The types of other packages are imported from their own files, so those
+// packages must be generated as well.
+type Generator struct {
+	basePath string
+}
+
+// NewGenerator creates a new Generator with the given base path.
+func NewGenerator(basePath string) *Generator {
+	return &Generator{basePath}
+}
+
+// Generate generates the TypeScript definitions of the given package and
+// writes them to disk.
+func (g *Generator) Generate(pkg *protobuf.Package) error {
+	w := &writer{pkg: pkg, imports: make(map[string]string)}
+
+	var body bytes.Buffer
+	for _, msg := range pkg.Messages {
+		w.writeMessage(&body, msg)
+		body.WriteRune('\n')
+	}
+
+	for _, enum := range pkg.Enums {
+		writeEnum(&body, enum)
+		body.WriteRune('\n')
+	}
+
+	if len(pkg.RPCs) > 0 {
+		w.writeService(&body)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by proteus. DO NOT EDIT.\n\n")
+	w.writeImports(&buf)
+	buf.Write(bytes.TrimRight(body.Bytes(), "\n"))
+	buf.WriteRune('\n')
+
+	return g.writeFile(pkg.Path, buf.Bytes())
+}
+
+func (g *Generator) writeFile(path string, data []byte) error {
+	path = filepath.Join(g.basePath, path)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return err
+	}
+
+	file := filepath.Join(path, "generated.ts")
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		return err
+	}
+
+	report.Info("Generated TypeScript definitions: %s", file)
+	return nil
+}
+
+// writer writes the definitions of a package and keeps track of the
+// packages it needs to import, by alias.
+type writer struct {
+	pkg     *protobuf.Package
+	imports map[string]string
+}
+
+func (w *writer) writeImports(buf *bytes.Buffer) {
+	if len(w.imports) == 0 {
+		return
+	}
+
+	var aliases []string
+	for alias := range w.imports {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	for _, alias := range aliases {
+		buf.WriteString(fmt.Sprintf("import * as %s from %q;\n", alias, w.imports[alias]))
+	}
+	buf.WriteRune('\n')
+}
+
+func (w *writer) writeMessage(buf *bytes.Buffer, msg *protobuf.Message) {
+	protobuf.WriteBlockComment(buf, msg.Docs, "")
+	buf.WriteString(fmt.Sprintf("export interface %s {\n", msg.Name))
+	for _, f := range msg.Fields {
+		protobuf.WriteBlockComment(buf, f.Docs, "  ")
+		typ := w.typeName(f.Type)
+		if f.Repeated {
+			typ = arrayOf(typ)
+		}
+		buf.WriteString(fmt.Sprintf("  %s?: %s;\n", f.JSONName(), typ))
+	}
+	buf.WriteString("}\n")
+}
+
+func writeEnum(buf *bytes.Buffer, enum *protobuf.Enum) {
+	protobuf.WriteBlockComment(buf, enum.Docs, "")
+	var values = make([]string, len(enum.Values))
+	for i, v := range enum.Values {
+		values[i] = fmt.Sprintf("%q", v.Name)
+	}
+
+	if len(values) == 0 {
+		values = []string{"never"}
+	}
+
+	buf.WriteString(fmt.Sprintf("export type %s = %s;\n", enum.Name, strings.Join(values, " | ")))
+}
+
+func (w *writer) writeService(buf *bytes.Buffer) {
+	buf.WriteString(fmt.Sprintf("export interface %s {\n", w.pkg.ServiceName()))
+	for _, rpc := range w.pkg.RPCs {
+		protobuf.WriteBlockComment(buf, rpc.Docs, "  ")
+		buf.WriteString(fmt.Sprintf(
+			"  %s(request: %s): Promise<%s>;\n",
+			methodName(rpc.Name),
+			w.typeName(rpc.Input),
+			w.typeName(rpc.Output),
+		))
+	}
+	buf.WriteString("}\n")
+}
+
+// methodName returns the name of the method of a RPC in lower camel case.
+func methodName(name string) string {
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+func arrayOf(typ string) string {
+	if strings.ContainsAny(typ, " |") {
+		return fmt.Sprintf("(%s)[]", typ)
+	}
+	return typ + "[]"
+}
+
+func (w *writer) typeName(typ protobuf.Type) string {
+	switch t := typ.(type) {
+	case *protobuf.Basic:
+		return basicType(t.Name)
+	case *protobuf.Alias:
+		return w.typeName(t.Underlying)
+	case *protobuf.Map:
+		return fmt.Sprintf("{ [key: string]: %s }", w.typeName(t.Value))
+	case *protobuf.Named:
+		return w.namedType(t)
+	}
+
+	return "unknown"
+}
+
+func (w *writer) namedType(t *protobuf.Named) string {
+	if t.Package == w.pkg.Name {
+		return t.Name
+	}
+
+	if t.Package == "google.protobuf" {
+		return wellKnownType(t.Name)
+	}
+
+	if src, ok := t.Source().(*scanner.Named); ok && src.Path != "" {
+		rel, err := filepath.Rel(w.pkg.Path, src.Path)
+		if err == nil {
+			alias := strings.Replace(t.Package, ".", "_", -1)
+			module := filepath.ToSlash(filepath.Join(rel, "generated"))
+			if !strings.HasPrefix(module, "../") {
+				module = "./" + module
+			}
+			w.imports[alias] = module
+			return fmt.Sprintf("%s.%s", alias, t.Name)
+		}
+	}
+
+	report.Warn("type %s cannot be imported in the TypeScript definitions of %s, using unknown", t, w.pkg.Name)
+	return "unknown"
+}
+
+// basicType returns the TypeScript type of a scalar type. 64-bit integers
+// and bytes, encoded in base64, are strings in the JSON mapping.
+func basicType(name string) string {
+	switch name {
+	case "int32", "sint32", "sfixed32", "uint32", "fixed32", "float", "double":
+		return "number"
+	case "int64", "sint64", "sfixed64", "uint64", "fixed64", "string", "bytes":
+		return "string"
+	case "bool":
+		return "boolean"
+	}
+
+	return "unknown"
+}
+
+// wellKnownType returns the TypeScript type of the JSON representation of a
+// well-known type. Wrappers can be null.
+func wellKnownType(name string) string {
+	if scalar, ok := protobuf.WrapperScalar(name); ok {
+		return basicType(scalar) + " | null"
+	}
+
+	switch name {
+	case "Timestamp", "Duration", "FieldMask":
+		return "string"
+	case "Struct":
+		return "{ [key: string]: unknown }"
+	case "ListValue":
+		return "unknown[]"
+	case "Value":
+		return "unknown"
+	case "Any":
+		return `{ "@type": string; [key: string]: unknown }`
+	}
+
+	return "{}"
+}
@@ -0,0 +1,113 @@
+package typescript
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"gopkg.in/src-d/proteus.v1/protobuf"
+	"gopkg.in/src-d/proteus.v1/protobuf/protobuftest"
+	"gopkg.in/src-d/proteus.v1/scanner"
+)
+
+// mockPackage returns the fixture package with documentation of many
+// paragraphs, a message of a subpackage and an RPC with a name that is not
+// in camel case.
+func mockPackage() *protobuf.Package {
+	category := protobuf.NewNamed("example.foo.categories", "Category")
+	category.SetSource(scanner.NewNamed(protobuftest.Path+"/categories", "Category"))
+
+	pkg := protobuftest.Package()
+	pkg.Messages[0].Docs = append(pkg.Messages[0].Docs, "", "It can log in.")
+	pkg.Messages[0].Fields = append(pkg.Messages[0].Fields, &protobuf.Field{
+		Name:     "categories",
+		Pos:      13,
+		Type:     category,
+		Repeated: true,
+	})
+	pkg.RPCs = append(pkg.RPCs, &protobuf.RPC{
+		Name:   "Store_Save",
+		Input:  protobuftest.Named("User"),
+		Output: protobuf.NewNamed("google.protobuf", "Empty"),
+	})
+	return pkg
+}
+
+const expected = `// Code generated by proteus. DO NOT EDIT.
+
+import * as example_bar from "../bar/generated";
+import * as example_foo_categories from "./categories/generated";
+
+/**
+ * User is an user.
+ *
+ * It can log in.
+ */
+export interface User {
+  /**
+   * ID of the user.
+   */
+  id?: string;
+  firstName?: string;
+  age?: number;
+  status?: Status;
+  /**
+   * Address of the user.
+   */
+  address?: Address;
+  tags?: string[];
+  createdAt?: string;
+  nickname?: string | null;
+  score?: number | null;
+  labels?: { [key: string]: number };
+  group?: example_bar.Group;
+  avatar?: string;
+  categories?: example_foo_categories.Category[];
+}
+
+export interface Address {
+  city?: string;
+}
+
+export interface GetUserRequest {
+  arg1?: string;
+  arg2?: Status;
+  arg3?: Address;
+}
+
+/**
+ * Status of an user.
+ */
+export type Status = "ACTIVE" | "BANNED";
+
+export interface FooService {
+  /**
+   * GetUser returns an user.
+   */
+  getUser(request: GetUserRequest): Promise<User>;
+  updateAddress(request: User): Promise<User>;
+  createUser(request: User): Promise<User>;
+  store_Save(request: User): Promise<{}>;
+}
+`
+
+func TestGenerate(t *testing.T) {
+	path, err := ioutil.TempDir("", "proteus")
+	require.Nil(t, err)
+	defer os.RemoveAll(path)
+
+	require.Nil(t, NewGenerator(path).Generate(mockPackage()))
+
+	data, err := ioutil.ReadFile(filepath.Join(path, "github.com/example/foo", "generated.ts"))
+	require.Nil(t, err)
+	require.Equal(t, expected, string(data))
+}
+
+func TestArrayOf(t *testing.T) {
+	require.Equal(t, "string[]", arrayOf("string"))
+	require.Equal(t, "(number | null)[]", arrayOf("number | null"))
+	require.Equal(t, "({ [key: string]: number })[]", arrayOf("{ [key: string]: number }"))
+}